@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"errors"
+	"fmt"
 
 	"github.com/bsv-blockchain/go-bt/v2/bscript"
 	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
@@ -17,6 +18,11 @@ var (
 	ErrPublicKeyXNil = errors.New("public key X coordinate cannot be nil")
 	// ErrInvalidOutputScript is returned when the output script is missing an address
 	ErrInvalidOutputScript = errors.New("invalid output script, missing an address")
+	// ErrInvalidAddressLength is returned when a decoded address is not 25 bytes
+	ErrInvalidAddressLength = errors.New("invalid address length")
+	// ErrUnknownAddressVersion is returned when the address version byte is not
+	// a P2PKH or P2SH version of a known network
+	ErrUnknownAddressVersion = errors.New("unknown address version")
 )
 
 // A25 is a type for a 25 byte (not base58 encoded) bitcoin address.
@@ -81,7 +87,7 @@ func (a *A25) doubleSHA256() []byte {
 // if it can be decoded into a 25 byte address, the version number is 0,
 // and the checksum validates.  Return value ok will be true for valid
 // addresses.  If ok is false, the address is invalid and the error value
-// may indicate why.
+// may indicate why.  Use ValidateAddress for other networks and address types.
 func ValidA58(a58 []byte) (bool, error) {
 	var a A25
 	if err := a.Set58(a58); err != nil {
@@ -93,6 +99,96 @@ func ValidA58(a58 []byte) (bool, error) {
 	return a.EmbeddedChecksum() == a.ComputeChecksum(), nil
 }
 
+// AddressType is the type of a base58 address
+type AddressType uint8
+
+const (
+	// AddressP2PKH is a pay-to-public-key-hash address (1..., m... or n...)
+	AddressP2PKH AddressType = iota
+
+	// AddressP2SH is a legacy pay-to-script-hash address (3... or 2...); BSV no
+	// longer creates P2SH outputs, but the addresses can still be parsed
+	AddressP2SH
+)
+
+// String returns "p2pkh" or "p2sh"
+func (t AddressType) String() string {
+	switch t {
+	case AddressP2PKH:
+		return "p2pkh"
+	case AddressP2SH:
+		return "p2sh"
+	}
+	return "unknown"
+}
+
+// Address versions of the mainnet and testnet (shared by regtest)
+const (
+	addressVersionMainnetP2PKH byte = 0x00
+	addressVersionMainnetP2SH  byte = 0x05
+	addressVersionTestnetP2PKH byte = 0x6f
+	addressVersionTestnetP2SH  byte = 0xc4
+)
+
+// ParsedAddress is a decoded base58 address
+type ParsedAddress struct {
+	Address string
+	Network Network
+	Type    AddressType
+	Hash160 [20]byte
+}
+
+// ParseAddress will decode a base58 address and get its network, type and hash160
+//
+// Testnet addresses are returned as NetworkTestnet (regtest addresses are
+// identical). Errors wrap ErrBadCharacter, ErrInvalidAddressLength,
+// ErrUnknownAddressVersion or ErrChecksumMismatch.
+func ParseAddress(address string) (*ParsedAddress, error) {
+	decoded, err := base58DecodeBytes([]byte(address))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrBadCharacter, address)
+	}
+	if len(decoded) != len(A25{}) {
+		return nil, fmt.Errorf("%w: %d bytes, expected %d", ErrInvalidAddressLength, len(decoded), len(A25{}))
+	}
+
+	a := A25(decoded)
+	if a.EmbeddedChecksum() != a.ComputeChecksum() {
+		return nil, fmt.Errorf("%w: %q", ErrChecksumMismatch, address)
+	}
+
+	parsed := &ParsedAddress{Address: address}
+	switch a.Version() {
+	case addressVersionMainnetP2PKH:
+		parsed.Network, parsed.Type = NetworkMainnet, AddressP2PKH
+	case addressVersionMainnetP2SH:
+		parsed.Network, parsed.Type = NetworkMainnet, AddressP2SH
+	case addressVersionTestnetP2PKH:
+		parsed.Network, parsed.Type = NetworkTestnet, AddressP2PKH
+	case addressVersionTestnetP2SH:
+		parsed.Network, parsed.Type = NetworkTestnet, AddressP2SH
+	default:
+		return nil, fmt.Errorf("%w: 0x%02x", ErrUnknownAddressVersion, a.Version())
+	}
+	copy(parsed.Hash160[:], a[1:21])
+	return parsed, nil
+}
+
+// ValidateAddress will check that an address is a valid P2PKH or P2SH address
+// of the network (testnet and regtest addresses are interchangeable)
+//
+// Returns the ParseAddress errors, or ErrNetworkMismatch for another network.
+func ValidateAddress(address string, network Network) error {
+	parsed, err := ParseAddress(address)
+	if err != nil {
+		return err
+	}
+	if parsed.Network.IsMainnet() != network.IsMainnet() {
+		return fmt.Errorf("%w: %s address, expected %s", ErrNetworkMismatch, parsed.Network, network)
+	}
+	return nil
+}
+
 // GetAddressFromPrivateKey takes an ec private key and returns a Bitcoin address
 func GetAddressFromPrivateKey(privateKey *ec.PrivateKey, compressed, mainnet bool) (string, error) {
 	address, err := GetAddressFromPubKey(privateKey.PubKey(), compressed, mainnet)
@@ -0,0 +1,80 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+)
+
+// AddressEncryptor encrypts data to the owner of an address, using the public
+// key recovered from a message the owner signed with SignMessage
+type AddressEncryptor struct {
+	// Address is the address the public key was checked against
+	Address string
+
+	// PubKey is the public key recovered from the signature
+	PubKey *ec.PublicKey
+
+	// Compressed is whether the address was derived from the compressed key
+	Compressed bool
+}
+
+// NewAddressEncryptor will recover the public key from a Bitcoin Signed Message
+// signature (see SignMessage), check it against the address using the same
+// logic as VerifyMessage and return an encryptor for the address owner
+func NewAddressEncryptor(address, sig, message string, mainnet bool) (*AddressEncryptor, error) {
+	if address == "" {
+		return nil, ErrMissingAddress
+	}
+
+	// Recover and check the public key
+	pubKey, compressed, err := pubKeyForAddress(address, sig, message, mainnet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AddressEncryptor{
+		Address:    address,
+		PubKey:     pubKey,
+		Compressed: compressed,
+	}, nil
+}
+
+// Encrypt will encrypt the data to the address owner using the legacy format
+//
+// The owner can decrypt it with DecryptWithPrivateKey
+func (a *AddressEncryptor) Encrypt(data []byte) ([]byte, error) {
+	return eciesEncrypt(a.PubKey, append([]byte{}, data...))
+}
+
+// EncryptString will encrypt the string to a hex encoded payload for the
+// address owner using the legacy format
+func (a *AddressEncryptor) EncryptString(data string) (string, error) {
+	encrypted, err := a.Encrypt([]byte(data))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(encrypted), nil
+}
+
+// EncryptFrom will encrypt the data from the sender's identity key to the
+// address owner using BRC-78
+func (a *AddressEncryptor) EncryptFrom(sender *ec.PrivateKey, data []byte) ([]byte, error) {
+	return EncryptBRC78(sender, a.PubKey, data)
+}
+
+// NewEnvelope will encrypt the data for the address owner and any additional
+// recipients (see NewEnvelope)
+func (a *AddressEncryptor) NewEnvelope(data []byte, sender *ec.PrivateKey, others ...*ec.PublicKey) (*Envelope, error) {
+	return NewEnvelope(data, append([]*ec.PublicKey{a.PubKey}, others...), sender)
+}
+
+// EncryptToAddress is a convenience wrapper for NewAddressEncryptor() and
+// AddressEncryptor.EncryptString()
+func EncryptToAddress(address, sig, message, data string, mainnet bool) (string, error) {
+	encryptor, err := NewAddressEncryptor(address, sig, message, mainnet)
+	if err != nil {
+		return "", err
+	}
+	return encryptor.EncryptString(data)
+}
@@ -0,0 +1,174 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// testOwnershipMessage is the message an address owner signs to publish their key
+	testOwnershipMessage = "prove you own this address"
+
+	// testOwnerAddress is the compressed address of testPrivateKeyHex
+	testOwnerAddress = "1DfGxKmgL3ETwUdNnXLBueEvNpjcDGcKgK"
+)
+
+// TestNewAddressEncryptor will test the method NewAddressEncryptor()
+func TestNewAddressEncryptor(t *testing.T) {
+	t.Parallel()
+
+	for _, compressed := range []bool{true, false} {
+		t.Run(fmt.Sprintf("compressed %t", compressed), func(t *testing.T) {
+			t.Parallel()
+
+			// The owner signs a message with their key
+			address, err := GetAddressFromPrivateKeyString(testPrivateKeyHex, compressed, true)
+			require.NoError(t, err)
+			sig, err := SignMessage(testPrivateKeyHex, testOwnershipMessage, compressed)
+			require.NoError(t, err)
+
+			// Anyone can encrypt to the address using the signature
+			encryptor, err := NewAddressEncryptor(address, sig, testOwnershipMessage, true)
+			require.NoError(t, err)
+			assert.Equal(t, address, encryptor.Address)
+			assert.Equal(t, compressed, encryptor.Compressed)
+			assert.Equal(t, testPubKeyCompressed, hex.EncodeToString(encryptor.PubKey.Compressed()))
+
+			encrypted, err := encryptor.EncryptString(testEncryptionMessage)
+			require.NoError(t, err)
+
+			// The owner decrypts with their key
+			decrypted, err := DecryptWithPrivateKeyString(testPrivateKeyHex, encrypted)
+			require.NoError(t, err)
+			assert.Equal(t, testEncryptionMessage, decrypted)
+		})
+	}
+}
+
+// TestNewAddressEncryptorErrors will test the error cases of NewAddressEncryptor()
+func TestNewAddressEncryptorErrors(t *testing.T) {
+	t.Parallel()
+
+	sig, err := SignMessage(testPrivateKeyHex, testOwnershipMessage, true)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		address  string
+		sig      string
+		message  string
+		mainnet  bool
+		expected error
+	}{
+		{"missing address", "", sig, testOwnershipMessage, true, ErrMissingAddress},
+		{"other address", testAddress2, sig, testOwnershipMessage, true, ErrAddressNotFound},
+		{"other message", testOwnerAddress, sig, "another message", true, ErrAddressNotFound},
+		{"wrong network", testOwnerAddress, sig, testOwnershipMessage, false, ErrAddressNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			encryptor, err := NewAddressEncryptor(test.address, test.sig, test.message, test.mainnet)
+			require.ErrorIs(t, err, test.expected)
+			assert.Nil(t, encryptor)
+		})
+	}
+
+	t.Run("invalid signature", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewAddressEncryptor(testOwnerAddress, "not-base64!", testOwnershipMessage, true)
+		require.Error(t, err)
+		_, err = EncryptToAddress(testOwnerAddress, "not-base64!", testOwnershipMessage, "data", true)
+		require.Error(t, err)
+	})
+}
+
+// TestAddressEncryptorFormats will test the BRC-78 and envelope helpers of AddressEncryptor
+func TestAddressEncryptorFormats(t *testing.T) {
+	t.Parallel()
+
+	owner, err := PrivateKeyFromString(testPrivateKeyHex)
+	require.NoError(t, err)
+	sig, err := SignMessage(testPrivateKeyHex, testOwnershipMessage, true)
+	require.NoError(t, err)
+	encryptor, err := NewAddressEncryptor(testOwnerAddress, sig, testOwnershipMessage, true)
+	require.NoError(t, err)
+
+	sender, err := CreatePrivateKey()
+	require.NoError(t, err)
+
+	t.Run("encrypt bytes", func(t *testing.T) {
+		t.Parallel()
+		encrypted, encryptErr := encryptor.Encrypt([]byte(testEncryptionMessage))
+		require.NoError(t, encryptErr)
+		decrypted, decryptErr := eciesDecrypt(owner, encrypted)
+		require.NoError(t, decryptErr)
+		assert.Equal(t, testEncryptionMessage, string(decrypted))
+	})
+
+	t.Run("brc78", func(t *testing.T) {
+		t.Parallel()
+		encrypted, encryptErr := encryptor.EncryptFrom(sender, []byte(testEncryptionMessage))
+		require.NoError(t, encryptErr)
+		decrypted, senderPubKey, decryptErr := DecryptBRC78(owner, encrypted)
+		require.NoError(t, decryptErr)
+		assert.Equal(t, testEncryptionMessage, string(decrypted))
+		assert.True(t, senderPubKey.IsEqual(sender.PubKey()))
+	})
+
+	t.Run("envelope", func(t *testing.T) {
+		t.Parallel()
+		other, keyErr := CreatePrivateKey()
+		require.NoError(t, keyErr)
+		envelope, envelopeErr := encryptor.NewEnvelope([]byte(testEncryptionMessage), sender, other.PubKey())
+		require.NoError(t, envelopeErr)
+		require.Len(t, envelope.Recipients, 3)
+		for _, key := range []*ec.PrivateKey{owner, sender, other} {
+			data, openErr := envelope.Open(key)
+			require.NoError(t, openErr)
+			assert.Equal(t, testEncryptionMessage, string(data))
+		}
+	})
+}
+
+// ExampleEncryptToAddress example using EncryptToAddress()
+func ExampleEncryptToAddress() {
+	// The address owner published a signed message
+	sig, err := SignMessage(testPrivateKeyHex, "prove you own this address", true)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	// Encrypt to the address (the payload changes each time)
+	var encrypted string
+	if encrypted, err = EncryptToAddress(
+		"1DfGxKmgL3ETwUdNnXLBueEvNpjcDGcKgK", sig, "prove you own this address", "for your eyes only", true,
+	); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	// The owner decrypts it
+	var decrypted string
+	if decrypted, err = DecryptWithPrivateKeyString(testPrivateKeyHex, encrypted); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("decrypted: %s", decrypted)
+	// Output:decrypted: for your eyes only
+}
+
+// BenchmarkNewAddressEncryptor benchmarks the method NewAddressEncryptor()
+func BenchmarkNewAddressEncryptor(b *testing.B) {
+	sig, _ := SignMessage(testPrivateKeyHex, testOwnershipMessage, true)
+	for b.Loop() {
+		_, _ = NewAddressEncryptor(testOwnerAddress, sig, testOwnershipMessage, true)
+	}
+}
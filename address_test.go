@@ -1,11 +1,13 @@
 package bitcoin
 
 import (
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"testing"
 
 	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	hash "github.com/bsv-blockchain/go-sdk/primitives/hash"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -71,6 +73,125 @@ func BenchmarkValidA58(b *testing.B) {
 	}
 }
 
+// TestParseAddress will test the method ParseAddress()
+func TestParseAddress(t *testing.T) {
+	t.Parallel()
+
+	// A Litecoin address (version 0x30) has a valid checksum but an unknown version
+	litecoin := append([]byte{0x30}, make([]byte, 20)...)
+	litecoin = append(litecoin, hash.Sha256d(litecoin)[:4]...)
+
+	tests := []struct {
+		name            string
+		input           string
+		expectedNetwork Network
+		expectedType    AddressType
+		expectedHash160 string
+		expectedError   error
+	}{
+		{"mainnet p2pkh", "1KCEAmVS6FFggtc7W9as7sEENvjt7DqMi2", NetworkMainnet, AddressP2PKH, "c791d50554b2c55e10d833bf4f1a954417ab3034", nil},
+		{"mainnet p2sh", "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", NetworkMainnet, AddressP2SH, "b472a266d0bd89c13706a4132ccfb16f7c3b9fcb", nil},
+		{"testnet p2pkh", "mmobaZaCeFGujSmej9ESfohgfWjXBW1u7m", NetworkTestnet, AddressP2PKH, "44f688a2961f9f5d301d0c1a1e674096f37fa688", nil},
+		{"testnet p2sh", "2MzQwSSnBHWHqSAqtTVQ6v47XtaisrJa1Vc", NetworkTestnet, AddressP2SH, "4e9f39ca4688ff102128ea4ccda34105324305b0", nil},
+		{"zero hash", "1111111111111111111114oLvT2", NetworkMainnet, AddressP2PKH, "0000000000000000000000000000000000000000", nil},
+		{"bad checksum", "1KCEAmVS6FFggtc7W9as7sEENvjt7DqMi", 0, 0, "", ErrChecksumMismatch},
+		{"bad character", "1KCEAmVS6FFggtc7W9as7sEENvjt7DqMi0", 0, 0, "", ErrBadCharacter},
+		{"too short", "1KCEAmV", 0, 0, "", ErrInvalidAddressLength},
+		{"too long", "1KCEAmVS6FFggtc7W9as7sEENvjt7DqMi21KCEAmVS6FFggtc7W9as7sEENvjt7DqMi2", 0, 0, "", ErrInvalidAddressLength},
+		{caseEmpty, "", 0, 0, "", ErrInvalidAddressLength},
+		{"unknown version", string(base58EncodeBytes(litecoin)), 0, 0, "", ErrUnknownAddressVersion},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			parsed, err := ParseAddress(test.input)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				assert.Nil(t, parsed)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.input, parsed.Address)
+			assert.Equal(t, test.expectedNetwork, parsed.Network)
+			assert.Equal(t, test.expectedType, parsed.Type)
+			assert.Equal(t, test.expectedHash160, hex.EncodeToString(parsed.Hash160[:]))
+		})
+	}
+
+	t.Run("generated addresses", func(t *testing.T) {
+		t.Parallel()
+		for _, mainnet := range []bool{true, false} {
+			address, err := GetAddressFromPubKeyString(testPubKeyCompressed, true, mainnet)
+			require.NoError(t, err)
+			parsed, err := ParseAddress(address.AddressString)
+			require.NoError(t, err)
+			assert.Equal(t, networkFromMainnet(mainnet), parsed.Network)
+			assert.Equal(t, address.PublicKeyHash, hex.EncodeToString(parsed.Hash160[:]))
+		}
+	})
+}
+
+// TestValidateAddress will test the method ValidateAddress()
+func TestValidateAddress(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		input         string
+		network       Network
+		expectedError error
+	}{
+		{"mainnet", "1KCEAmVS6FFggtc7W9as7sEENvjt7DqMi2", NetworkMainnet, nil},
+		{"mainnet p2sh", "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", NetworkMainnet, nil},
+		{"testnet", "mmobaZaCeFGujSmej9ESfohgfWjXBW1u7m", NetworkTestnet, nil},
+		{"regtest", "mmobaZaCeFGujSmej9ESfohgfWjXBW1u7m", NetworkRegtest, nil},
+		{"mainnet address on testnet", "1KCEAmVS6FFggtc7W9as7sEENvjt7DqMi2", NetworkTestnet, ErrNetworkMismatch},
+		{"testnet address on mainnet", "2MzQwSSnBHWHqSAqtTVQ6v47XtaisrJa1Vc", NetworkMainnet, ErrNetworkMismatch},
+		{"bad checksum", "1KCEAmVS6FFggtc7W9as7sEENvjt7DqMi", NetworkMainnet, ErrChecksumMismatch},
+		{"bad character", "0KCEAmVS6FFggtc7W9as7sEENvjt7DqMi2", NetworkMainnet, ErrBadCharacter},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateAddress(test.input, test.network)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+// ExampleParseAddress example using ParseAddress()
+func ExampleParseAddress() {
+	parsed, err := ParseAddress("mmobaZaCeFGujSmej9ESfohgfWjXBW1u7m")
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("%s %s address, hash160: %x", parsed.Network, parsed.Type, parsed.Hash160)
+	// Output:testnet p2pkh address, hash160: 44f688a2961f9f5d301d0c1a1e674096f37fa688
+}
+
+// BenchmarkParseAddress benchmarks the method ParseAddress()
+func BenchmarkParseAddress(b *testing.B) {
+	for b.Loop() {
+		_, _ = ParseAddress("1KCEAmVS6FFggtc7W9as7sEENvjt7DqMi2")
+	}
+}
+
+// ExampleValidateAddress example using ValidateAddress()
+func ExampleValidateAddress() {
+	err := ValidateAddress("mmobaZaCeFGujSmej9ESfohgfWjXBW1u7m", NetworkMainnet)
+	fmt.Printf("mainnet: %t, testnet: %t",
+		err == nil, ValidateAddress("mmobaZaCeFGujSmej9ESfohgfWjXBW1u7m", NetworkTestnet) == nil)
+	// Output:mainnet: false, testnet: true
+}
+
 // TestGetAddressFromPrivateKey will test the method GetAddressFromPrivateKey()
 func TestGetAddressFromPrivateKey(t *testing.T) {
 	t.Parallel()
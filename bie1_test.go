@@ -0,0 +1,276 @@
+package bitcoin
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	ecies "github.com/bsv-blockchain/go-sdk/compat/ecies"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// testBIE1WIF / testBIE1Message / testBIE1Vector are the Electrum (bsv.js)
+	// ECIES vector for a self-encrypted message using the key as the sender
+	testBIE1WIF     = "L211enC224G1kV8pyyq7bjVd9SxZebnRYEzzM3i7ZHCc1c5E7dQu"
+	testBIE1Message = "hello world"
+	testBIE1Vector  = "QklFMQO7zpX/GS4XpthCy6/hT38ZKsBGbn8JKMGHOY5ifmaoT890Krt9cIRk/ULXaB5uC08owRICzenFbm31pZGu0gCM2uOxpofwHacKidwZ0Q7aEw=="
+
+	// testBIE1LongMessage is longer than 2 blocks (go-sdk expects an embedded key
+	// in longer payloads)
+	testBIE1LongMessage = "a message that is longer than two AES blocks"
+
+	// testBIE1CounterpartyWIF is a second key used for the two-party vectors
+	testBIE1CounterpartyWIF = "L27ZSAC1xTsZrghYHqnxwAQZ12bH57piaAdoGaLizTp3JZrjkZjK"
+)
+
+// TestEncryptBIE1Vector will test EncryptBIE1() and DecryptBIE1() against a
+// known Electrum / bsv.js vector
+func TestEncryptBIE1Vector(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := WifToPrivateKey(testBIE1WIF)
+	require.NoError(t, err)
+
+	expected, err := base64.StdEncoding.DecodeString(testBIE1Vector)
+	require.NoError(t, err)
+
+	// Encrypting with a fixed sender key is deterministic
+	encrypted, err := EncryptBIE1(privateKey.PubKey(), []byte(testBIE1Message), privateKey, false)
+	require.NoError(t, err)
+	assert.Equal(t, expected, encrypted)
+
+	// Decrypt the vector using the embedded key
+	var decrypted []byte
+	decrypted, err = DecryptBIE1(privateKey, expected, nil)
+	require.NoError(t, err)
+	assert.Equal(t, testBIE1Message, string(decrypted))
+}
+
+// TestEncryptBIE1 will test the methods EncryptBIE1() and DecryptBIE1()
+func TestEncryptBIE1(t *testing.T) {
+	t.Parallel()
+
+	sender, err := WifToPrivateKey(testBIE1WIF)
+	require.NoError(t, err)
+	recipient, err := WifToPrivateKey(testBIE1CounterpartyWIF)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		sender       *ec.PrivateKey
+		omitKey      bool
+		senderPubKey *ec.PublicKey
+	}{
+		{"ephemeral key", nil, false, nil},
+		{"sender key embedded", sender, false, nil},
+		{"sender key embedded and known", sender, false, sender.PubKey()},
+		{"sender key omitted", sender, true, sender.PubKey()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			for _, data := range []string{"", "a", testEncryptionMessage, "exactly 16 bytes", testBIE1LongMessage} {
+				encrypted, err := EncryptBIE1(recipient.PubKey(), []byte(data), test.sender, test.omitKey)
+				require.NoError(t, err)
+				assert.Equal(t, EncryptionFormatBIE1, DetectEncryptionFormat(encrypted))
+
+				decrypted, err := DecryptBIE1(recipient, encrypted, test.senderPubKey)
+				require.NoError(t, err)
+				assert.Equal(t, data, string(decrypted))
+			}
+		})
+	}
+}
+
+// TestEncryptBIE1Compatibility will test interoperability with the go-sdk
+// Electrum ECIES implementation in both directions
+func TestEncryptBIE1Compatibility(t *testing.T) {
+	t.Parallel()
+
+	sender, err := WifToPrivateKey(testBIE1WIF)
+	require.NoError(t, err)
+	recipient, err := WifToPrivateKey(testBIE1CounterpartyWIF)
+	require.NoError(t, err)
+
+	for _, omitKey := range []bool{false, true} {
+		t.Run(fmt.Sprintf("omit key %t", omitKey), func(t *testing.T) {
+			t.Parallel()
+
+			// go-sdk -> go-bitcoin
+			for _, message := range []string{testBIE1Message, testBIE1LongMessage} {
+				encrypted, err := ecies.ElectrumEncrypt([]byte(message), recipient.PubKey(), sender, omitKey)
+				require.NoError(t, err)
+				decrypted, err := DecryptBIE1(recipient, encrypted, sender.PubKey())
+				require.NoError(t, err)
+				assert.Equal(t, message, string(decrypted))
+			}
+
+			// go-bitcoin -> go-sdk
+			encrypted, err := EncryptBIE1(recipient.PubKey(), []byte(testBIE1Message), sender, omitKey)
+			require.NoError(t, err)
+			decrypted, err := ecies.ElectrumDecrypt(encrypted, recipient, sender.PubKey())
+			require.NoError(t, err)
+			assert.Equal(t, testBIE1Message, string(decrypted))
+		})
+	}
+}
+
+// TestEncryptBIE1Errors will test the error cases of EncryptBIE1() and DecryptBIE1()
+func TestEncryptBIE1Errors(t *testing.T) {
+	t.Parallel()
+
+	sender, err := WifToPrivateKey(testBIE1WIF)
+	require.NoError(t, err)
+	recipient, err := WifToPrivateKey(testBIE1CounterpartyWIF)
+	require.NoError(t, err)
+
+	t.Run("nil public key", func(t *testing.T) {
+		_, err := EncryptBIE1(nil, []byte("data"), sender, false)
+		require.ErrorIs(t, err, ErrPublicKeyNil)
+	})
+
+	t.Run("omit key without sender", func(t *testing.T) {
+		_, err := EncryptBIE1(recipient.PubKey(), []byte("data"), nil, true)
+		require.ErrorIs(t, err, ErrBIE1SenderRequired)
+	})
+
+	encrypted, err := EncryptBIE1(recipient.PubKey(), []byte("data"), nil, false)
+	require.NoError(t, err)
+
+	t.Run("nil private key", func(t *testing.T) {
+		_, err := DecryptBIE1(nil, encrypted, nil)
+		require.ErrorIs(t, err, ErrPrivateKeyMissing)
+	})
+
+	t.Run("too short", func(t *testing.T) {
+		_, err := DecryptBIE1(recipient, encrypted[:bie1MinLength-1], nil)
+		require.ErrorIs(t, err, errInputTooShort)
+	})
+
+	t.Run("bad magic", func(t *testing.T) {
+		tampered := append([]byte{}, encrypted...)
+		tampered[3] = '2'
+		_, err := DecryptBIE1(recipient, tampered, nil)
+		require.ErrorIs(t, err, errInvalidBIE1Magic)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		_, err := DecryptBIE1(sender, encrypted, nil)
+		require.ErrorIs(t, err, ErrInvalidMAC)
+	})
+
+	t.Run("tampered cipher text", func(t *testing.T) {
+		tampered := append([]byte{}, encrypted...)
+		tampered[len(tampered)-40] ^= 0x01
+		_, err := DecryptBIE1(recipient, tampered, nil)
+		require.ErrorIs(t, err, ErrInvalidMAC)
+	})
+
+	t.Run("invalid cipher text length", func(t *testing.T) {
+		_, err := DecryptBIE1(recipient, append(append([]byte{}, encrypted...), 0x00), nil)
+		require.ErrorIs(t, err, errInvalidPadding)
+	})
+
+	t.Run("tampered cipher text without key", func(t *testing.T) {
+		omitted, err := EncryptBIE1(recipient.PubKey(), []byte(testBIE1LongMessage), sender, true)
+		require.NoError(t, err)
+		omitted[10] ^= 0x01
+		_, err = DecryptBIE1(recipient, omitted, sender.PubKey())
+		require.ErrorIs(t, err, ErrInvalidMAC)
+	})
+
+	t.Run("omitted key requires sender", func(t *testing.T) {
+		omitted, err := EncryptBIE1(recipient.PubKey(), []byte("data"), sender, true)
+		require.NoError(t, err)
+		_, err = DecryptBIE1(recipient, omitted, nil)
+		require.ErrorIs(t, err, ErrBIE1SenderRequired)
+	})
+}
+
+// TestEncryptWithPrivateKeyFormat will test the method EncryptWithPrivateKeyFormat()
+// and the format detection of DecryptWithPrivateKey()
+func TestEncryptWithPrivateKeyFormat(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := PrivateKeyFromString(testPrivateKeyHex)
+	require.NoError(t, err)
+
+	for _, format := range []EncryptionFormat{EncryptionFormatLegacy, EncryptionFormatBRC78, EncryptionFormatBIE1} {
+		t.Run(format.String(), func(t *testing.T) {
+			t.Parallel()
+			encrypted, err := EncryptWithPrivateKeyFormat(privateKey, testEncryptionMessage, format)
+			require.NoError(t, err)
+			assert.Equal(t, format, DetectEncryptionFormatString(encrypted))
+
+			decrypted, err := DecryptWithPrivateKey(privateKey, encrypted)
+			require.NoError(t, err)
+			assert.Equal(t, testEncryptionMessage, decrypted)
+
+			encrypted, err = EncryptWithPrivateKeyStringFormat(testPrivateKeyHex, testEncryptionMessage, format)
+			require.NoError(t, err)
+
+			decrypted, err = DecryptWithPrivateKeyString(testPrivateKeyHex, encrypted)
+			require.NoError(t, err)
+			assert.Equal(t, testEncryptionMessage, decrypted)
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		t.Parallel()
+		_, err := EncryptWithPrivateKeyFormat(privateKey, testEncryptionMessage, EncryptionFormatUnknown)
+		require.ErrorIs(t, err, ErrUnknownEncryptionFormat)
+		_, err = EncryptWithPrivateKeyStringFormat(testPrivateKeyHex, testEncryptionMessage, EncryptionFormat(99))
+		require.ErrorIs(t, err, ErrUnknownEncryptionFormat)
+		_, err = EncryptWithPrivateKeyStringFormat("", testEncryptionMessage, EncryptionFormatBIE1)
+		require.ErrorIs(t, err, ErrPrivateKeyMissing)
+	})
+
+	t.Run("self encrypted with omitted key", func(t *testing.T) {
+		t.Parallel()
+		encrypted, err := EncryptBIE1(privateKey.PubKey(), []byte(testEncryptionMessage), privateKey, true)
+		require.NoError(t, err)
+
+		decrypted, err := decryptAnyFormat(privateKey, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, testEncryptionMessage, string(decrypted))
+	})
+}
+
+// ExampleEncryptBIE1 example using EncryptBIE1()
+func ExampleEncryptBIE1() {
+	privateKey, err := WifToPrivateKey(testBIE1WIF)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	// Encrypt using the key as the sender (deterministic)
+	var encrypted []byte
+	if encrypted, err = EncryptBIE1(privateKey.PubKey(), []byte("hello world"), privateKey, false); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("encrypted: %s", base64.StdEncoding.EncodeToString(encrypted))
+	// Output:encrypted: QklFMQO7zpX/GS4XpthCy6/hT38ZKsBGbn8JKMGHOY5ifmaoT890Krt9cIRk/ULXaB5uC08owRICzenFbm31pZGu0gCM2uOxpofwHacKidwZ0Q7aEw==
+}
+
+// BenchmarkEncryptBIE1 benchmarks the method EncryptBIE1()
+func BenchmarkEncryptBIE1(b *testing.B) {
+	key, _ := CreatePrivateKey()
+	for b.Loop() {
+		_, _ = EncryptBIE1(key.PubKey(), []byte("some-data"), nil, false)
+	}
+}
+
+// BenchmarkDecryptBIE1 benchmarks the method DecryptBIE1()
+func BenchmarkDecryptBIE1(b *testing.B) {
+	key, _ := CreatePrivateKey()
+	encrypted, _ := EncryptBIE1(key.PubKey(), []byte("some-data"), nil, false)
+	for b.Loop() {
+		_, _ = DecryptBIE1(key, encrypted, nil)
+	}
+}
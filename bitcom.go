@@ -0,0 +1,97 @@
+package bitcoin
+
+import (
+	"github.com/bsv-blockchain/go-bt/v2"
+	script "github.com/bsv-blockchain/go-sdk/script"
+)
+
+// BitcomSeparator separates the protocols of an OP_RETURN pipeline
+const BitcomSeparator = "|"
+
+// BitcomProtocol is one protocol (B://, MAP, AIP, ...) of an OP_RETURN
+// pipeline: its prefix and the pushes after it, up to the next separator
+type BitcomProtocol struct {
+	Vout   uint32
+	Prefix string
+	Args   [][]byte
+}
+
+// JoinBitcomProtocols will join the pushes of protocols into one pipeline,
+// separated by "|", for CreateTx
+func JoinBitcomProtocols(protocols ...OpReturnData) OpReturnData {
+	var joined OpReturnData
+	for i, protocol := range protocols {
+		if i > 0 {
+			joined = append(joined, []byte(BitcomSeparator))
+		}
+		joined = append(joined, protocol...)
+	}
+	return joined
+}
+
+// GetBitcomProtocols will get the protocols of the OP_RETURN pipelines in
+// every output of a transaction (see ScriptBitcomProtocols)
+//
+// Expects tx to not be nil (otherwise will panic)
+func GetBitcomProtocols(tx *bt.Tx) []BitcomProtocol {
+	var protocols []BitcomProtocol
+	for vout, output := range tx.Outputs {
+		if output.LockingScript == nil {
+			continue
+		}
+		for _, protocol := range ScriptBitcomProtocols(*output.LockingScript) {
+			protocol.Vout = uint32(vout) // #nosec G115 -- outputs are limited by the tx size
+			protocols = append(protocols, protocol)
+		}
+	}
+	return protocols
+}
+
+// ScriptBitcomProtocols will split the pushes after the first OP_RETURN of a
+// locking script into protocols, separated by "|"
+//
+// The OP_RETURN may be preceded by OP_FALSE or by a locking script (like a
+// 1Sat ordinal). Empty pushes (OP_0) are kept as empty args.
+func ScriptBitcomProtocols(lockingScript []byte) []BitcomProtocol {
+	pushes := opReturnPushes(lockingScript)
+
+	var protocols []BitcomProtocol
+	var current *BitcomProtocol
+	for _, push := range pushes {
+		switch {
+		case string(push) == BitcomSeparator:
+			current = nil
+		case current == nil:
+			protocols = append(protocols, BitcomProtocol{Prefix: string(push)})
+			current = &protocols[len(protocols)-1]
+		default:
+			current.Args = append(current.Args, push)
+		}
+	}
+	return protocols
+}
+
+// opReturnPushes returns the pushes after the first OP_RETURN of a script
+// (OP_0 to OP_16 and OP_1NEGATE are the bytes they push; other opcodes are skipped).
+func opReturnPushes(lockingScript []byte) [][]byte {
+	chunks, _ := scriptChunks(lockingScript)
+	for i, chunk := range chunks {
+		if chunk.Op != script.OpRETURN {
+			continue
+		}
+
+		var pushes [][]byte
+		for _, data := range chunks[i+1:] {
+			switch {
+			case data.Op <= script.OpPUSHDATA4:
+				pushes = append(pushes, append([]byte{}, data.Data...))
+			case data.Op == script.Op1NEGATE:
+				pushes = append(pushes, []byte{0x81})
+			case data.Op >= script.Op1 && data.Op <= script.Op16:
+				pushes = append(pushes, []byte{data.Op - script.Op1 + 1})
+			}
+		}
+		return pushes
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/bsv-blockchain/go-bt/v2"
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	script "github.com/bsv-blockchain/go-sdk/script"
+)
+
+// AIPPrefix is the AIP (Author Identity Protocol) prefix
+const AIPPrefix = "15PciHG22SNLQJXMoSUaWVi7WSqc7hCfva"
+
+// AIPAlgorithmBitcoinECDSA is the AIP algorithm for Bitcoin Signed Message signatures
+const AIPAlgorithmBitcoinECDSA = "BITCOIN_ECDSA"
+
+// ErrInvalidAIP is returned when an AIP protocol or its field indexes are invalid
+var ErrInvalidAIP = errors.New("invalid AIP data")
+
+// AIPData is an AIP (Author Identity Protocol) signature
+//
+// Fields are numbered from the OP_RETURN (0), counting every push including
+// the "|" separators. Without indexes the signature covers every field before
+// the AIP prefix. Spec: https://github.com/attilaaf/AUTHOR_IDENTITY_PROTOCOL
+type AIPData struct {
+	Vout      uint32
+	Algorithm string
+	Address   string
+	Signature string
+	Indexes   []int
+}
+
+// SignAIP will sign the fields of a pipeline (all fields, or the field indexes)
+// with a Bitcoin Signed Message signature from the compressed key, and return
+// the pipeline with the AIP protocol appended
+func SignAIP(data OpReturnData, privateKey *ec.PrivateKey, mainnet bool, indexes ...int) (OpReturnData, error) {
+	if privateKey == nil {
+		return nil, ErrPrivateKeyMissing
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: nothing to sign", ErrInvalidAIP)
+	}
+
+	address, err := GetAddressFromPrivateKey(privateKey, true, mainnet)
+	if err != nil {
+		return nil, err
+	}
+
+	// The signed fields include the separator before the AIP prefix
+	signed := append(append(OpReturnData{}, data...), []byte(BitcomSeparator))
+	message, err := aipMessage(signed, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	var sigBytes []byte
+	if sigBytes, err = bsm.SignMessageWithCompression(privateKey, message, true); err != nil {
+		return nil, err
+	}
+
+	aip := OpReturnData{
+		[]byte(AIPPrefix),
+		[]byte(AIPAlgorithmBitcoinECDSA),
+		[]byte(address),
+		[]byte(base64.StdEncoding.EncodeToString(sigBytes)),
+	}
+	for _, index := range indexes {
+		aip = append(aip, []byte(strconv.Itoa(index)))
+	}
+	return append(signed, aip...), nil
+}
+
+// ParseAIPData will parse an AIP protocol from a pipeline (see GetBitcomProtocols)
+//
+// This does not verify the signature (see VerifyAIP).
+func ParseAIPData(protocol BitcomProtocol) (*AIPData, error) {
+	if protocol.Prefix != AIPPrefix {
+		return nil, fmt.Errorf("%w: prefix is %q", ErrInvalidAIP, protocol.Prefix)
+	}
+	if len(protocol.Args) < 3 {
+		return nil, fmt.Errorf("%w: expected algorithm, address and signature, got %d args", ErrInvalidAIP, len(protocol.Args))
+	}
+
+	a := &AIPData{
+		Vout:      protocol.Vout,
+		Algorithm: string(protocol.Args[0]),
+		Address:   string(protocol.Args[1]),
+		Signature: string(protocol.Args[2]),
+	}
+	if a.Algorithm != AIPAlgorithmBitcoinECDSA {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidAIP, a.Algorithm)
+	}
+	for _, arg := range protocol.Args[3:] {
+		index, err := strconv.Atoi(string(arg))
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("%w: invalid field index %q", ErrInvalidAIP, arg)
+		}
+		a.Indexes = append(a.Indexes, index)
+	}
+	return a, nil
+}
+
+// VerifyAIPData will verify every AIP signature in a pipeline (as built by SignAIP)
+//
+// Returns the verified signatures, and an error joining the failure of every
+// other AIP signature (malformed, unsupported algorithm or not verifying).
+func VerifyAIPData(data OpReturnData) ([]*AIPData, error) {
+	return verifyAIPPushes(data, 0)
+}
+
+// VerifyAIP will verify every AIP signature in the outputs of a transaction
+// against the fields before it and its declared address
+//
+// Outputs are independent: a bad AIP signature in one output does not stop the
+// others from being verified. Returns every verified signature, and an error
+// joining the failure of every other AIP signature (malformed, unsupported
+// algorithm or not verifying), prefixed with its output.
+//
+// Expects tx to not be nil (otherwise will panic)
+func VerifyAIP(tx *bt.Tx) ([]*AIPData, error) {
+	var verified []*AIPData
+	var errs []error
+	for vout, output := range tx.Outputs {
+		if output.LockingScript == nil {
+			continue
+		}
+		signatures, err := verifyAIPPushes(
+			opReturnPushes(*output.LockingScript),
+			uint32(vout), // #nosec G115 -- outputs are limited by the tx size
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("output %d: %w", vout, err))
+		}
+		verified = append(verified, signatures...)
+	}
+	return verified, errors.Join(errs...)
+}
+
+// verifyAIPPushes verifies the AIP protocols in the pushes after an OP_RETURN,
+// returning the verified ones and the joined errors of the others.
+func verifyAIPPushes(pushes [][]byte, vout uint32) ([]*AIPData, error) {
+	var verified []*AIPData
+	var errs []error
+	for start := 0; start < len(pushes); start++ {
+		if string(pushes[start]) != AIPPrefix || (start > 0 && string(pushes[start-1]) != BitcomSeparator) {
+			continue
+		}
+
+		end := start + 1
+		for end < len(pushes) && string(pushes[end]) != BitcomSeparator {
+			end++
+		}
+
+		a, err := verifyAIPProtocol(pushes[:start], BitcomProtocol{Vout: vout, Prefix: AIPPrefix, Args: pushes[start+1 : end]})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		verified = append(verified, a)
+	}
+	return verified, errors.Join(errs...)
+}
+
+// verifyAIPProtocol verifies one AIP protocol against the pushes before it.
+func verifyAIPProtocol(pushes [][]byte, protocol BitcomProtocol) (*AIPData, error) {
+	a, err := ParseAIPData(protocol)
+	if err != nil {
+		return nil, err
+	}
+	message, err := aipMessage(pushes, a.Indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed *ParsedAddress
+	if parsed, err = ParseAddress(a.Address); err != nil {
+		return nil, err
+	}
+	if err = VerifyMessage(a.Address, a.Signature, string(message), parsed.Network.IsMainnet()); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// aipMessage concatenates the signed fields: the OP_RETURN (field 0) and the
+// pushes before the AIP prefix, or only the field indexes.
+func aipMessage(pushes [][]byte, indexes []int) ([]byte, error) {
+	fields := append([][]byte{{script.OpRETURN}}, pushes...)
+	if len(indexes) == 0 {
+		return bytes.Join(fields, nil), nil
+	}
+
+	var message []byte
+	for _, index := range indexes {
+		if index < 0 || index >= len(fields) {
+			return nil, fmt.Errorf("%w: field index %d out of range (%d fields)", ErrInvalidAIP, index, len(fields))
+		}
+		message = append(message, fields[index]...)
+	}
+	return message, nil
+}
@@ -0,0 +1,253 @@
+package bitcoin
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mustAIPData returns a B:// | MAP pipeline to sign in tests
+func mustAIPData(t *testing.T) OpReturnData {
+	t.Helper()
+	b, err := (&BData{Data: []byte("hello"), MediaType: "text/plain"}).OpReturnData()
+	require.NoError(t, err)
+	set, err := NewMAPSet("app", "myapp")
+	require.NoError(t, err)
+	return JoinBitcomProtocols(b, set)
+}
+
+// TestSignAIP will test the methods SignAIP() and VerifyAIPData()
+func TestSignAIP(t *testing.T) {
+	t.Parallel()
+
+	privateKey := mustTestPrivKey(t)
+	address, err := GetAddressFromPrivateKey(privateKey, true, true)
+	require.NoError(t, err)
+
+	t.Run("all fields", func(t *testing.T) {
+		t.Parallel()
+		data := mustAIPData(t)
+		signed, err := SignAIP(data, privateKey, true)
+		require.NoError(t, err)
+		require.Len(t, signed, len(data)+5)
+		assert.Equal(t, data, signed[:len(data)])
+		assert.Equal(t, []byte(BitcomSeparator), signed[len(data)])
+		assert.Equal(t, []byte(AIPPrefix), signed[len(data)+1])
+
+		verified, err := VerifyAIPData(signed)
+		require.NoError(t, err)
+		require.Len(t, verified, 1)
+		assert.Equal(t, AIPAlgorithmBitcoinECDSA, verified[0].Algorithm)
+		assert.Equal(t, address, verified[0].Address)
+		assert.Empty(t, verified[0].Indexes)
+	})
+
+	t.Run("field indexes", func(t *testing.T) {
+		t.Parallel()
+		data := mustAIPData(t)
+		signed, err := SignAIP(data, privateKey, true, 0, 2, 3)
+		require.NoError(t, err)
+		assert.Equal(t, OpReturnData{[]byte("0"), []byte("2"), []byte("3")}, signed[len(signed)-3:])
+
+		verified, err := VerifyAIPData(signed)
+		require.NoError(t, err)
+		require.Len(t, verified, 1)
+		assert.Equal(t, []int{0, 2, 3}, verified[0].Indexes)
+
+		// Fields that are not signed can change
+		signed[7] = []byte("otherapp")
+		_, err = VerifyAIPData(signed)
+		require.NoError(t, err)
+
+		signed[2] = []byte("tampered")
+		_, err = VerifyAIPData(signed)
+		require.ErrorIs(t, err, ErrAddressNotFound)
+	})
+
+	t.Run("testnet", func(t *testing.T) {
+		t.Parallel()
+		signed, err := SignAIP(mustAIPData(t), privateKey, false)
+		require.NoError(t, err)
+		verified, err := VerifyAIPData(signed)
+		require.NoError(t, err)
+		require.Len(t, verified, 1)
+		assert.NotEqual(t, address, verified[0].Address)
+	})
+
+	t.Run("tampered data", func(t *testing.T) {
+		t.Parallel()
+		signed, err := SignAIP(mustAIPData(t), privateKey, true)
+		require.NoError(t, err)
+		signed[2] = []byte("tampered")
+		_, err = VerifyAIPData(signed)
+		require.ErrorIs(t, err, ErrAddressNotFound)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		_, err := SignAIP(mustAIPData(t), nil, true)
+		require.ErrorIs(t, err, ErrPrivateKeyMissing)
+		_, err = SignAIP(nil, privateKey, true)
+		require.ErrorIs(t, err, ErrInvalidAIP)
+		_, err = SignAIP(mustAIPData(t), privateKey, true, 100)
+		require.ErrorIs(t, err, ErrInvalidAIP)
+		_, err = SignAIP(mustAIPData(t), privateKey, true, -1)
+		require.ErrorIs(t, err, ErrInvalidAIP)
+	})
+
+	t.Run("no AIP", func(t *testing.T) {
+		t.Parallel()
+		verified, err := VerifyAIPData(mustAIPData(t))
+		require.NoError(t, err)
+		assert.Empty(t, verified)
+	})
+}
+
+// TestParseAIPData will test the method ParseAIPData()
+func TestParseAIPData(t *testing.T) {
+	t.Parallel()
+
+	protocol := func(args ...string) BitcomProtocol {
+		p := BitcomProtocol{Vout: 1, Prefix: AIPPrefix}
+		for _, arg := range args {
+			p.Args = append(p.Args, []byte(arg))
+		}
+		return p
+	}
+
+	tests := []struct {
+		name          string
+		protocol      BitcomProtocol
+		expected      *AIPData
+		expectedError error
+	}{
+		{"valid", protocol(AIPAlgorithmBitcoinECDSA, testAddress, "sig"), &AIPData{Vout: 1, Algorithm: AIPAlgorithmBitcoinECDSA, Address: testAddress, Signature: "sig"}, nil},
+		{"indexes", protocol(AIPAlgorithmBitcoinECDSA, testAddress, "sig", "1", "10"), &AIPData{Vout: 1, Algorithm: AIPAlgorithmBitcoinECDSA, Address: testAddress, Signature: "sig", Indexes: []int{1, 10}}, nil},
+		{"wrong prefix", BitcomProtocol{Prefix: MAPPrefix}, nil, ErrInvalidAIP},
+		{"missing signature", protocol(AIPAlgorithmBitcoinECDSA, testAddress), nil, ErrInvalidAIP},
+		{"unsupported algorithm", protocol("OTHER", testAddress, "sig"), nil, ErrInvalidAIP},
+		{"invalid index", protocol(AIPAlgorithmBitcoinECDSA, testAddress, "sig", "x"), nil, ErrInvalidAIP},
+		{"negative index", protocol(AIPAlgorithmBitcoinECDSA, testAddress, "sig", "-1"), nil, ErrInvalidAIP},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			a, err := ParseAIPData(test.protocol)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, a)
+		})
+	}
+}
+
+// TestVerifyAIP will test the method VerifyAIP() with transactions from CreateTx()
+func TestVerifyAIP(t *testing.T) {
+	t.Parallel()
+
+	privateKey := mustTestPrivKey(t)
+	signed, err := SignAIP(mustAIPData(t), privateKey, true)
+	require.NoError(t, err)
+
+	tx, err := CreateTx(
+		[]*Utxo{newTestUtxo(1000)},
+		[]*PayToAddress{{Address: testAddress, Satoshis: 500}},
+		[]OpReturnData{signed},
+		privateKey,
+	)
+	require.NoError(t, err)
+
+	verified, err := VerifyAIP(tx)
+	require.NoError(t, err)
+	require.Len(t, verified, 1)
+	assert.Equal(t, uint32(1), verified[0].Vout)
+
+	// Parsing the pipeline finds the same AIP
+	var protocols []BitcomProtocol
+	for _, protocol := range GetBitcomProtocols(tx) {
+		if protocol.Prefix == AIPPrefix {
+			protocols = append(protocols, protocol)
+		}
+	}
+	require.Len(t, protocols, 1)
+	parsed, err := ParseAIPData(protocols[0])
+	require.NoError(t, err)
+	assert.Equal(t, verified[0], parsed)
+
+	t.Run("invalid signature", func(t *testing.T) {
+		t.Parallel()
+		signed, err := SignAIP(mustAIPData(t), privateKey, true)
+		require.NoError(t, err)
+		signed[1] = []byte("tampered")
+		tx, err := CreateTx(nil, nil, []OpReturnData{mustAIPData(t), signed}, nil)
+		require.NoError(t, err)
+		_, err = VerifyAIP(tx)
+		require.ErrorIs(t, err, ErrAddressNotFound)
+	})
+
+	t.Run("bad signatures do not hide valid ones", func(t *testing.T) {
+		t.Parallel()
+		tampered, err := SignAIP(mustAIPData(t), privateKey, true)
+		require.NoError(t, err)
+		tampered[1] = []byte("tampered")
+		unsupported := slices.Clone(signed)
+		unsupported[len(unsupported)-3] = []byte("OTHER_ALGORITHM")
+
+		tx, err := CreateTx(nil, nil, []OpReturnData{tampered, signed, unsupported}, nil)
+		require.NoError(t, err)
+		verified, err := VerifyAIP(tx)
+		require.ErrorIs(t, err, ErrAddressNotFound)
+		require.ErrorIs(t, err, ErrInvalidAIP)
+		assert.Contains(t, err.Error(), "output 0")
+		assert.Contains(t, err.Error(), "output 2")
+		require.Len(t, verified, 1)
+		assert.Equal(t, uint32(1), verified[0].Vout)
+	})
+}
+
+// ExampleSignAIP example using SignAIP()
+func ExampleSignAIP() {
+	privateKey, err := PrivateKeyFromString(testPrivateKeyHex)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	data, _ := NewMAPSet("app", "myapp")
+	if data, err = SignAIP(data, privateKey, true); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	var verified []*AIPData
+	if verified, err = VerifyAIPData(data); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("signed by: %s", verified[0].Address)
+	// Output:signed by: 1DfGxKmgL3ETwUdNnXLBueEvNpjcDGcKgK
+}
+
+// BenchmarkSignAIP benchmarks the method SignAIP()
+func BenchmarkSignAIP(b *testing.B) {
+	privateKey, _ := PrivateKeyFromString(testPrivateKeyHex)
+	data, _ := NewMAPSet("app", "myapp")
+	for b.Loop() {
+		_, _ = SignAIP(data, privateKey, true)
+	}
+}
+
+// BenchmarkVerifyAIPData benchmarks the method VerifyAIPData()
+func BenchmarkVerifyAIPData(b *testing.B) {
+	privateKey, _ := PrivateKeyFromString(testPrivateKeyHex)
+	data, _ := NewMAPSet("app", "myapp")
+	signed, _ := SignAIP(data, privateKey, true)
+	for b.Loop() {
+		_, _ = VerifyAIPData(signed)
+	}
+}
@@ -0,0 +1,92 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bsv-blockchain/go-bt/v2"
+)
+
+// BPrefix is the B:// (Bitcoin Data) protocol prefix
+const BPrefix = "19HxigV4QyBv3tHpQVcUEQyq1pzZVdoAut"
+
+// BEncodingBinary is the default B:// encoding
+const BEncodingBinary = "binary"
+
+// ErrInvalidBData is returned when B:// data is missing its data or media type
+var ErrInvalidBData = errors.New("invalid B:// data")
+
+// BData is a B:// (Bitcoin Data) protocol payload
+//
+// Spec: https://b.bitcoinschema.org
+type BData struct {
+	Data      []byte
+	MediaType string
+	Encoding  string
+	Filename  string
+}
+
+// OpReturnData will build the B:// pushes for CreateTx (or JoinBitcomProtocols)
+//
+// The encoding defaults to "binary" and the filename is only added when set.
+func (b *BData) OpReturnData() (OpReturnData, error) {
+	if len(b.Data) == 0 {
+		return nil, fmt.Errorf("%w: missing data", ErrInvalidBData)
+	}
+	if b.MediaType == "" {
+		return nil, fmt.Errorf("%w: missing media type", ErrInvalidBData)
+	}
+
+	encoding := b.Encoding
+	if encoding == "" {
+		encoding = BEncodingBinary
+	}
+
+	data := OpReturnData{[]byte(BPrefix), b.Data, []byte(b.MediaType), []byte(encoding)}
+	if b.Filename != "" {
+		data = append(data, []byte(b.Filename))
+	}
+	return data, nil
+}
+
+// ParseBData will parse a B:// protocol from a pipeline (see GetBitcomProtocols)
+//
+// The encoding and filename are optional.
+func ParseBData(protocol BitcomProtocol) (*BData, error) {
+	if protocol.Prefix != BPrefix {
+		return nil, fmt.Errorf("%w: prefix is %q", ErrInvalidBData, protocol.Prefix)
+	}
+	if len(protocol.Args) < 2 {
+		return nil, fmt.Errorf("%w: expected data and media type, got %d args", ErrInvalidBData, len(protocol.Args))
+	}
+
+	b := &BData{
+		Data:      protocol.Args[0],
+		MediaType: string(protocol.Args[1]),
+	}
+	if len(protocol.Args) > 2 {
+		b.Encoding = string(protocol.Args[2])
+	}
+	if len(protocol.Args) > 3 {
+		b.Filename = string(protocol.Args[3])
+	}
+	return b, nil
+}
+
+// GetBData will get the valid B:// payloads from every output of a transaction,
+// including B:// protocols in multi-protocol pipelines
+//
+// Use GetBitcomProtocols and ParseBData to get the output index (Vout) or parse errors.
+// Expects tx to not be nil (otherwise will panic)
+func GetBData(tx *bt.Tx) []*BData {
+	var payloads []*BData
+	for _, protocol := range GetBitcomProtocols(tx) {
+		if protocol.Prefix != BPrefix {
+			continue
+		}
+		if b, err := ParseBData(protocol); err == nil {
+			payloads = append(payloads, b)
+		}
+	}
+	return payloads
+}
@@ -0,0 +1,356 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/bsv-blockchain/go-bt/v2"
+	base58 "github.com/bsv-blockchain/go-sdk/compat/base58"
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	hash "github.com/bsv-blockchain/go-sdk/primitives/hash"
+)
+
+// BAPPrefix is the BAP (Bitcoin Attestation Protocol) prefix
+const BAPPrefix = "1BAPSuaPnfGnSBM3GLV9yhxUdYe4vGbdMT"
+
+// BAPPurpose is the hardened purpose of BAP identity key paths:
+// m/424150'/0'/0'/0/0/n, where n = 0 is the root key and n > 0 the signing keys
+const BAPPurpose uint32 = 424150
+
+var (
+	// ErrInvalidBAP is returned when a BAP record is malformed
+	ErrInvalidBAP = errors.New("invalid BAP data")
+
+	// ErrInvalidBAPChain is returned when ID records do not form a chain of rotations
+	ErrInvalidBAPChain = errors.New("invalid BAP identity chain")
+)
+
+// BAPType is a BAP record type
+type BAPType string
+
+// BAP record types
+const (
+	BAPID     BAPType = "ID"
+	BAPAttest BAPType = "ATTEST"
+	BAPAlias  BAPType = "ALIAS"
+	BAPData   BAPType = "DATA"
+)
+
+// BAPIdentity is a BAP identity derived from an HD private key
+//
+// The identity key is computed from the root address (see BAPIdentityKey).
+// Each rotation moves to the next signing key and is published with an ID
+// record signed by the previous key. Spec: https://github.com/icellan/bap
+type BAPIdentity struct {
+	hdKey       *bip32.ExtendedKey // m/424150'/0'/0'/0/0
+	mainnet     bool
+	rootAddress string
+	identityKey string
+	counter     uint32
+	address     string
+}
+
+// BAPRecord is a parsed BAP record
+//
+// ID records use IdentityKey and Address, ATTEST uses AttestationHash and
+// Sequence, ALIAS uses IdentityKey and Data and DATA uses AttestationHash and
+// Data. Signer is the address of the AIP signature over the record (see GetBAPRecords).
+type BAPRecord struct {
+	Vout            uint32
+	Type            BAPType
+	IdentityKey     string
+	Address         string
+	AttestationHash string
+	Sequence        uint64
+	Data            []byte
+	Signer          string
+}
+
+// BAPIDChain is a validated chain of identity rotations
+//
+// Addresses are the signing addresses in order, the last one is the current one.
+type BAPIDChain struct {
+	IdentityKey string
+	RootAddress string
+	Addresses   []string
+}
+
+// BAPIdentityKey will compute the identity key of a root address:
+// base58(ripemd160(sha256(rootAddress)))
+func BAPIdentityKey(rootAddress string) string {
+	return base58.Encode(hash.Hash160([]byte(rootAddress)))
+}
+
+// NewBAPIdentity will derive a BAP identity from an HD private key, with the
+// signing key at counter (1 for a new identity)
+//
+// The addresses use the network of the HD key.
+// Expects hdKey to not be nil (otherwise will panic)
+func NewBAPIdentity(hdKey *bip32.ExtendedKey, counter uint32) (*BAPIdentity, error) {
+	if !hdKey.IsPrivate() {
+		return nil, fmt.Errorf("%w: BAP identities need an HD private key", ErrPrivateKeyMissing)
+	}
+	if counter == 0 {
+		return nil, fmt.Errorf("%w: counter 0 is the root key, signing keys start at 1", ErrInvalidBAP)
+	}
+
+	network, err := GetHDKeyNetwork(hdKey)
+	if err != nil {
+		return nil, err
+	}
+
+	id := &BAPIdentity{hdKey: hdKey, mainnet: network.IsMainnet()}
+	for _, num := range []uint32{bip32.HardenedKeyStart + BAPPurpose, bip32.HardenedKeyStart, bip32.HardenedKeyStart, 0, 0} {
+		if id.hdKey, err = GetHDKeyChild(id.hdKey, num); err != nil {
+			return nil, err
+		}
+	}
+
+	if id.rootAddress, err = id.signingAddress(0); err != nil {
+		return nil, err
+	}
+	id.identityKey = BAPIdentityKey(id.rootAddress)
+
+	if err = id.setCounter(counter); err != nil {
+		return nil, err
+	}
+	return id, nil
+}
+
+// IdentityKey will return the identity key
+func (id *BAPIdentity) IdentityKey() string {
+	return id.identityKey
+}
+
+// RootAddress will return the address of the root key
+func (id *BAPIdentity) RootAddress() string {
+	return id.rootAddress
+}
+
+// Counter will return the index of the current signing key
+func (id *BAPIdentity) Counter() uint32 {
+	return id.counter
+}
+
+// Address will return the address of the current signing key
+func (id *BAPIdentity) Address() string {
+	return id.address
+}
+
+// PrivateKey will return the current signing key
+func (id *BAPIdentity) PrivateKey() (*ec.PrivateKey, error) {
+	return id.signingKey(id.counter)
+}
+
+// IDRecord will build the ID record publishing the current signing address,
+// signed with AIP by the previous key (the root key for counter 1)
+func (id *BAPIdentity) IDRecord() (OpReturnData, error) {
+	return id.sign(
+		id.counter-1,
+		OpReturnData{[]byte(BAPPrefix), []byte(BAPID), []byte(id.identityKey), []byte(id.address)},
+	)
+}
+
+// Rotate will move to the next signing key and return its ID record
+// (see IDRecord); the identity is unchanged if this fails
+func (id *BAPIdentity) Rotate() (OpReturnData, error) {
+	previous := id.counter
+	if err := id.setCounter(previous + 1); err != nil {
+		return nil, err
+	}
+	record, err := id.IDRecord()
+	if err != nil {
+		_ = id.setCounter(previous)
+		return nil, err
+	}
+	return record, nil
+}
+
+// AttestRecord will build an ATTEST record for an attestation hash, signed
+// with AIP by the current key
+func (id *BAPIdentity) AttestRecord(attestationHash string, sequence uint64) (OpReturnData, error) {
+	if attestationHash == "" {
+		return nil, fmt.Errorf("%w: missing attestation hash", ErrInvalidBAP)
+	}
+	return id.sign(
+		id.counter,
+		OpReturnData{[]byte(BAPPrefix), []byte(BAPAttest), []byte(attestationHash), []byte(strconv.FormatUint(sequence, 10))},
+	)
+}
+
+// AliasRecord will build an ALIAS record with a profile (usually JSON), signed
+// with AIP by the current key
+func (id *BAPIdentity) AliasRecord(profile []byte) (OpReturnData, error) {
+	if len(profile) == 0 {
+		return nil, fmt.Errorf("%w: missing profile", ErrInvalidBAP)
+	}
+	return id.sign(
+		id.counter,
+		OpReturnData{[]byte(BAPPrefix), []byte(BAPAlias), []byte(id.identityKey), profile},
+	)
+}
+
+// DataRecord will build a DATA record attaching data to an attestation hash,
+// signed with AIP by the current key
+func (id *BAPIdentity) DataRecord(attestationHash string, data []byte) (OpReturnData, error) {
+	if attestationHash == "" {
+		return nil, fmt.Errorf("%w: missing attestation hash", ErrInvalidBAP)
+	}
+	return id.sign(
+		id.counter,
+		OpReturnData{[]byte(BAPPrefix), []byte(BAPData), []byte(attestationHash), data},
+	)
+}
+
+// setCounter moves to the signing key at counter.
+func (id *BAPIdentity) setCounter(counter uint32) error {
+	if counter >= bip32.HardenedKeyStart {
+		return fmt.Errorf("%w: counter %d is out of range", ErrInvalidBAP, counter)
+	}
+	address, err := id.signingAddress(counter)
+	if err != nil {
+		return err
+	}
+	id.counter, id.address = counter, address
+	return nil
+}
+
+// signingKey derives the signing key at counter (0 is the root key).
+func (id *BAPIdentity) signingKey(counter uint32) (*ec.PrivateKey, error) {
+	child, err := GetHDKeyChild(id.hdKey, counter)
+	if err != nil {
+		return nil, err
+	}
+	return GetPrivateKeyFromHDKey(child)
+}
+
+// signingAddress derives the address of the signing key at counter.
+func (id *BAPIdentity) signingAddress(counter uint32) (string, error) {
+	child, err := GetHDKeyChild(id.hdKey, counter)
+	if err != nil {
+		return "", err
+	}
+	return GetAddressStringFromHDKey(child, id.mainnet)
+}
+
+// sign signs a record with AIP by the signing key at counter.
+func (id *BAPIdentity) sign(counter uint32, record OpReturnData) (OpReturnData, error) {
+	privateKey, err := id.signingKey(counter)
+	if err != nil {
+		return nil, err
+	}
+	return SignAIP(record, privateKey, id.mainnet)
+}
+
+// ParseBAPRecord will parse a BAP protocol from a pipeline (see GetBitcomProtocols)
+//
+// This does not verify the AIP signature (see GetBAPRecords).
+func ParseBAPRecord(protocol BitcomProtocol) (*BAPRecord, error) {
+	if protocol.Prefix != BAPPrefix {
+		return nil, fmt.Errorf("%w: prefix is %q", ErrInvalidBAP, protocol.Prefix)
+	}
+	if len(protocol.Args) < 3 {
+		return nil, fmt.Errorf("%w: expected a type and 2 args, got %d args", ErrInvalidBAP, len(protocol.Args))
+	}
+
+	r := &BAPRecord{Vout: protocol.Vout, Type: BAPType(protocol.Args[0])}
+	first, second := protocol.Args[1], protocol.Args[2]
+	switch r.Type {
+	case BAPID:
+		r.IdentityKey, r.Address = string(first), string(second)
+	case BAPAttest:
+		sequence, err := strconv.ParseUint(string(second), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid sequence %q", ErrInvalidBAP, second)
+		}
+		r.AttestationHash, r.Sequence = string(first), sequence
+	case BAPAlias:
+		r.IdentityKey, r.Data = string(first), second
+	case BAPData:
+		r.AttestationHash, r.Data = string(first), second
+	default:
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidBAP, r.Type)
+	}
+	return r, nil
+}
+
+// GetBAPRecords will get the BAP records from every output of a transaction,
+// with Signer set to the address of the first AIP signature after the record
+// in the same output that signs all fields
+//
+// Returns an error if a record is malformed or an AIP signature is invalid.
+// Expects tx to not be nil (otherwise will panic)
+func GetBAPRecords(tx *bt.Tx) ([]*BAPRecord, error) {
+	var records []*BAPRecord
+	for vout, output := range tx.Outputs {
+		if output.LockingScript == nil {
+			continue
+		}
+		outputVout := uint32(vout) // #nosec G115 -- outputs are limited by the tx size
+
+		signatures, err := verifyAIPPushes(opReturnPushes(*output.LockingScript), outputVout)
+		if err != nil {
+			return nil, fmt.Errorf("output %d: %w", vout, err)
+		}
+
+		var unsigned []*BAPRecord
+		for _, protocol := range ScriptBitcomProtocols(*output.LockingScript) {
+			switch protocol.Prefix {
+			case BAPPrefix:
+				protocol.Vout = outputVout
+				record, parseErr := ParseBAPRecord(protocol)
+				if parseErr != nil {
+					return nil, fmt.Errorf("output %d: %w", vout, parseErr)
+				}
+				records = append(records, record)
+				unsigned = append(unsigned, record)
+			case AIPPrefix:
+				// Every AIP protocol was verified above, in the same order
+				if len(signatures) == 0 {
+					return nil, fmt.Errorf("output %d: %w: unverified signature", vout, ErrInvalidAIP)
+				}
+				signature := signatures[0]
+				signatures = signatures[1:]
+				if len(signature.Indexes) > 0 {
+					continue
+				}
+				for _, record := range unsigned {
+					record.Signer = signature.Address
+				}
+				unsigned = nil
+			}
+		}
+	}
+	return records, nil
+}
+
+// ValidateBAPIDChain will validate ID records (see GetBAPRecords) in order:
+// the first must be signed by the root address of the identity key, and
+// each rotation by the address published in the previous record
+func ValidateBAPIDChain(records []*BAPRecord) (*BAPIDChain, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: no ID records", ErrInvalidBAPChain)
+	}
+
+	chain := &BAPIDChain{IdentityKey: records[0].IdentityKey, RootAddress: records[0].Signer}
+	if chain.RootAddress == "" || BAPIdentityKey(chain.RootAddress) != chain.IdentityKey {
+		return nil, fmt.Errorf("%w: identity key %s is not signed by its root address", ErrInvalidBAPChain, chain.IdentityKey)
+	}
+
+	signer := chain.RootAddress
+	for i, record := range records {
+		switch {
+		case record.Type != BAPID:
+			return nil, fmt.Errorf("%w: record %d is %s, not ID", ErrInvalidBAPChain, i, record.Type)
+		case record.IdentityKey != chain.IdentityKey:
+			return nil, fmt.Errorf("%w: record %d is for identity key %s", ErrInvalidBAPChain, i, record.IdentityKey)
+		case record.Signer != signer:
+			return nil, fmt.Errorf("%w: record %d is signed by %q, expected %s", ErrInvalidBAPChain, i, record.Signer, signer)
+		}
+		signer = record.Address
+		chain.Addresses = append(chain.Addresses, record.Address)
+	}
+	return chain, nil
+}
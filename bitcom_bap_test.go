@@ -0,0 +1,337 @@
+package bitcoin
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/bsv-blockchain/go-bt/v2"
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mustBAPIdentity derives a BAP identity from testBIP32Master, failing the test on error
+func mustBAPIdentity(t *testing.T, counter uint32) *BAPIdentity {
+	t.Helper()
+	id, err := NewBAPIdentity(mustBIP32Master(t), counter)
+	require.NoError(t, err)
+	return id
+}
+
+// mustBAPTx creates a transaction with OP_RETURN outputs, failing the test on error
+func mustBAPTx(t *testing.T, opReturns ...OpReturnData) *bt.Tx {
+	t.Helper()
+	tx, err := CreateTx(nil, nil, opReturns, nil)
+	require.NoError(t, err)
+	return tx
+}
+
+// TestNewBAPIdentity will test the method NewBAPIdentity()
+func TestNewBAPIdentity(t *testing.T) {
+	t.Parallel()
+
+	t.Run("derivation", func(t *testing.T) {
+		t.Parallel()
+		id := mustBAPIdentity(t, 1)
+		masterKey := mustBIP32Master(t)
+
+		// m/424150'/0'/0'/0/0/n
+		base := masterKey
+		var err error
+		for _, num := range []uint32{bip32.HardenedKeyStart + 424150, bip32.HardenedKeyStart, bip32.HardenedKeyStart, 0, 0} {
+			base, err = GetHDKeyChild(base, num)
+			require.NoError(t, err)
+		}
+		root, err := GetHDKeyChild(base, 0)
+		require.NoError(t, err)
+		expectedRoot, err := GetAddressStringFromHDKey(root, true)
+		require.NoError(t, err)
+		signing, err := GetHDKeyChild(base, 1)
+		require.NoError(t, err)
+		expectedAddress, err := GetAddressStringFromHDKey(signing, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, expectedRoot, id.RootAddress())
+		assert.Equal(t, expectedAddress, id.Address())
+		assert.Equal(t, BAPIdentityKey(expectedRoot), id.IdentityKey())
+		assert.Equal(t, uint32(1), id.Counter())
+
+		privateKey, err := id.PrivateKey()
+		require.NoError(t, err)
+		address, err := GetAddressFromPrivateKey(privateKey, true, true)
+		require.NoError(t, err)
+		assert.Equal(t, expectedAddress, address)
+
+		// The counter picks the signing key, not the identity
+		id5 := mustBAPIdentity(t, 5)
+		assert.Equal(t, id.IdentityKey(), id5.IdentityKey())
+		assert.NotEqual(t, id.Address(), id5.Address())
+	})
+
+	t.Run("testnet", func(t *testing.T) {
+		t.Parallel()
+		id, err := NewBAPIdentity(mustTestnetHDKey(t, mustBIP32Master(t)), 1)
+		require.NoError(t, err)
+		parsed, err := ParseAddress(id.Address())
+		require.NoError(t, err)
+		assert.Equal(t, NetworkTestnet, parsed.Network)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewBAPIdentity(mustBIP32Master(t), 0)
+		require.ErrorIs(t, err, ErrInvalidBAP)
+		_, err = NewBAPIdentity(mustBIP32Master(t), bip32.HardenedKeyStart)
+		require.ErrorIs(t, err, ErrInvalidBAP)
+
+		xPub, err := mustBIP32Master(t).Neuter()
+		require.NoError(t, err)
+		_, err = NewBAPIdentity(xPub, 1)
+		require.ErrorIs(t, err, ErrPrivateKeyMissing)
+	})
+}
+
+// TestBAPIdentityKey will test the method BAPIdentityKey()
+func TestBAPIdentityKey(t *testing.T) {
+	t.Parallel()
+
+	key := BAPIdentityKey(testAddress)
+	assert.NotEmpty(t, key)
+	assert.Equal(t, key, BAPIdentityKey(testAddress))
+	assert.NotEqual(t, key, BAPIdentityKey(testAddress2))
+}
+
+// TestBAPIdentity_Records will test building and parsing BAP records
+func TestBAPIdentity_Records(t *testing.T) {
+	t.Parallel()
+
+	id := mustBAPIdentity(t, 1)
+
+	t.Run("id", func(t *testing.T) {
+		t.Parallel()
+		record, err := id.IDRecord()
+		require.NoError(t, err)
+		assert.Equal(t, OpReturnData{[]byte(BAPPrefix), []byte("ID"), []byte(id.IdentityKey()), []byte(id.Address())}, record[:4])
+
+		records, err := GetBAPRecords(mustBAPTx(t, record))
+		require.NoError(t, err)
+		assert.Equal(t, []*BAPRecord{{
+			Type:        BAPID,
+			IdentityKey: id.IdentityKey(),
+			Address:     id.Address(),
+			Signer:      id.RootAddress(),
+		}}, records)
+	})
+
+	t.Run("attest", func(t *testing.T) {
+		t.Parallel()
+		record, err := id.AttestRecord("attestation-hash", 3)
+		require.NoError(t, err)
+		records, err := GetBAPRecords(mustBAPTx(t, record))
+		require.NoError(t, err)
+		assert.Equal(t, []*BAPRecord{{Type: BAPAttest, AttestationHash: "attestation-hash", Sequence: 3, Signer: id.Address()}}, records)
+	})
+
+	t.Run("alias", func(t *testing.T) {
+		t.Parallel()
+		record, err := id.AliasRecord([]byte(`{"name":"satoshi"}`))
+		require.NoError(t, err)
+		records, err := GetBAPRecords(mustBAPTx(t, record))
+		require.NoError(t, err)
+		assert.Equal(t, []*BAPRecord{{Type: BAPAlias, IdentityKey: id.IdentityKey(), Data: []byte(`{"name":"satoshi"}`), Signer: id.Address()}}, records)
+	})
+
+	t.Run("data", func(t *testing.T) {
+		t.Parallel()
+		record, err := id.DataRecord("attestation-hash", []byte("encrypted"))
+		require.NoError(t, err)
+		records, err := GetBAPRecords(mustBAPTx(t, mustAIPData(t), record))
+		require.NoError(t, err)
+		assert.Equal(t, []*BAPRecord{{Vout: 1, Type: BAPData, AttestationHash: "attestation-hash", Data: []byte("encrypted"), Signer: id.Address()}}, records)
+	})
+
+	t.Run("build errors", func(t *testing.T) {
+		t.Parallel()
+		_, err := id.AttestRecord("", 0)
+		require.ErrorIs(t, err, ErrInvalidBAP)
+		_, err = id.AliasRecord(nil)
+		require.ErrorIs(t, err, ErrInvalidBAP)
+		_, err = id.DataRecord("", []byte("data"))
+		require.ErrorIs(t, err, ErrInvalidBAP)
+	})
+
+	t.Run("unsigned and partially signed records", func(t *testing.T) {
+		t.Parallel()
+		record := OpReturnData{[]byte(BAPPrefix), []byte("ATTEST"), []byte("hash"), []byte("0")}
+		privateKey, err := id.PrivateKey()
+		require.NoError(t, err)
+		partial, err := SignAIP(record, privateKey, true, 0)
+		require.NoError(t, err)
+
+		records, err := GetBAPRecords(mustBAPTx(t, record, partial))
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Empty(t, records[0].Signer)
+		assert.Empty(t, records[1].Signer)
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		t.Parallel()
+		record, err := id.AttestRecord("attestation-hash", 0)
+		require.NoError(t, err)
+		record[2] = []byte("tampered")
+		_, err = GetBAPRecords(mustBAPTx(t, record))
+		require.ErrorIs(t, err, ErrAddressNotFound)
+	})
+
+	t.Run("malformed signature", func(t *testing.T) {
+		t.Parallel()
+		record, err := id.AttestRecord("attestation-hash", 0)
+		require.NoError(t, err)
+		aip := slices.IndexFunc(record, func(push []byte) bool { return string(push) == AIPPrefix })
+		require.Positive(t, aip)
+
+		for _, malformed := range []OpReturnData{
+			record[:aip+1],
+			record[:aip+3],
+			append(slices.Clone(record[:aip+1]), []byte("OTHER"), record[aip+2], record[aip+3]),
+			append(slices.Clone(record[:aip+1]), record[aip+1], record[aip+2], []byte("not base64")),
+			append(slices.Clone(record), []byte("x")),
+		} {
+			_, err = GetBAPRecords(mustBAPTx(t, malformed))
+			require.Error(t, err)
+		}
+	})
+
+	t.Run("malformed record", func(t *testing.T) {
+		t.Parallel()
+		_, err := GetBAPRecords(mustBAPTx(t, OpReturnData{[]byte(BAPPrefix), []byte("ID")}))
+		require.ErrorIs(t, err, ErrInvalidBAP)
+	})
+}
+
+// TestParseBAPRecord will test the method ParseBAPRecord()
+func TestParseBAPRecord(t *testing.T) {
+	t.Parallel()
+
+	protocol := func(args ...string) BitcomProtocol {
+		p := BitcomProtocol{Prefix: BAPPrefix}
+		for _, arg := range args {
+			p.Args = append(p.Args, []byte(arg))
+		}
+		return p
+	}
+
+	tests := []struct {
+		name          string
+		protocol      BitcomProtocol
+		expected      *BAPRecord
+		expectedError error
+	}{
+		{"id", protocol("ID", "key", testAddress), &BAPRecord{Type: BAPID, IdentityKey: "key", Address: testAddress}, nil},
+		{"attest", protocol("ATTEST", "hash", "7"), &BAPRecord{Type: BAPAttest, AttestationHash: "hash", Sequence: 7}, nil},
+		{"alias", protocol("ALIAS", "key", "{}"), &BAPRecord{Type: BAPAlias, IdentityKey: "key", Data: []byte("{}")}, nil},
+		{"data", protocol("DATA", "hash", "x"), &BAPRecord{Type: BAPData, AttestationHash: "hash", Data: []byte("x")}, nil},
+		{"wrong prefix", BitcomProtocol{Prefix: AIPPrefix}, nil, ErrInvalidBAP},
+		{"missing args", protocol("ID", "key"), nil, ErrInvalidBAP},
+		{"invalid sequence", protocol("ATTEST", "hash", "x"), nil, ErrInvalidBAP},
+		{"unknown type", protocol("REVOKE", "hash", "0"), nil, ErrInvalidBAP},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r, err := ParseBAPRecord(test.protocol)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, r)
+		})
+	}
+}
+
+// TestValidateBAPIDChain will test the method ValidateBAPIDChain() with rotations
+func TestValidateBAPIDChain(t *testing.T) {
+	t.Parallel()
+
+	id := mustBAPIdentity(t, 1)
+	var records []*BAPRecord
+	addresses := []string{id.Address()}
+
+	record, err := id.IDRecord()
+	require.NoError(t, err)
+	parsed, err := GetBAPRecords(mustBAPTx(t, record))
+	require.NoError(t, err)
+	records = append(records, parsed...)
+
+	for range 2 {
+		record, err = id.Rotate()
+		require.NoError(t, err)
+		addresses = append(addresses, id.Address())
+		parsed, err = GetBAPRecords(mustBAPTx(t, record))
+		require.NoError(t, err)
+		records = append(records, parsed...)
+	}
+	assert.Equal(t, uint32(3), id.Counter())
+	assert.Equal(t, mustBAPIdentity(t, 3).Address(), id.Address())
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+		chain, err := ValidateBAPIDChain(records)
+		require.NoError(t, err)
+		assert.Equal(t, &BAPIDChain{IdentityKey: id.IdentityKey(), RootAddress: id.RootAddress(), Addresses: addresses}, chain)
+	})
+
+	t.Run("broken", func(t *testing.T) {
+		t.Parallel()
+		tests := []struct {
+			name    string
+			records []*BAPRecord
+		}{
+			{caseEmpty, nil},
+			{"skipped rotation", []*BAPRecord{records[0], records[2]}},
+			{"not starting at the root", records[1:]},
+			{"wrong type", []*BAPRecord{records[0], {Type: BAPAttest, Signer: records[0].Address}}},
+			{"other identity", []*BAPRecord{records[0], {Type: BAPID, IdentityKey: "other", Signer: records[0].Address}}},
+			{"unsigned", []*BAPRecord{{Type: BAPID, IdentityKey: id.IdentityKey(), Address: id.Address()}}},
+		}
+		for _, test := range tests {
+			_, err := ValidateBAPIDChain(test.records)
+			require.ErrorIs(t, err, ErrInvalidBAPChain, test.name)
+		}
+	})
+}
+
+// ExampleNewBAPIdentity example using NewBAPIdentity()
+func ExampleNewBAPIdentity() {
+	hdKey, err := GenerateHDKeyFromString("xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi")
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	var id *BAPIdentity
+	if id, err = NewBAPIdentity(hdKey, 1); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	var record OpReturnData
+	if record, err = id.Rotate(); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("rotated to key %d: %s", id.Counter(), record[1])
+	// Output:rotated to key 2: ID
+}
+
+// BenchmarkNewBAPIdentity benchmarks the method NewBAPIdentity()
+func BenchmarkNewBAPIdentity(b *testing.B) {
+	hdKey, _ := GenerateHDKeyFromString(testBIP32Master)
+	for b.Loop() {
+		_, _ = NewBAPIdentity(hdKey, 1)
+	}
+}
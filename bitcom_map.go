@@ -0,0 +1,150 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bsv-blockchain/go-bt/v2"
+)
+
+// MAPPrefix is the MAP (Magic Attribute Protocol) prefix
+const MAPPrefix = "1PuQa7K62MiKCtssSLKy1kh56WWU7MtUR5"
+
+// ErrInvalidMAP is returned when a MAP command or its keys and values are invalid
+var ErrInvalidMAP = errors.New("invalid MAP data")
+
+// MAPCommand is a MAP command
+type MAPCommand string
+
+// MAP commands
+const (
+	MAPSet    MAPCommand = "SET"
+	MAPAdd    MAPCommand = "ADD"
+	MAPDelete MAPCommand = "DELETE"
+	MAPRemove MAPCommand = "REMOVE"
+)
+
+// MAPData is a MAP (Magic Attribute Protocol) command
+//
+// SET and DELETE use Data (key/value pairs), ADD uses Key and Values and
+// REMOVE uses Keys. Spec: https://map.sv
+type MAPData struct {
+	Command MAPCommand
+	Data    map[string]string
+	Key     string
+	Values  []string
+	Keys    []string
+}
+
+// NewMAPSet will build a MAP SET command from key/value pairs
+// (key1, value1, key2, value2, ...)
+func NewMAPSet(keyValues ...string) (OpReturnData, error) {
+	return newMAPPairs(MAPSet, keyValues)
+}
+
+// NewMAPAdd will build a MAP ADD command adding values to the list in key
+func NewMAPAdd(key string, values ...string) (OpReturnData, error) {
+	if key == "" {
+		return nil, fmt.Errorf("%w: missing key", ErrInvalidMAP)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w: missing values", ErrInvalidMAP)
+	}
+	data := OpReturnData{[]byte(MAPPrefix), []byte(MAPAdd), []byte(key)}
+	for _, value := range values {
+		data = append(data, []byte(value))
+	}
+	return data, nil
+}
+
+// NewMAPDelete will build a MAP DELETE command deleting key/value pairs
+// (key1, value1, key2, value2, ...)
+func NewMAPDelete(keyValues ...string) (OpReturnData, error) {
+	return newMAPPairs(MAPDelete, keyValues)
+}
+
+// NewMAPRemove will build a MAP REMOVE command removing keys
+func NewMAPRemove(keys ...string) (OpReturnData, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: missing keys", ErrInvalidMAP)
+	}
+	data := OpReturnData{[]byte(MAPPrefix), []byte(MAPRemove)}
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("%w: empty key", ErrInvalidMAP)
+		}
+		data = append(data, []byte(key))
+	}
+	return data, nil
+}
+
+// newMAPPairs builds a MAP command followed by key/value pairs.
+func newMAPPairs(command MAPCommand, keyValues []string) (OpReturnData, error) {
+	if len(keyValues) == 0 || len(keyValues)%2 != 0 {
+		return nil, fmt.Errorf("%w: %s expects key/value pairs, got %d args", ErrInvalidMAP, command, len(keyValues))
+	}
+	data := OpReturnData{[]byte(MAPPrefix), []byte(command)}
+	for i, keyValue := range keyValues {
+		if i%2 == 0 && keyValue == "" {
+			return nil, fmt.Errorf("%w: empty key", ErrInvalidMAP)
+		}
+		data = append(data, []byte(keyValue))
+	}
+	return data, nil
+}
+
+// ParseMAPData will parse a MAP protocol from a pipeline (see GetBitcomProtocols)
+func ParseMAPData(protocol BitcomProtocol) (*MAPData, error) {
+	if protocol.Prefix != MAPPrefix {
+		return nil, fmt.Errorf("%w: prefix is %q", ErrInvalidMAP, protocol.Prefix)
+	}
+	if len(protocol.Args) < 2 {
+		return nil, fmt.Errorf("%w: expected a command and args, got %d args", ErrInvalidMAP, len(protocol.Args))
+	}
+
+	m := &MAPData{Command: MAPCommand(protocol.Args[0])}
+	args := protocol.Args[1:]
+	switch m.Command {
+	case MAPSet, MAPDelete:
+		if len(args)%2 != 0 {
+			return nil, fmt.Errorf("%w: %s expects key/value pairs, got %d args", ErrInvalidMAP, m.Command, len(args))
+		}
+		m.Data = make(map[string]string, len(args)/2)
+		for i := 0; i < len(args); i += 2 {
+			m.Data[string(args[i])] = string(args[i+1])
+		}
+	case MAPAdd:
+		if len(args) < 2 {
+			return nil, fmt.Errorf("%w: ADD expects a key and values", ErrInvalidMAP)
+		}
+		m.Key = string(args[0])
+		for _, value := range args[1:] {
+			m.Values = append(m.Values, string(value))
+		}
+	case MAPRemove:
+		for _, key := range args {
+			m.Keys = append(m.Keys, string(key))
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown command %q", ErrInvalidMAP, m.Command)
+	}
+	return m, nil
+}
+
+// GetMAPData will get the valid MAP commands from every output of a
+// transaction, including MAP protocols in multi-protocol pipelines
+//
+// Use GetBitcomProtocols and ParseMAPData to get the output index (Vout) or parse errors.
+// Expects tx to not be nil (otherwise will panic)
+func GetMAPData(tx *bt.Tx) []*MAPData {
+	var commands []*MAPData
+	for _, protocol := range GetBitcomProtocols(tx) {
+		if protocol.Prefix != MAPPrefix {
+			continue
+		}
+		if m, err := ParseMAPData(protocol); err == nil {
+			commands = append(commands, m)
+		}
+	}
+	return commands
+}
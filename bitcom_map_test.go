@@ -0,0 +1,159 @@
+package bitcoin
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMAPBuilders will test the MAP command builders
+func TestMAPBuilders(t *testing.T) {
+	t.Parallel()
+
+	pushes := func(args ...string) OpReturnData {
+		data := OpReturnData{[]byte(MAPPrefix)}
+		for _, arg := range args {
+			data = append(data, []byte(arg))
+		}
+		return data
+	}
+
+	tests := []struct {
+		name          string
+		build         func() (OpReturnData, error)
+		expected      OpReturnData
+		expectedError error
+	}{
+		{"set", func() (OpReturnData, error) { return NewMAPSet("app", "myapp", "type", "post") }, pushes("SET", "app", "myapp", "type", "post"), nil},
+		{"set empty value", func() (OpReturnData, error) { return NewMAPSet("app", "") }, pushes("SET", "app", ""), nil},
+		{"set odd args", func() (OpReturnData, error) { return NewMAPSet("app", "myapp", "type") }, nil, ErrInvalidMAP},
+		{"set no args", func() (OpReturnData, error) { return NewMAPSet() }, nil, ErrInvalidMAP},
+		{"set empty key", func() (OpReturnData, error) { return NewMAPSet("", "myapp") }, nil, ErrInvalidMAP},
+		{"add", func() (OpReturnData, error) { return NewMAPAdd("tags", "a", "b") }, pushes("ADD", "tags", "a", "b"), nil},
+		{"add no values", func() (OpReturnData, error) { return NewMAPAdd("tags") }, nil, ErrInvalidMAP},
+		{"add empty key", func() (OpReturnData, error) { return NewMAPAdd("", "a") }, nil, ErrInvalidMAP},
+		{"delete", func() (OpReturnData, error) { return NewMAPDelete("tags", "a") }, pushes("DELETE", "tags", "a"), nil},
+		{"delete odd args", func() (OpReturnData, error) { return NewMAPDelete("tags") }, nil, ErrInvalidMAP},
+		{"remove", func() (OpReturnData, error) { return NewMAPRemove("tags", "app") }, pushes("REMOVE", "tags", "app"), nil},
+		{"remove no keys", func() (OpReturnData, error) { return NewMAPRemove() }, nil, ErrInvalidMAP},
+		{"remove empty key", func() (OpReturnData, error) { return NewMAPRemove("tags", "") }, nil, ErrInvalidMAP},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			data, err := test.build()
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, data)
+		})
+	}
+}
+
+// TestParseMAPData will test the method ParseMAPData()
+func TestParseMAPData(t *testing.T) {
+	t.Parallel()
+
+	protocol := func(args ...string) BitcomProtocol {
+		p := BitcomProtocol{Prefix: MAPPrefix}
+		for _, arg := range args {
+			p.Args = append(p.Args, []byte(arg))
+		}
+		return p
+	}
+
+	tests := []struct {
+		name          string
+		protocol      BitcomProtocol
+		expected      *MAPData
+		expectedError error
+	}{
+		{"set", protocol("SET", "app", "myapp", "type", "post"), &MAPData{Command: MAPSet, Data: map[string]string{"app": "myapp", "type": "post"}}, nil},
+		{"delete", protocol("DELETE", "tags", "a"), &MAPData{Command: MAPDelete, Data: map[string]string{"tags": "a"}}, nil},
+		{"add", protocol("ADD", "tags", "a", "b"), &MAPData{Command: MAPAdd, Key: "tags", Values: []string{"a", "b"}}, nil},
+		{"remove", protocol("REMOVE", "tags", "app"), &MAPData{Command: MAPRemove, Keys: []string{"tags", "app"}}, nil},
+		{"wrong prefix", BitcomProtocol{Prefix: BPrefix, Args: [][]byte{[]byte("SET"), []byte("a"), []byte("b")}}, nil, ErrInvalidMAP},
+		{"missing args", protocol("SET"), nil, ErrInvalidMAP},
+		{"set odd args", protocol("SET", "app", "myapp", "type"), nil, ErrInvalidMAP},
+		{"add missing values", protocol("ADD", "tags"), nil, ErrInvalidMAP},
+		{"unknown command", protocol("SELECT", "abc"), nil, ErrInvalidMAP},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			m, err := ParseMAPData(test.protocol)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, m)
+		})
+	}
+}
+
+// TestGetMAPData will test the method GetMAPData() with a B:// | MAP pipeline from CreateTx()
+func TestGetMAPData(t *testing.T) {
+	t.Parallel()
+
+	b, err := (&BData{Data: []byte("hello"), MediaType: "text/plain"}).OpReturnData()
+	require.NoError(t, err)
+	set, err := NewMAPSet("app", "myapp", "type", "post")
+	require.NoError(t, err)
+	add, err := NewMAPAdd("tags", "a", "b")
+	require.NoError(t, err)
+
+	tx, err := CreateTx(
+		[]*Utxo{newTestUtxo(1000)},
+		[]*PayToAddress{{Address: testAddress, Satoshis: 500}},
+		[]OpReturnData{JoinBitcomProtocols(b, set, add), {[]byte(MAPPrefix), []byte("SET"), []byte("odd")}},
+		mustTestPrivKey(t),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []*MAPData{
+		{Command: MAPSet, Data: map[string]string{"app": "myapp", "type": "post"}},
+		{Command: MAPAdd, Key: "tags", Values: []string{"a", "b"}},
+	}, GetMAPData(tx))
+	assert.Len(t, GetBData(tx), 1)
+}
+
+// ExampleNewMAPSet example using NewMAPSet()
+func ExampleNewMAPSet() {
+	data, err := NewMAPSet("app", "myapp", "type", "post")
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("pushes: %q", data)
+	// Output:pushes: ["1PuQa7K62MiKCtssSLKy1kh56WWU7MtUR5" "SET" "app" "myapp" "type" "post"]
+}
+
+// ExampleGetMAPData example using GetMAPData()
+func ExampleGetMAPData() {
+	data, _ := NewMAPSet("app", "myapp")
+	tx, err := CreateTx(nil, nil, []OpReturnData{data}, nil)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	for _, m := range GetMAPData(tx) {
+		fmt.Printf("%s app=%s", m.Command, m.Data["app"])
+	}
+	// Output:SET app=myapp
+}
+
+// BenchmarkGetMAPData benchmarks the method GetMAPData()
+func BenchmarkGetMAPData(b *testing.B) {
+	data, _ := NewMAPSet("app", "myapp", "type", "post")
+	tx, _ := CreateTx(nil, nil, []OpReturnData{data}, nil)
+	for b.Loop() {
+		_ = GetMAPData(tx)
+	}
+}
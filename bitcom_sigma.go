@@ -0,0 +1,227 @@
+package bitcoin
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/bsv-blockchain/go-bt/v2"
+	"github.com/bsv-blockchain/go-bt/v2/bscript"
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	hash "github.com/bsv-blockchain/go-sdk/primitives/hash"
+	script "github.com/bsv-blockchain/go-sdk/script"
+)
+
+// SigmaPrefix is the Sigma protocol prefix
+const SigmaPrefix = "SIGMA"
+
+// SigmaAlgorithmBSM is the Sigma algorithm for Bitcoin Signed Message signatures
+const SigmaAlgorithmBSM = "BSM"
+
+// ErrInvalidSigma is returned when a Sigma signature or its input or output is invalid
+var ErrInvalidSigma = errors.New("invalid Sigma data")
+
+// SigmaSignature is a Sigma signature in a transaction output
+//
+// The signature covers sha256(sha256(outpoint of input Vin) + sha256(output
+// script before the signature)), so it cannot be replayed in another
+// transaction. Spec: https://github.com/BitcoinSchema/sigma
+type SigmaSignature struct {
+	Vout      uint32
+	Algorithm string
+	Address   string
+	Signature string
+	Vin       uint32
+}
+
+// sigmaInstance is a Sigma protocol in an output and the length of the
+// script it signs (up to the "|" before it).
+type sigmaInstance struct {
+	protocol BitcomProtocol
+	dataEnd  int
+}
+
+// SignSigma will sign the OP_RETURN output vout of a transaction, together
+// with the outpoint of input vin, and append the Sigma protocol to the output
+//
+// Outputs can have several signatures, each one also covers the previous ones.
+// This changes the output, so sign the inputs afterwards: create the tx
+// without a key (CreateTx(..., nil)), add the Sigma signatures, then SignTx.
+// Expects tx to not be nil (otherwise will panic)
+func SignSigma(tx *bt.Tx, vout, vin uint32, privateKey *ec.PrivateKey, mainnet bool) (*SigmaSignature, error) {
+	if privateKey == nil {
+		return nil, ErrPrivateKeyMissing
+	}
+	if int(vout) >= len(tx.Outputs) || tx.Outputs[vout].LockingScript == nil {
+		return nil, fmt.Errorf("%w: output %d not found", ErrInvalidSigma, vout)
+	}
+
+	lockingScript := *tx.Outputs[vout].LockingScript
+	if _, hasData := sigmaInstances(lockingScript); !hasData {
+		return nil, fmt.Errorf("%w: output %d has no OP_RETURN data", ErrInvalidSigma, vout)
+	}
+
+	message, err := sigmaMessage(tx, vin, lockingScript)
+	if err != nil {
+		return nil, err
+	}
+
+	var sigBytes []byte
+	if sigBytes, err = bsm.SignMessageWithCompression(privateKey, message, true); err != nil {
+		return nil, err
+	}
+
+	var address string
+	if address, err = GetAddressFromPrivateKey(privateKey, true, mainnet); err != nil {
+		return nil, err
+	}
+
+	signature := &SigmaSignature{
+		Vout:      vout,
+		Algorithm: SigmaAlgorithmBSM,
+		Address:   address,
+		Signature: base64.StdEncoding.EncodeToString(sigBytes),
+		Vin:       vin,
+	}
+
+	var signed []byte
+	if signed, err = NewScriptBuilder().
+		AddScript(lockingScript).
+		AddString(BitcomSeparator).
+		AddString(SigmaPrefix).
+		AddString(signature.Algorithm).
+		AddString(signature.Address).
+		AddString(signature.Signature).
+		AddString(strconv.FormatUint(uint64(vin), 10)).
+		Script(); err != nil {
+		return nil, err
+	}
+	tx.Outputs[vout].LockingScript = bscript.NewFromBytes(signed)
+	return signature, nil
+}
+
+// ParseSigmaSignature will parse a Sigma protocol from a pipeline (see GetBitcomProtocols)
+//
+// This does not verify the signature (see VerifySigma).
+func ParseSigmaSignature(protocol BitcomProtocol) (*SigmaSignature, error) {
+	if protocol.Prefix != SigmaPrefix {
+		return nil, fmt.Errorf("%w: prefix is %q", ErrInvalidSigma, protocol.Prefix)
+	}
+	if len(protocol.Args) < 4 {
+		return nil, fmt.Errorf("%w: expected algorithm, address, signature and vin, got %d args", ErrInvalidSigma, len(protocol.Args))
+	}
+
+	s := &SigmaSignature{
+		Vout:      protocol.Vout,
+		Algorithm: string(protocol.Args[0]),
+		Address:   string(protocol.Args[1]),
+		Signature: string(protocol.Args[2]),
+	}
+	if s.Algorithm != SigmaAlgorithmBSM {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidSigma, s.Algorithm)
+	}
+	vin, err := strconv.ParseUint(string(protocol.Args[3]), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid vin %q", ErrInvalidSigma, protocol.Args[3])
+	}
+	s.Vin = uint32(vin) // #nosec G115 -- parsed as 32 bits
+	return s, nil
+}
+
+// VerifySigma will verify every Sigma signature in the outputs of a
+// transaction against the input it references and its declared address
+//
+// Returns the verified signatures, or the first verification error.
+// Expects tx to not be nil (otherwise will panic)
+func VerifySigma(tx *bt.Tx) ([]*SigmaSignature, error) {
+	var verified []*SigmaSignature
+	for vout, output := range tx.Outputs {
+		if output.LockingScript == nil {
+			continue
+		}
+		instances, _ := sigmaInstances(*output.LockingScript)
+		for _, instance := range instances {
+			instance.protocol.Vout = uint32(vout) // #nosec G115 -- outputs are limited by the tx size
+			s, err := verifySigmaInstance(tx, *output.LockingScript, instance)
+			if err != nil {
+				return nil, fmt.Errorf("output %d: %w", vout, err)
+			}
+			verified = append(verified, s)
+		}
+	}
+	return verified, nil
+}
+
+// verifySigmaInstance parses and verifies one Sigma signature.
+func verifySigmaInstance(tx *bt.Tx, lockingScript []byte, instance sigmaInstance) (*SigmaSignature, error) {
+	s, err := ParseSigmaSignature(instance.protocol)
+	if err != nil {
+		return nil, err
+	}
+
+	var message []byte
+	if message, err = sigmaMessage(tx, s.Vin, lockingScript[:instance.dataEnd]); err != nil {
+		return nil, err
+	}
+
+	var parsed *ParsedAddress
+	if parsed, err = ParseAddress(s.Address); err != nil {
+		return nil, err
+	}
+	if err = VerifyMessage(s.Address, s.Signature, string(message), parsed.Network.IsMainnet()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// sigmaMessage returns the signed message: sha256(sha256(outpoint) + sha256(data)).
+func sigmaMessage(tx *bt.Tx, vin uint32, data []byte) ([]byte, error) {
+	if int(vin) >= len(tx.Inputs) || tx.Inputs[vin].PreviousTxIDChainHash() == nil {
+		return nil, fmt.Errorf("%w: input %d not found", ErrInvalidSigma, vin)
+	}
+	input := tx.Inputs[vin]
+	outpoint := binary.LittleEndian.AppendUint32(input.PreviousTxID(), input.PreviousTxOutIndex)
+
+	return hash.Sha256(append(hash.Sha256(outpoint), hash.Sha256(data)...)), nil
+}
+
+// sigmaInstances finds the Sigma protocols after the first OP_RETURN of a
+// script, and reports whether it has an OP_RETURN.
+func sigmaInstances(lockingScript []byte) ([]sigmaInstance, bool) {
+	s := script.NewFromBytes(lockingScript)
+
+	var instances []sigmaInstance
+	var current *sigmaInstance
+	opReturn := false
+	separator, previousSeparator := -1, -1
+	for pos := 0; pos < len(lockingScript); {
+		start := pos
+		chunk, err := s.ReadOp(&pos)
+		if err != nil {
+			break
+		}
+		if !opReturn {
+			opReturn = chunk.Op == script.OpRETURN
+			continue
+		}
+
+		previousSeparator, separator = separator, -1
+		isPush := chunk.Op <= script.OpPUSHDATA4
+		switch {
+		case isPush && string(chunk.Data) == BitcomSeparator:
+			separator, current = start, nil
+		case isPush && previousSeparator >= 0 && string(chunk.Data) == SigmaPrefix:
+			instances = append(instances, sigmaInstance{
+				protocol: BitcomProtocol{Prefix: SigmaPrefix},
+				dataEnd:  previousSeparator,
+			})
+			current = &instances[len(instances)-1]
+		case isPush && current != nil:
+			current.protocol.Args = append(current.protocol.Args, chunk.Data)
+		}
+	}
+	return instances, opReturn
+}
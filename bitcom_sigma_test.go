@@ -0,0 +1,278 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/bsv-blockchain/go-bt/v2"
+	"github.com/bsv-blockchain/go-bt/v2/bscript"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mustSigmaTx creates an unsigned tx with 2 inputs, a payment and a B:// | MAP output (vout 1)
+func mustSigmaTx(t *testing.T) *bt.Tx {
+	t.Helper()
+	second := newTestUtxo(1000)
+	second.Vout = 1
+	tx, err := CreateTx(
+		[]*Utxo{newTestUtxo(1000), second},
+		[]*PayToAddress{{Address: testAddress, Satoshis: 500}},
+		[]OpReturnData{mustAIPData(t)},
+		nil,
+	)
+	require.NoError(t, err)
+	return tx
+}
+
+// TestSignSigma will test the methods SignSigma() and VerifySigma()
+func TestSignSigma(t *testing.T) {
+	t.Parallel()
+
+	privateKey := mustTestPrivKey(t)
+	address, err := GetAddressFromPrivateKey(privateKey, true, true)
+	require.NoError(t, err)
+
+	t.Run("sign and verify", func(t *testing.T) {
+		t.Parallel()
+		tx := mustSigmaTx(t)
+		data := append([]byte{}, *tx.Outputs[1].LockingScript...)
+
+		signature, err := SignSigma(tx, 1, 0, privateKey, true)
+		require.NoError(t, err)
+		assert.Equal(t, address, signature.Address)
+		assert.Equal(t, data, []byte(*tx.Outputs[1].LockingScript)[:len(data)])
+
+		verified, err := VerifySigma(tx)
+		require.NoError(t, err)
+		assert.Equal(t, []*SigmaSignature{signature}, verified)
+
+		// The Sigma protocol is part of the pipeline, after the other protocols
+		protocols := GetBitcomProtocols(tx)
+		require.Len(t, protocols, 3)
+		parsed, err := ParseSigmaSignature(protocols[2])
+		require.NoError(t, err)
+		assert.Equal(t, signature, parsed)
+		assert.Len(t, GetBData(tx), 1)
+		assert.Len(t, GetMAPData(tx), 1)
+	})
+
+	t.Run("multiple signatures and inputs", func(t *testing.T) {
+		t.Parallel()
+		tx := mustSigmaTx(t)
+		other, err := PrivateKeyFromString(testPrivateKeyHex)
+		require.NoError(t, err)
+
+		first, err := SignSigma(tx, 1, 0, privateKey, true)
+		require.NoError(t, err)
+		second, err := SignSigma(tx, 1, 1, other, false)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(1), second.Vin)
+
+		verified, err := VerifySigma(tx)
+		require.NoError(t, err)
+		assert.Equal(t, []*SigmaSignature{first, second}, verified)
+	})
+
+	t.Run("sign inputs afterwards", func(t *testing.T) {
+		t.Parallel()
+		tx := mustSigmaTx(t)
+		_, err := SignSigma(tx, 1, 0, privateKey, true)
+		require.NoError(t, err)
+		require.NoError(t, SignTx(tx, privateKey))
+		assert.NotEmpty(t, tx.Inputs[0].UnlockingScript)
+
+		// Input signatures do not change the Sigma signature
+		parsed, err := TxFromHex(tx.String())
+		require.NoError(t, err)
+		_, err = VerifySigma(parsed)
+		require.NoError(t, err)
+		require.ErrorIs(t, SignTx(tx, nil), ErrPrivateKeyMissing)
+	})
+
+	t.Run("replay in another tx", func(t *testing.T) {
+		t.Parallel()
+		tx := mustSigmaTx(t)
+		_, err := SignSigma(tx, 1, 0, privateKey, true)
+		require.NoError(t, err)
+
+		// Same output, spending another outpoint
+		replay := mustSigmaTx(t)
+		replay.Outputs[1].LockingScript = tx.Outputs[1].LockingScript
+		replay.Inputs[0].PreviousTxOutIndex = 5
+		_, err = VerifySigma(replay)
+		require.ErrorIs(t, err, ErrAddressNotFound)
+	})
+
+	t.Run("tampered data", func(t *testing.T) {
+		t.Parallel()
+		tx := mustSigmaTx(t)
+		_, err := SignSigma(tx, 1, 0, privateKey, true)
+		require.NoError(t, err)
+		(*tx.Outputs[1].LockingScript)[3] ^= 0xff
+		_, err = VerifySigma(tx)
+		require.ErrorIs(t, err, ErrAddressNotFound)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		tx := mustSigmaTx(t)
+		_, err := SignSigma(tx, 1, 0, nil, true)
+		require.ErrorIs(t, err, ErrPrivateKeyMissing)
+		_, err = SignSigma(tx, 2, 0, privateKey, true)
+		require.ErrorIs(t, err, ErrInvalidSigma)
+		_, err = SignSigma(tx, 0, 0, privateKey, true)
+		require.ErrorIs(t, err, ErrInvalidSigma)
+		_, err = SignSigma(tx, 1, 2, privateKey, true)
+		require.ErrorIs(t, err, ErrInvalidSigma)
+
+		// The input referenced by a signature must exist
+		_, err = SignSigma(tx, 1, 1, privateKey, true)
+		require.NoError(t, err)
+		tx.Inputs = tx.Inputs[:1]
+		_, err = VerifySigma(tx)
+		require.ErrorIs(t, err, ErrInvalidSigma)
+	})
+
+	t.Run("no signatures", func(t *testing.T) {
+		t.Parallel()
+		verified, err := VerifySigma(mustSigmaTx(t))
+		require.NoError(t, err)
+		assert.Empty(t, verified)
+	})
+}
+
+// TestSigmaMessageVector will test the signed message and signature against a
+// known-answer vector built as the Sigma reference library (sigma-protocol) does:
+//
+//	inputHash = sha256(txid in wire order || vout as uint32 LE)
+//	dataHash  = sha256(script before the "|")
+//	message   = sha256(inputHash || dataHash), signed with BSM
+//
+// The message was computed independently of this package (Python hashlib).
+func TestSigmaMessageVector(t *testing.T) {
+	t.Parallel()
+
+	const (
+		data      = "006a0568656c6c6f" // OP_FALSE OP_RETURN "hello"
+		message   = "6f396969a197673ceef14225f58babb26b0a72116f9c1275c818f3288e26a3cc"
+		signature = "IAVazKaZMPXjvuAO06UgufcjFcKdGqcdxKgUhYE6EFujXcq1W320eJuMDPgdnDyxb/A+SRS7Fxf4DXE3+bTkKaQ="
+	)
+
+	// Spends output 1 of testTxID (displayed big-endian, hashed in wire order)
+	tx := bt.NewTx()
+	require.NoError(t, tx.From(testTxID, 1, testScriptPubKey, 1000))
+	lockingScript, err := bscript.NewFromHexString(data)
+	require.NoError(t, err)
+	tx.AddOutput(&bt.Output{LockingScript: lockingScript})
+
+	raw, err := hex.DecodeString(data)
+	require.NoError(t, err)
+	m, err := sigmaMessage(tx, 0, raw)
+	require.NoError(t, err)
+	assert.Equal(t, message, hex.EncodeToString(m))
+
+	privateKey, err := PrivateKeyFromString(testPrivateKeyHex)
+	require.NoError(t, err)
+	s, err := SignSigma(tx, 0, 0, privateKey, true)
+	require.NoError(t, err)
+	assert.Equal(t, signature, s.Signature)
+	require.NoError(t, VerifyMessage("1DfGxKmgL3ETwUdNnXLBueEvNpjcDGcKgK", signature, string(m), true))
+
+	// data | SIGMA BSM <address> <signature> 0
+	expected, err := NewScriptBuilder().AddScript(raw).
+		AddString("|").AddString("SIGMA").AddString("BSM").
+		AddString("1DfGxKmgL3ETwUdNnXLBueEvNpjcDGcKgK").AddString(signature).AddString("0").
+		Script()
+	require.NoError(t, err)
+	assert.Equal(t, expected, []byte(*tx.Outputs[0].LockingScript))
+}
+
+// TestParseSigmaSignature will test the method ParseSigmaSignature()
+func TestParseSigmaSignature(t *testing.T) {
+	t.Parallel()
+
+	protocol := func(args ...string) BitcomProtocol {
+		p := BitcomProtocol{Vout: 2, Prefix: SigmaPrefix}
+		for _, arg := range args {
+			p.Args = append(p.Args, []byte(arg))
+		}
+		return p
+	}
+
+	tests := []struct {
+		name          string
+		protocol      BitcomProtocol
+		expected      *SigmaSignature
+		expectedError error
+	}{
+		{"valid", protocol("BSM", testAddress, "sig", "1"), &SigmaSignature{Vout: 2, Algorithm: "BSM", Address: testAddress, Signature: "sig", Vin: 1}, nil},
+		{"wrong prefix", BitcomProtocol{Prefix: AIPPrefix}, nil, ErrInvalidSigma},
+		{"missing vin", protocol("BSM", testAddress, "sig"), nil, ErrInvalidSigma},
+		{"unsupported algorithm", protocol("OTHER", testAddress, "sig", "0"), nil, ErrInvalidSigma},
+		{"invalid vin", protocol("BSM", testAddress, "sig", "x"), nil, ErrInvalidSigma},
+		{"vin out of range", protocol("BSM", testAddress, "sig", "4294967296"), nil, ErrInvalidSigma},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			s, err := ParseSigmaSignature(test.protocol)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, s)
+		})
+	}
+}
+
+// ExampleSignSigma example using SignSigma()
+func ExampleSignSigma() {
+	privateKey, err := PrivateKeyFromString(testPrivateKeyHex)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	data, _ := NewMAPSet("app", "myapp")
+
+	// Create the tx without signing the inputs, sign the data, then the inputs
+	var tx *bt.Tx
+	if tx, err = CreateTx([]*Utxo{{
+		TxID:         "b7b0650a7c3a1bd4716369783876348b59f5404784970192cec1996e86950576",
+		ScriptPubKey: "76a9149cbe9f5e72fa286ac8a38052d1d5337aa363ea7f88ac",
+		Satoshis:     1000,
+	}}, nil, []OpReturnData{data}, nil); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	if _, err = SignSigma(tx, 0, 0, privateKey, true); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	var verified []*SigmaSignature
+	if verified, err = VerifySigma(tx); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("signed by: %s (input %d)", verified[0].Address, verified[0].Vin)
+	// Output:signed by: 1DfGxKmgL3ETwUdNnXLBueEvNpjcDGcKgK (input 0)
+}
+
+// BenchmarkVerifySigma benchmarks the method VerifySigma()
+func BenchmarkVerifySigma(b *testing.B) {
+	privateKey, _ := PrivateKeyFromString(testPrivateKeyHex)
+	data, _ := NewMAPSet("app", "myapp")
+	tx, _ := CreateTx([]*Utxo{{
+		TxID:         "b7b0650a7c3a1bd4716369783876348b59f5404784970192cec1996e86950576",
+		ScriptPubKey: "76a9149cbe9f5e72fa286ac8a38052d1d5337aa363ea7f88ac",
+		Satoshis:     1000,
+	}}, nil, []OpReturnData{data}, nil)
+	_, _ = SignSigma(tx, 0, 0, privateKey, true)
+	for b.Loop() {
+		_, _ = VerifySigma(tx)
+	}
+}
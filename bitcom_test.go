@@ -0,0 +1,210 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/bsv-blockchain/go-bt/v2"
+	"github.com/bsv-blockchain/go-bt/v2/bscript"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestScriptBitcomProtocols will test the method ScriptBitcomProtocols()
+func TestScriptBitcomProtocols(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		script   string
+		expected []BitcomProtocol
+	}{
+		{caseEmpty, "", nil},
+		{"p2pkh", testScriptPubKey, nil},
+		{"op_return only", "006a", nil},
+		{
+			"single protocol",
+			"006a0161016201630164",
+			[]BitcomProtocol{{Prefix: "a", Args: [][]byte{[]byte("b"), []byte("c"), []byte("d")}}},
+		},
+		{
+			"pipeline",
+			"006a01610162017c0163017c0164",
+			[]BitcomProtocol{
+				{Prefix: "a", Args: [][]byte{[]byte("b")}},
+				{Prefix: "c"},
+				{Prefix: "d"},
+			},
+		},
+		{
+			"empty and small int pushes",
+			"6a0161005f4f",
+			[]BitcomProtocol{{Prefix: "a", Args: [][]byte{{}, {0x0f}, {0x81}}}},
+		},
+		{
+			"after a locking script",
+			testScriptPubKey + "6a0161",
+			[]BitcomProtocol{{Prefix: "a"}},
+		},
+		{
+			"leading and trailing separators",
+			"006a017c0161017c",
+			[]BitcomProtocol{{Prefix: "a"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			raw, err := hex.DecodeString(test.script)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, ScriptBitcomProtocols(raw))
+		})
+	}
+}
+
+// TestJoinBitcomProtocols will test the method JoinBitcomProtocols()
+func TestJoinBitcomProtocols(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, JoinBitcomProtocols())
+	assert.Equal(t,
+		OpReturnData{[]byte("a"), []byte("b")},
+		JoinBitcomProtocols(OpReturnData{[]byte("a"), []byte("b")}),
+	)
+	assert.Equal(t,
+		OpReturnData{[]byte("a"), []byte("b"), []byte("|"), []byte("c")},
+		JoinBitcomProtocols(OpReturnData{[]byte("a"), []byte("b")}, OpReturnData{[]byte("c")}),
+	)
+}
+
+// TestGetBitcomProtocols will test the method GetBitcomProtocols()
+func TestGetBitcomProtocols(t *testing.T) {
+	t.Parallel()
+
+	tx := bt.NewTx()
+	require.NoError(t, tx.PayToAddress(testAddress, 500))
+	require.NoError(t, tx.AddOpReturnPartsOutput([][]byte{[]byte("a"), []byte("|"), []byte("b")}))
+	tx.AddOutput(&bt.Output{})
+	require.NoError(t, tx.AddOpReturnPartsOutput([][]byte{[]byte("c")}))
+
+	assert.Equal(t, []BitcomProtocol{
+		{Vout: 1, Prefix: "a"},
+		{Vout: 1, Prefix: "b"},
+		{Vout: 3, Prefix: "c"},
+	}, GetBitcomProtocols(tx))
+}
+
+// TestBData will test building and parsing B:// data
+func TestBData(t *testing.T) {
+	t.Parallel()
+
+	t.Run("build", func(t *testing.T) {
+		t.Parallel()
+		data, err := (&BData{Data: []byte("hello"), MediaType: "text/plain"}).OpReturnData()
+		require.NoError(t, err)
+		assert.Equal(t, OpReturnData{[]byte(BPrefix), []byte("hello"), []byte("text/plain"), []byte("binary")}, data)
+
+		data, err = (&BData{Data: []byte("hello"), MediaType: "text/plain", Encoding: "utf-8", Filename: "hello.txt"}).OpReturnData()
+		require.NoError(t, err)
+		assert.Equal(t, OpReturnData{[]byte(BPrefix), []byte("hello"), []byte("text/plain"), []byte("utf-8"), []byte("hello.txt")}, data)
+	})
+
+	t.Run("build errors", func(t *testing.T) {
+		t.Parallel()
+		_, err := (&BData{MediaType: "text/plain"}).OpReturnData()
+		require.ErrorIs(t, err, ErrInvalidBData)
+		_, err = (&BData{Data: []byte("hello")}).OpReturnData()
+		require.ErrorIs(t, err, ErrInvalidBData)
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		t.Parallel()
+		b, err := ParseBData(BitcomProtocol{Prefix: BPrefix, Args: [][]byte{[]byte("hello"), []byte("text/plain")}})
+		require.NoError(t, err)
+		assert.Equal(t, &BData{Data: []byte("hello"), MediaType: "text/plain"}, b)
+
+		b, err = ParseBData(BitcomProtocol{Prefix: BPrefix, Args: [][]byte{
+			[]byte("hello"), []byte("text/plain"), []byte("utf-8"), []byte("hello.txt"), []byte("extra"),
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, &BData{Data: []byte("hello"), MediaType: "text/plain", Encoding: "utf-8", Filename: "hello.txt"}, b)
+	})
+
+	t.Run("parse errors", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseBData(BitcomProtocol{Prefix: "other", Args: [][]byte{[]byte("hello"), []byte("text/plain")}})
+		require.ErrorIs(t, err, ErrInvalidBData)
+		_, err = ParseBData(BitcomProtocol{Prefix: BPrefix, Args: [][]byte{[]byte("hello")}})
+		require.ErrorIs(t, err, ErrInvalidBData)
+	})
+}
+
+// TestGetBData will test the method GetBData() with transactions from CreateTx()
+func TestGetBData(t *testing.T) {
+	t.Parallel()
+
+	image := &BData{Data: []byte{0x89, 0x50, 0x4e, 0x47}, MediaType: "image/png", Filename: "image.png"}
+	imageData, err := image.OpReturnData()
+	require.NoError(t, err)
+	text := &BData{Data: []byte("hello"), MediaType: "text/plain", Encoding: "utf-8"}
+	textData, err := text.OpReturnData()
+	require.NoError(t, err)
+
+	tx, err := CreateTx(
+		[]*Utxo{newTestUtxo(1000)},
+		[]*PayToAddress{{Address: testAddress, Satoshis: 500}},
+		[]OpReturnData{
+			imageData,
+			JoinBitcomProtocols(OpReturnData{[]byte("other"), []byte("x")}, textData),
+			{[]byte(BPrefix), []byte("no media type")},
+		},
+		mustTestPrivKey(t),
+	)
+	require.NoError(t, err)
+
+	image.Encoding = BEncodingBinary
+	assert.Equal(t, []*BData{image, text}, GetBData(tx))
+
+	// An enriched 1Sat style output: locking script, then OP_RETURN and the pipeline
+	lockingScript, err := hex.DecodeString(testScriptPubKey + "6a22" + hex.EncodeToString([]byte(BPrefix)) + "0568656c6c6f0a746578742f706c61696e")
+	require.NoError(t, err)
+	tx = bt.NewTx()
+	tx.AddOutput(&bt.Output{Satoshis: 1, LockingScript: bscript.NewFromBytes(lockingScript)})
+	assert.Equal(t, []*BData{{Data: []byte("hello"), MediaType: "text/plain"}}, GetBData(tx))
+}
+
+// ExampleBData_OpReturnData example using BData.OpReturnData()
+func ExampleBData_OpReturnData() {
+	data, err := (&BData{Data: []byte("hello"), MediaType: "text/plain", Encoding: "utf-8"}).OpReturnData()
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("pushes: %q", data)
+	// Output:pushes: ["19HxigV4QyBv3tHpQVcUEQyq1pzZVdoAut" "hello" "text/plain" "utf-8"]
+}
+
+// ExampleGetBData example using GetBData()
+func ExampleGetBData() {
+	data, _ := (&BData{Data: []byte("hello"), MediaType: "text/plain"}).OpReturnData()
+	tx, err := CreateTx(nil, nil, []OpReturnData{data}, nil)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	for _, b := range GetBData(tx) {
+		fmt.Printf("%s (%s): %s", b.MediaType, b.Encoding, b.Data)
+	}
+	// Output:text/plain (binary): hello
+}
+
+// BenchmarkGetBData benchmarks the method GetBData()
+func BenchmarkGetBData(b *testing.B) {
+	data, _ := (&BData{Data: []byte("hello"), MediaType: "text/plain"}).OpReturnData()
+	tx, _ := CreateTx(nil, nil, []OpReturnData{data}, nil)
+	for b.Loop() {
+		_ = GetBData(tx)
+	}
+}
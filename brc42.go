@@ -0,0 +1,187 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+)
+
+// This file adds BRC-42 (Type-42) key derivation and BRC-43 invoice numbers.
+//
+// Unlike GenerateSharedKeyPair, which derives one fixed key from ECDH, BRC-42
+// derives a new child key for every invoice number: the HMAC-SHA256 of the
+// invoice number keyed by the ECDH shared secret is added to the key. The
+// sender derives the recipient's child public key, and only the recipient can
+// derive the matching child private key.
+//
+// BRC-43 standardizes invoice numbers as "<security level>-<protocol>-<key id>".
+
+var (
+	// ErrInvalidSecurityLevel is returned when a BRC-43 security level is not 0, 1 or 2
+	ErrInvalidSecurityLevel = errors.New("security level must be 0, 1 or 2")
+
+	// ErrInvalidProtocolName is returned when a BRC-43 protocol name is invalid
+	ErrInvalidProtocolName = errors.New("invalid protocol name")
+
+	// ErrInvalidKeyID is returned when a BRC-43 key ID is invalid
+	ErrInvalidKeyID = errors.New("invalid key id")
+)
+
+const (
+	// protocolNameMinLength is the shortest BRC-43 protocol name
+	protocolNameMinLength = 5
+
+	// protocolNameMaxLength is the longest BRC-43 protocol name
+	protocolNameMaxLength = 400
+
+	// keyIDMaxLength is the longest BRC-43 key ID
+	keyIDMaxLength = 800
+)
+
+// SecurityLevel is the BRC-43 security level of a protocol
+type SecurityLevel uint8
+
+const (
+	// SecurityLevelSilent keys can be used without asking the user
+	SecurityLevelSilent SecurityLevel = iota
+
+	// SecurityLevelEveryApp keys require permission once per application
+	SecurityLevelEveryApp
+
+	// SecurityLevelEveryAppAndCounterparty keys require permission per application
+	// and counterparty
+	SecurityLevelEveryAppAndCounterparty
+)
+
+// Protocol is a BRC-43 protocol (security level and name)
+type Protocol struct {
+	SecurityLevel SecurityLevel
+	Name          string
+}
+
+// InvoiceNumber will validate the protocol and key ID and return the BRC-43
+// invoice number "<security level>-<protocol name>-<key id>"
+//
+// The protocol name is trimmed and lower-cased before it is validated
+func (p Protocol) InvoiceNumber(keyID string) (string, error) {
+	if p.SecurityLevel > SecurityLevelEveryAppAndCounterparty {
+		return "", ErrInvalidSecurityLevel
+	}
+
+	// Key ID
+	if len(keyID) == 0 || len(keyID) > keyIDMaxLength {
+		return "", fmt.Errorf("%w: must be 1 to %d characters", ErrInvalidKeyID, keyIDMaxLength)
+	}
+
+	// Protocol name
+	name := strings.ToLower(strings.TrimSpace(p.Name))
+	switch {
+	case len(name) < protocolNameMinLength || len(name) > protocolNameMaxLength:
+		return "", fmt.Errorf("%w: must be %d to %d characters",
+			ErrInvalidProtocolName, protocolNameMinLength, protocolNameMaxLength)
+	case strings.Contains(name, "  "):
+		return "", fmt.Errorf("%w: cannot contain consecutive spaces", ErrInvalidProtocolName)
+	case strings.IndexFunc(name, isNotProtocolNameRune) >= 0:
+		return "", fmt.Errorf("%w: can only contain letters, numbers and spaces", ErrInvalidProtocolName)
+	case strings.HasSuffix(name, " protocol"):
+		return "", fmt.Errorf("%w: cannot end with \" protocol\"", ErrInvalidProtocolName)
+	}
+
+	return fmt.Sprintf("%d-%s-%s", p.SecurityLevel, name, keyID), nil
+}
+
+// isNotProtocolNameRune returns true for runes not allowed in a (lower-cased)
+// protocol name.
+func isNotProtocolNameRune(r rune) bool {
+	return (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != ' '
+}
+
+// AnyoneKey returns the BRC-43 "anyone" key pair (private key 1)
+//
+// Use its public key as the counterparty for keys that anyone may derive
+func AnyoneKey() (*ec.PrivateKey, *ec.PublicKey) {
+	return ec.PrivateKeyFromBytes([]byte{1})
+}
+
+// DeriveChildPublicKey will derive the BRC-42 child public key of the recipient
+// for the invoice number, using the sender's private key
+//
+// Only the recipient can derive the matching private key (see DeriveChildPrivateKey)
+func DeriveChildPublicKey(senderPrivateKey *ec.PrivateKey, recipientPubKey *ec.PublicKey,
+	invoiceNumber string,
+) (*ec.PublicKey, error) {
+	if senderPrivateKey == nil {
+		return nil, ErrPrivateKeyMissing
+	} else if recipientPubKey == nil {
+		return nil, ErrPublicKeyNil
+	}
+	return recipientPubKey.DeriveChild(senderPrivateKey, invoiceNumber)
+}
+
+// DeriveChildPrivateKey will derive the BRC-42 child private key of the
+// recipient for the invoice number, using the sender's public key
+func DeriveChildPrivateKey(recipientPrivateKey *ec.PrivateKey, senderPubKey *ec.PublicKey,
+	invoiceNumber string,
+) (*ec.PrivateKey, error) {
+	if recipientPrivateKey == nil {
+		return nil, ErrPrivateKeyMissing
+	} else if senderPubKey == nil {
+		return nil, ErrPublicKeyNil
+	}
+	return recipientPrivateKey.DeriveChild(senderPubKey, invoiceNumber)
+}
+
+// DeriveProtocolPrivateKey will derive the BRC-42 child private key of the root
+// key for a BRC-43 protocol, key ID and counterparty
+//
+// A nil counterparty derives a key for yourself ("self"); use the public key
+// from AnyoneKey for keys that anyone may derive
+func DeriveProtocolPrivateKey(rootKey *ec.PrivateKey, protocol Protocol, keyID string,
+	counterparty *ec.PublicKey,
+) (*ec.PrivateKey, error) {
+	if rootKey == nil {
+		return nil, ErrPrivateKeyMissing
+	}
+	invoiceNumber, err := protocol.InvoiceNumber(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return DeriveChildPrivateKey(rootKey, counterpartyOrSelf(rootKey, counterparty), invoiceNumber)
+}
+
+// DeriveProtocolPublicKey will derive a BRC-42 child public key for a BRC-43
+// protocol, key ID and counterparty
+//
+// If forSelf is true it returns the public key of DeriveProtocolPrivateKey (our
+// own child key), otherwise the child key of the counterparty that only the
+// counterparty can spend from. A nil counterparty means "self".
+func DeriveProtocolPublicKey(rootKey *ec.PrivateKey, protocol Protocol, keyID string,
+	counterparty *ec.PublicKey, forSelf bool,
+) (*ec.PublicKey, error) {
+	if forSelf {
+		privateKey, err := DeriveProtocolPrivateKey(rootKey, protocol, keyID, counterparty)
+		if err != nil {
+			return nil, err
+		}
+		return privateKey.PubKey(), nil
+	}
+
+	if rootKey == nil {
+		return nil, ErrPrivateKeyMissing
+	}
+	invoiceNumber, err := protocol.InvoiceNumber(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return DeriveChildPublicKey(rootKey, counterpartyOrSelf(rootKey, counterparty), invoiceNumber)
+}
+
+// counterpartyOrSelf returns the counterparty, or the root public key if nil.
+func counterpartyOrSelf(rootKey *ec.PrivateKey, counterparty *ec.PublicKey) *ec.PublicKey {
+	if counterparty == nil {
+		return rootKey.PubKey()
+	}
+	return counterparty
+}
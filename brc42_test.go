@@ -0,0 +1,330 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testProtocol is a valid BRC-43 protocol used across the tests
+const testProtocol = "go bitcoin test"
+
+// TestDeriveChildPrivateKey will test the method DeriveChildPrivateKey() with
+// the official BRC-42 private key vectors
+func TestDeriveChildPrivateKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		senderPublicKey     string
+		recipientPrivateKey string
+		invoiceNumber       string
+		expectedPrivateKey  string
+	}{
+		{
+			"033f9160df035156f1c48e75eae99914fa1a1546bec19781e8eddb900200bff9d1",
+			"6a1751169c111b4667a6539ee1be6b7cd9f6e9c8fe011a5f2fe31e03a15e0ede",
+			"f3WCaUmnN9U=",
+			"761656715bbfa172f8f9f58f5af95d9d0dfd69014cfdcacc9a245a10ff8893ef",
+		},
+		{
+			"027775fa43959548497eb510541ac34b01d5ee9ea768de74244a4a25f7b60fae8d",
+			"cab2500e206f31bc18a8af9d6f44f0b9a208c32d5cca2b22acfe9d1a213b2f36",
+			"2Ska++APzEc=",
+			"09f2b48bd75f4da6429ac70b5dce863d5ed2b350b6f2119af5626914bdb7c276",
+		},
+		{
+			"0338d2e0d12ba645578b0955026ee7554889ae4c530bd7a3b6f688233d763e169f",
+			"7a66d0896f2c4c2c9ac55670c71a9bc1bdbdfb4e8786ee5137cea1d0a05b6f20",
+			"cN/yQ7+k7pg=",
+			"7114cd9afd1eade02f76703cc976c241246a2f26f5c4b7a3a0150ecc745da9f0",
+		},
+		{
+			"02830212a32a47e68b98d477000bde08cb916f4d44ef49d47ccd4918d9aaabe9c8",
+			"6e8c3da5f2fb0306a88d6bcd427cbfba0b9c7f4c930c43122a973d620ffa3036",
+			"m2/QAsmwaA4=",
+			"f1d6fb05da1225feeddd1cf4100128afe09c3c1aadbffbd5c8bd10d329ef8f40",
+		},
+		{
+			"03f20a7e71c4b276753969e8b7e8b67e2dbafc3958d66ecba98dedc60a6615336d",
+			"e9d174eff5708a0a41b32624f9b9cc97ef08f8931ed188ee58d5390cad2bf68e",
+			"jgpUIjWFlVQ=",
+			"c5677c533f17c30f79a40744b18085632b262c0c13d87f3848c385f1389f79a6",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.invoiceNumber, func(t *testing.T) {
+			t.Parallel()
+			senderPubKey, err := PubKeyFromString(test.senderPublicKey)
+			require.NoError(t, err)
+			recipientPrivateKey, err := PrivateKeyFromString(test.recipientPrivateKey)
+			require.NoError(t, err)
+
+			childKey, err := DeriveChildPrivateKey(recipientPrivateKey, senderPubKey, test.invoiceNumber)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedPrivateKey, hex.EncodeToString(childKey.Serialize()))
+		})
+	}
+
+	t.Run("missing keys", func(t *testing.T) {
+		t.Parallel()
+		privateKey := mustTestPrivKey(t)
+		_, err := DeriveChildPrivateKey(nil, privateKey.PubKey(), "1")
+		require.ErrorIs(t, err, ErrPrivateKeyMissing)
+		_, err = DeriveChildPrivateKey(privateKey, nil, "1")
+		require.ErrorIs(t, err, ErrPublicKeyNil)
+	})
+}
+
+// TestDeriveChildPublicKey will test the method DeriveChildPublicKey() with
+// the official BRC-42 public key vectors
+func TestDeriveChildPublicKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		senderPrivateKey   string
+		recipientPublicKey string
+		invoiceNumber      string
+		expectedPublicKey  string
+	}{
+		{
+			"583755110a8c059de5cd81b8a04e1be884c46083ade3f779c1e022f6f89da94c",
+			"02c0c1e1a1f7d247827d1bcf399f0ef2deef7695c322fd91a01a91378f101b6ffc",
+			"IBioA4D/OaE=",
+			"03c1bf5baadee39721ae8c9882b3cf324f0bf3b9eb3fc1b8af8089ca7a7c2e669f",
+		},
+		{
+			"2c378b43d887d72200639890c11d79e8f22728d032a5733ba3d7be623d1bb118",
+			"039a9da906ecb8ced5c87971e9c2e7c921e66ad450fd4fc0a7d569fdb5bede8e0f",
+			"PWYuo9PDKvI=",
+			"0398cdf4b56a3b2e106224ff3be5253afd5b72de735d647831be51c713c9077848",
+		},
+		{
+			"d5a5f70b373ce164998dff7ecd93260d7e80356d3d10abf928fb267f0a6c7be6",
+			"02745623f4e5de046b6ab59ce837efa1a959a8f28286ce9154a4781ec033b85029",
+			"X9pnS+bByrM=",
+			"0273eec9380c1a11c5a905e86c2d036e70cbefd8991d9a0cfca671f5e0bbea4a3c",
+		},
+		{
+			"46cd68165fd5d12d2d6519b02feb3f4d9c083109de1bfaa2b5c4836ba717523c",
+			"031e18bb0bbd3162b886007c55214c3c952bb2ae6c33dd06f57d891a60976003b1",
+			"+ktmYRHv3uQ=",
+			"034c5c6bf2e52e8de8b2eb75883090ed7d1db234270907f1b0d1c2de1ddee5005d",
+		},
+		{
+			"7c98b8abd7967485cfb7437f9c56dd1e48ceb21a4085b8cdeb2a647f62012db4",
+			"03c8885f1e1ab4facd0f3272bb7a48b003d2e608e1619fb38b8be69336ab828f37",
+			"PPfDTTcl1ao=",
+			"03304b41cfa726096ffd9d8907fe0835f888869eda9653bca34eb7bcab870d3779",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.invoiceNumber, func(t *testing.T) {
+			t.Parallel()
+			senderPrivateKey, err := PrivateKeyFromString(test.senderPrivateKey)
+			require.NoError(t, err)
+			recipientPubKey, err := PubKeyFromString(test.recipientPublicKey)
+			require.NoError(t, err)
+
+			childKey, err := DeriveChildPublicKey(senderPrivateKey, recipientPubKey, test.invoiceNumber)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedPublicKey, hex.EncodeToString(childKey.Compressed()))
+		})
+	}
+
+	t.Run("matches the recipient private key", func(t *testing.T) {
+		t.Parallel()
+		sender := mustTestPrivKey(t)
+		recipient, err := CreatePrivateKey()
+		require.NoError(t, err)
+
+		childPubKey, err := DeriveChildPublicKey(sender, recipient.PubKey(), "invoice 1")
+		require.NoError(t, err)
+		childPrivateKey, err := DeriveChildPrivateKey(recipient, sender.PubKey(), "invoice 1")
+		require.NoError(t, err)
+		assert.True(t, childPubKey.IsEqual(childPrivateKey.PubKey()))
+
+		otherPubKey, err := DeriveChildPublicKey(sender, recipient.PubKey(), "invoice 2")
+		require.NoError(t, err)
+		assert.False(t, childPubKey.IsEqual(otherPubKey))
+	})
+
+	t.Run("missing keys", func(t *testing.T) {
+		t.Parallel()
+		privateKey := mustTestPrivKey(t)
+		_, err := DeriveChildPublicKey(nil, privateKey.PubKey(), "1")
+		require.ErrorIs(t, err, ErrPrivateKeyMissing)
+		_, err = DeriveChildPublicKey(privateKey, nil, "1")
+		require.ErrorIs(t, err, ErrPublicKeyNil)
+	})
+}
+
+// TestProtocolInvoiceNumber will test the method Protocol.InvoiceNumber()
+func TestProtocolInvoiceNumber(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		protocol      Protocol
+		keyID         string
+		expected      string
+		expectedError error
+	}{
+		{"silent", Protocol{SecurityLevelSilent, testProtocol}, "1", "0-go bitcoin test-1", nil},
+		{"every app", Protocol{SecurityLevelEveryApp, testProtocol}, "abc", "1-go bitcoin test-abc", nil},
+		{"every app and counterparty", Protocol{SecurityLevelEveryAppAndCounterparty, testProtocol}, "k", "2-go bitcoin test-k", nil},
+		{"normalized name", Protocol{SecurityLevelSilent, "  Go Bitcoin TEST "}, "1", "0-go bitcoin test-1", nil},
+		{"key id with dashes", Protocol{SecurityLevelSilent, testProtocol}, "a-b", "0-go bitcoin test-a-b", nil},
+		{"invalid security level", Protocol{3, testProtocol}, "1", "", ErrInvalidSecurityLevel},
+		{"empty key id", Protocol{SecurityLevelSilent, testProtocol}, "", "", ErrInvalidKeyID},
+		{"long key id", Protocol{SecurityLevelSilent, testProtocol}, strings.Repeat("k", 801), "", ErrInvalidKeyID},
+		{"short name", Protocol{SecurityLevelSilent, "abcd"}, "1", "", ErrInvalidProtocolName},
+		{"long name", Protocol{SecurityLevelSilent, strings.Repeat("a", 401)}, "1", "", ErrInvalidProtocolName},
+		{"double space", Protocol{SecurityLevelSilent, "go  bitcoin"}, "1", "", ErrInvalidProtocolName},
+		{"invalid character", Protocol{SecurityLevelSilent, "go-bitcoin"}, "1", "", ErrInvalidProtocolName},
+		{"protocol suffix", Protocol{SecurityLevelSilent, "my payment protocol"}, "1", "", ErrInvalidProtocolName},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			invoiceNumber, err := test.protocol.InvoiceNumber(test.keyID)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, invoiceNumber)
+		})
+	}
+
+	t.Run("longest name and key id", func(t *testing.T) {
+		t.Parallel()
+		_, err := Protocol{SecurityLevelSilent, strings.Repeat("a", 400)}.InvoiceNumber(strings.Repeat("k", 800))
+		require.NoError(t, err)
+	})
+}
+
+// TestDeriveProtocolKeys will test the methods DeriveProtocolPrivateKey() and
+// DeriveProtocolPublicKey() against the go-sdk wallet key deriver
+func TestDeriveProtocolKeys(t *testing.T) {
+	t.Parallel()
+
+	alice := mustTestPrivKey(t)
+	bob, err := CreatePrivateKey()
+	require.NoError(t, err)
+	_, anyone := AnyoneKey()
+
+	protocol := Protocol{SecurityLevelEveryApp, testProtocol}
+	sdkProtocol := wallet.Protocol{SecurityLevel: wallet.SecurityLevelEveryApp, Protocol: testProtocol}
+	deriver := wallet.NewKeyDeriver(alice)
+
+	counterparties := []struct {
+		name            string
+		counterparty    *ec.PublicKey
+		sdkCounterparty wallet.Counterparty
+	}{
+		{"self", nil, wallet.Counterparty{Type: wallet.CounterpartyTypeSelf}},
+		{"anyone", anyone, wallet.Counterparty{Type: wallet.CounterpartyTypeAnyone}},
+		{"other", bob.PubKey(), wallet.Counterparty{Type: wallet.CounterpartyTypeOther, Counterparty: bob.PubKey()}},
+	}
+
+	for _, c := range counterparties {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			privateKey, deriveErr := DeriveProtocolPrivateKey(alice, protocol, "1", c.counterparty)
+			require.NoError(t, deriveErr)
+			expectedPrivateKey, deriveErr := deriver.DerivePrivateKey(sdkProtocol, "1", c.sdkCounterparty)
+			require.NoError(t, deriveErr)
+			assert.Equal(t, expectedPrivateKey.Serialize(), privateKey.Serialize())
+
+			for _, forSelf := range []bool{true, false} {
+				pubKey, pubErr := DeriveProtocolPublicKey(alice, protocol, "1", c.counterparty, forSelf)
+				require.NoError(t, pubErr)
+				expectedPubKey, pubErr := deriver.DerivePublicKey(sdkProtocol, "1", c.sdkCounterparty, forSelf)
+				require.NoError(t, pubErr)
+				assert.True(t, expectedPubKey.IsEqual(pubKey), "forSelf %t", forSelf)
+			}
+		})
+	}
+
+	t.Run("counterparty derives the same key", func(t *testing.T) {
+		t.Parallel()
+		// Alice derives Bob's child public key, Bob derives the private key
+		pubKey, deriveErr := DeriveProtocolPublicKey(alice, protocol, "invoice 42", bob.PubKey(), false)
+		require.NoError(t, deriveErr)
+		privateKey, deriveErr := DeriveProtocolPrivateKey(bob, protocol, "invoice 42", alice.PubKey())
+		require.NoError(t, deriveErr)
+		assert.True(t, pubKey.IsEqual(privateKey.PubKey()))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		_, deriveErr := DeriveProtocolPrivateKey(nil, protocol, "1", nil)
+		require.ErrorIs(t, deriveErr, ErrPrivateKeyMissing)
+		_, deriveErr = DeriveProtocolPublicKey(nil, protocol, "1", nil, false)
+		require.ErrorIs(t, deriveErr, ErrPrivateKeyMissing)
+		_, deriveErr = DeriveProtocolPublicKey(nil, protocol, "1", nil, true)
+		require.ErrorIs(t, deriveErr, ErrPrivateKeyMissing)
+		_, deriveErr = DeriveProtocolPrivateKey(alice, protocol, "", nil)
+		require.ErrorIs(t, deriveErr, ErrInvalidKeyID)
+		_, deriveErr = DeriveProtocolPublicKey(alice, Protocol{SecurityLevelSilent, "bad"}, "1", nil, false)
+		require.ErrorIs(t, deriveErr, ErrInvalidProtocolName)
+	})
+}
+
+// ExampleDeriveChildPublicKey example using DeriveChildPublicKey()
+func ExampleDeriveChildPublicKey() {
+	sender, err := PrivateKeyFromString(testPrivateKeyHex)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	recipient, _ := AnyoneKey()
+
+	// The sender derives a fresh key for the recipient per invoice
+	var invoiceNumber string
+	if invoiceNumber, err = (Protocol{SecurityLevelSilent, "example payments"}).InvoiceNumber("1"); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	var childPubKey *ec.PublicKey
+	if childPubKey, err = DeriveChildPublicKey(sender, recipient.PubKey(), invoiceNumber); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	// The recipient derives the matching private key
+	var childPrivateKey *ec.PrivateKey
+	if childPrivateKey, err = DeriveChildPrivateKey(recipient, sender.PubKey(), invoiceNumber); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("invoice %s matches: %t", invoiceNumber, childPubKey.IsEqual(childPrivateKey.PubKey()))
+	// Output:invoice 0-example payments-1 matches: true
+}
+
+// BenchmarkDeriveChildPublicKey benchmarks the method DeriveChildPublicKey()
+func BenchmarkDeriveChildPublicKey(b *testing.B) {
+	sender, _ := CreatePrivateKey()
+	recipient, _ := CreatePrivateKey()
+	for b.Loop() {
+		_, _ = DeriveChildPublicKey(sender, recipient.PubKey(), "1-benchmark-1")
+	}
+}
+
+// BenchmarkDeriveChildPrivateKey benchmarks the method DeriveChildPrivateKey()
+func BenchmarkDeriveChildPrivateKey(b *testing.B) {
+	sender, _ := CreatePrivateKey()
+	recipient, _ := CreatePrivateKey()
+	for b.Loop() {
+		_, _ = DeriveChildPrivateKey(recipient, sender.PubKey(), "1-benchmark-1")
+	}
+}
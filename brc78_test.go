@@ -0,0 +1,198 @@
+package bitcoin
+
+import (
+	"fmt"
+	"testing"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEncryptBRC78 will test the methods EncryptBRC78() and DecryptBRC78()
+func TestEncryptBRC78(t *testing.T) {
+	t.Parallel()
+
+	sender, err := CreatePrivateKey()
+	require.NoError(t, err)
+	recipient, err := CreatePrivateKey()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{caseEmpty, []byte{}},
+		{"single byte", []byte{0x00}},
+		{"text", []byte(testEncryptionMessage)},
+		{"json data", []byte(`{"json":"data"}`)},
+		{"binary", []byte{1, 2, 4, 8, 16, 32, 64, 128}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			encrypted, err := EncryptBRC78(sender, recipient.PubKey(), test.data)
+			require.NoError(t, err)
+			assert.Equal(t, EncryptionFormatBRC78, DetectEncryptionFormat(encrypted))
+
+			decrypted, senderPubKey, err := DecryptBRC78(recipient, encrypted)
+			require.NoError(t, err)
+			assert.Equal(t, string(test.data), string(decrypted))
+			assert.True(t, senderPubKey.IsEqual(sender.PubKey()))
+		})
+	}
+}
+
+// TestEncryptBRC78Errors will test the error cases of EncryptBRC78() and DecryptBRC78()
+func TestEncryptBRC78Errors(t *testing.T) {
+	t.Parallel()
+
+	sender, err := CreatePrivateKey()
+	require.NoError(t, err)
+	recipient, err := CreatePrivateKey()
+	require.NoError(t, err)
+	other, err := CreatePrivateKey()
+	require.NoError(t, err)
+
+	t.Run("nil sender", func(t *testing.T) {
+		_, err := EncryptBRC78(nil, recipient.PubKey(), []byte("data"))
+		require.ErrorIs(t, err, ErrPrivateKeyMissing)
+	})
+
+	t.Run("nil recipient", func(t *testing.T) {
+		_, err := EncryptBRC78(sender, nil, []byte("data"))
+		require.ErrorIs(t, err, ErrPublicKeyNil)
+	})
+
+	encrypted, err := EncryptBRC78(sender, recipient.PubKey(), []byte("data"))
+	require.NoError(t, err)
+
+	t.Run("nil recipient key on decrypt", func(t *testing.T) {
+		_, _, err := DecryptBRC78(nil, encrypted)
+		require.ErrorIs(t, err, ErrPrivateKeyMissing)
+	})
+
+	t.Run("wrong recipient", func(t *testing.T) {
+		_, _, err := DecryptBRC78(other, encrypted)
+		require.Error(t, err)
+	})
+
+	t.Run("tampered cipher text", func(t *testing.T) {
+		tampered := append([]byte{}, encrypted...)
+		tampered[len(tampered)-1] ^= 0x01
+		_, _, err := DecryptBRC78(recipient, tampered)
+		require.Error(t, err)
+	})
+
+	t.Run("too short", func(t *testing.T) {
+		_, _, err := DecryptBRC78(recipient, encrypted[:brc78MinLength-1])
+		require.ErrorIs(t, err, ErrUnknownEncryptionFormat)
+	})
+
+	t.Run("legacy payload", func(t *testing.T) {
+		legacy, err := eciesEncrypt(recipient.PubKey(), []byte("data"))
+		require.NoError(t, err)
+		_, _, err = DecryptBRC78(recipient, legacy)
+		require.ErrorIs(t, err, ErrUnknownEncryptionFormat)
+	})
+}
+
+// TestEncryptBRC78String will test the methods EncryptBRC78String() and DecryptBRC78String()
+func TestEncryptBRC78String(t *testing.T) {
+	t.Parallel()
+
+	sender, err := CreatePrivateKey()
+	require.NoError(t, err)
+	recipient, err := CreatePrivateKey()
+	require.NoError(t, err)
+
+	encrypted, err := EncryptBRC78String(sender, recipient.PubKey(), testEncryptionMessage)
+	require.NoError(t, err)
+	assert.Equal(t, EncryptionFormatBRC78, DetectEncryptionFormatString(encrypted))
+
+	var decrypted string
+	var senderPubKey *ec.PublicKey
+	decrypted, senderPubKey, err = DecryptBRC78String(recipient, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, testEncryptionMessage, decrypted)
+	assert.True(t, senderPubKey.IsEqual(sender.PubKey()))
+
+	_, _, err = DecryptBRC78String(recipient, "invalid-hex")
+	require.Error(t, err)
+
+	_, err = EncryptBRC78String(nil, recipient.PubKey(), testEncryptionMessage)
+	require.ErrorIs(t, err, ErrPrivateKeyMissing)
+}
+
+// TestDecryptWithPrivateKeyBRC78 will test that DecryptWithPrivateKey() accepts
+// both legacy and BRC-78 payloads
+func TestDecryptWithPrivateKeyBRC78(t *testing.T) {
+	t.Parallel()
+
+	sender, err := CreatePrivateKey()
+	require.NoError(t, err)
+	recipient, err := CreatePrivateKey()
+	require.NoError(t, err)
+
+	legacy, err := EncryptWithPrivateKey(recipient, testEncryptionMessage)
+	require.NoError(t, err)
+
+	brc78, err := EncryptBRC78String(sender, recipient.PubKey(), testEncryptionMessage)
+	require.NoError(t, err)
+
+	for _, payload := range []string{legacy, brc78} {
+		decrypted, err := DecryptWithPrivateKey(recipient, payload)
+		require.NoError(t, err)
+		assert.Equal(t, testEncryptionMessage, decrypted)
+	}
+
+	// Wrong key still fails for BRC-78
+	_, err = DecryptWithPrivateKey(sender, brc78)
+	require.Error(t, err)
+}
+
+// ExampleEncryptBRC78String example using EncryptBRC78String()
+func ExampleEncryptBRC78String() {
+	sender, _ := PrivateKeyFromString(testPrivateKeyHex)
+	recipient, err := CreatePrivateKey()
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	// Encrypt the text (the payload changes each time)
+	var encrypted string
+	if encrypted, err = EncryptBRC78String(sender, recipient.PubKey(), "encrypt my message"); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	// Decrypt using the single entry point
+	var decrypted string
+	if decrypted, err = DecryptWithPrivateKey(recipient, encrypted); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("format: %s decrypted: %s", DetectEncryptionFormatString(encrypted), decrypted)
+	// Output:format: brc78 decrypted: encrypt my message
+}
+
+// BenchmarkEncryptBRC78 benchmarks the method EncryptBRC78()
+func BenchmarkEncryptBRC78(b *testing.B) {
+	sender, _ := CreatePrivateKey()
+	recipient, _ := CreatePrivateKey()
+	for b.Loop() {
+		_, _ = EncryptBRC78(sender, recipient.PubKey(), []byte("some-data"))
+	}
+}
+
+// BenchmarkDecryptBRC78 benchmarks the method DecryptBRC78()
+func BenchmarkDecryptBRC78(b *testing.B) {
+	sender, _ := CreatePrivateKey()
+	recipient, _ := CreatePrivateKey()
+	encrypted, _ := EncryptBRC78(sender, recipient.PubKey(), []byte("some-data"))
+	for b.Loop() {
+		_, _, _ = DecryptBRC78(recipient, encrypted)
+	}
+}
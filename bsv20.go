@@ -0,0 +1,302 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/bsv-blockchain/go-bt/v2"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+)
+
+// BSV20ContentType is the content type of BSV-20 and BSV-21 inscriptions
+const BSV20ContentType = "application/bsv-20"
+
+// BSV20Protocol is the "p" field of BSV-20 and BSV-21 inscriptions
+const BSV20Protocol = "bsv-20"
+
+// BSV20MaxDecimals is the maximum number of decimals of a token
+const BSV20MaxDecimals uint8 = 18
+
+var (
+	// ErrInvalidBSV20 is returned when a BSV-20/BSV-21 inscription is malformed
+	ErrInvalidBSV20 = errors.New("invalid BSV-20 inscription")
+
+	// ErrTokenMismatch is returned when a token utxo holds another token
+	ErrTokenMismatch = errors.New("token utxo holds another token")
+
+	// ErrInsufficientTokens is returned when token utxos do not cover the transfers
+	ErrInsufficientTokens = errors.New("insufficient tokens in utxos to cover transfers")
+)
+
+// BSV20Op is a BSV-20/BSV-21 operation
+type BSV20Op string
+
+// BSV-20 (tick) and BSV-21 (id) operations
+const (
+	BSV20Deploy     BSV20Op = "deploy"
+	BSV20Mint       BSV20Op = "mint"
+	BSV20Transfer   BSV20Op = "transfer"
+	BSV21DeployMint BSV20Op = "deploy+mint"
+)
+
+// BSV20 is a BSV-20 (tick based) or BSV-21 (id based) token inscription
+//
+// Amounts are in the smallest unit (see Decimals) and are JSON strings.
+// BSV-21 tokens are identified by the outpoint of their deploy+mint
+// ("<txid>_<vout>"). Spec: https://docs.1satordinals.com/fungible-tokens
+type BSV20 struct {
+	Protocol string  `json:"p"`
+	Op       BSV20Op `json:"op"`
+	Tick     string  `json:"tick,omitempty"`
+	ID       string  `json:"id,omitempty"`
+	Symbol   string  `json:"sym,omitempty"`
+	Icon     string  `json:"icon,omitempty"`
+	Amount   uint64  `json:"amt,string,omitempty"`
+	Max      uint64  `json:"max,string,omitempty"`
+	Limit    uint64  `json:"lim,string,omitempty"`
+	Decimals uint8   `json:"dec,string,omitempty"`
+}
+
+// TokenUtxo is a 1Sat ordinal utxo holding an amount of a token (tick or id)
+type TokenUtxo struct {
+	Utxo
+	Token  string
+	Amount uint64
+}
+
+// TokenTransfer is an amount of tokens to send to an address
+type TokenTransfer struct {
+	Address string
+	Amount  uint64
+}
+
+// BSV20Output is a token inscription in a transaction output
+//
+// Token is the tick or id, or "<txid>_<vout>" of this output for a BSV-21 deploy+mint.
+type BSV20Output struct {
+	Vout    uint32
+	Address string
+	Token   string
+	BSV20   *BSV20
+}
+
+// TokenID will return the id of a BSV-21 token, or the tick of a BSV-20 token
+func (b *BSV20) TokenID() string {
+	if b.ID != "" {
+		return b.ID
+	}
+	return b.Tick
+}
+
+// Inscription will validate and encode the token as a JSON inscription
+func (b *BSV20) Inscription() (*Inscription, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	content, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return &Inscription{ContentType: BSV20ContentType, Content: content}, nil
+}
+
+// NewBSV20Deploy will build a BSV-20 deploy inscription output for a tick
+// (limit 0 is no mint limit)
+func NewBSV20Deploy(address, tick string, maxSupply, limit uint64, decimals uint8) (*InscriptionOutput, error) {
+	return newBSV20Output(address, &BSV20{Op: BSV20Deploy, Tick: tick, Max: maxSupply, Limit: limit, Decimals: decimals})
+}
+
+// NewBSV20Mint will build a BSV-20 mint inscription output for a tick
+func NewBSV20Mint(address, tick string, amount uint64) (*InscriptionOutput, error) {
+	return newBSV20Output(address, &BSV20{Op: BSV20Mint, Tick: tick, Amount: amount})
+}
+
+// NewBSV21DeployMint will build a BSV-21 deploy+mint inscription output, minting
+// the whole supply to the address (the token id is the outpoint of this output)
+func NewBSV21DeployMint(address, symbol string, amount uint64, decimals uint8, icon string) (*InscriptionOutput, error) {
+	return newBSV20Output(address, &BSV20{Op: BSV21DeployMint, Symbol: symbol, Amount: amount, Decimals: decimals, Icon: icon})
+}
+
+// NewBSV20Transfer will build a transfer inscription output of a BSV-21 token
+// id ("<txid>_<vout>") or a BSV-20 tick
+func NewBSV20Transfer(address, token string, amount uint64) (*InscriptionOutput, error) {
+	transfer := &BSV20{Op: BSV20Transfer, Amount: amount}
+	if isBSV21ID(token) {
+		transfer.ID = token
+	} else {
+		transfer.Tick = token
+	}
+	return newBSV20Output(address, transfer)
+}
+
+// CreateBSV20TransferTx will create a signed transaction transferring a token
+// (tick or id) from token utxos to addresses
+//
+// Inputs are the token utxos (in order) then the utxos paying the fee (1Sat
+// ordinals are skipped); outputs are the transfers (in order), the token
+// change to the change address, then the satoshi change. All inputs are
+// signed with the private key.
+//
+// Ticks match token utxos regardless of case; ids must match exactly.
+func CreateBSV20TransferTx(token string, tokenUtxos []*TokenUtxo, transfers []*TokenTransfer,
+	utxos []*Utxo, changeAddress string, standardRate, dataRate *bt.Fee, privateKey *ec.PrivateKey,
+) (*bt.Tx, error) {
+	if len(tokenUtxos) == 0 {
+		return nil, ErrUtxosRequired
+	}
+	if len(transfers) == 0 {
+		return nil, fmt.Errorf("%w: no transfers", ErrInvalidBSV20)
+	}
+
+	var available, sent uint64
+	ordinals := make([]*Utxo, 0, len(tokenUtxos))
+	for _, tokenUtxo := range tokenUtxos {
+		if !sameBSV20Token(tokenUtxo.Token, token) {
+			return nil, fmt.Errorf("%w: %s utxo %s:%d holds %s", ErrTokenMismatch, token, tokenUtxo.TxID, tokenUtxo.Vout, tokenUtxo.Token)
+		}
+		var carry uint64
+		if available, carry = bits.Add64(available, tokenUtxo.Amount, 0); carry != 0 {
+			return nil, fmt.Errorf("%w: token utxo amounts overflow", ErrInvalidBSV20)
+		}
+		ordinals = append(ordinals, &tokenUtxo.Utxo)
+	}
+
+	outputs := make([]*InscriptionOutput, 0, len(transfers)+1)
+	for _, transfer := range transfers {
+		output, err := NewBSV20Transfer(transfer.Address, token, transfer.Amount)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, output)
+		var carry uint64
+		if sent, carry = bits.Add64(sent, transfer.Amount, 0); carry != 0 {
+			return nil, fmt.Errorf("%w: transfer amounts overflow", ErrInvalidBSV20)
+		}
+	}
+	if sent > available {
+		return nil, fmt.Errorf("%w: need %d, found %d", ErrInsufficientTokens, sent, available)
+	}
+
+	// Send the remaining tokens back, or they are burned
+	if available > sent {
+		tokenChange, err := NewBSV20Transfer(changeAddress, token, available-sent)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, tokenChange)
+	}
+
+	return createOrdinalTx(ordinals, utxos, outputs, changeAddress, standardRate, dataRate, privateKey)
+}
+
+// ParseBSV20 will parse and validate a BSV-20/BSV-21 inscription
+func ParseBSV20(inscription *Inscription) (*BSV20, error) {
+	if inscription == nil || inscription.ContentType != BSV20ContentType {
+		return nil, fmt.Errorf("%w: content type is not %s", ErrInvalidBSV20, BSV20ContentType)
+	}
+	b := &BSV20{}
+	if err := json.Unmarshal(inscription.Content, b); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBSV20, err)
+	}
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// GetBSV20Outputs will get the token movements (valid BSV-20/BSV-21
+// inscriptions) from every output of a transaction, with their owners (on the network)
+//
+// Expects tx to not be nil (otherwise will panic)
+func GetBSV20Outputs(tx *bt.Tx, network Network) []*BSV20Output {
+	var outputs []*BSV20Output
+	for _, inscription := range GetInscriptions(tx, network) {
+		token, err := ParseBSV20(inscription.Inscription)
+		if err != nil {
+			continue
+		}
+		output := &BSV20Output{
+			Vout:    inscription.Vout,
+			Address: inscription.Address,
+			Token:   token.TokenID(),
+			BSV20:   token,
+		}
+		if token.Op == BSV21DeployMint {
+			output.Token = tx.TxID() + "_" + strconv.FormatUint(uint64(inscription.Vout), 10)
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs
+}
+
+// newBSV20Output builds the inscription output of a token.
+func newBSV20Output(address string, token *BSV20) (*InscriptionOutput, error) {
+	token.Protocol = BSV20Protocol
+	inscription, err := token.Inscription()
+	if err != nil {
+		return nil, err
+	}
+	return &InscriptionOutput{Address: address, Inscription: inscription}, nil
+}
+
+// validate checks the fields required by the operation.
+func (b *BSV20) validate() error {
+	if b.Protocol != BSV20Protocol {
+		return fmt.Errorf("%w: protocol is %q", ErrInvalidBSV20, b.Protocol)
+	}
+	if b.Decimals > BSV20MaxDecimals {
+		return fmt.Errorf("%w: %d decimals, max is %d", ErrInvalidBSV20, b.Decimals, BSV20MaxDecimals)
+	}
+
+	switch b.Op {
+	case BSV20Deploy:
+		if b.Tick == "" || b.Max == 0 {
+			return fmt.Errorf("%w: deploy needs a tick and max supply", ErrInvalidBSV20)
+		}
+	case BSV20Mint:
+		if b.Tick == "" || b.Amount == 0 {
+			return fmt.Errorf("%w: mint needs a tick and amount", ErrInvalidBSV20)
+		}
+	case BSV21DeployMint:
+		if b.Amount == 0 {
+			return fmt.Errorf("%w: deploy+mint needs an amount", ErrInvalidBSV20)
+		}
+	case BSV20Transfer:
+		if b.Amount == 0 || (b.Tick == "") == (b.ID == "") {
+			return fmt.Errorf("%w: transfer needs an amount and a tick or id", ErrInvalidBSV20)
+		}
+		if b.ID != "" && !isBSV21ID(b.ID) {
+			return fmt.Errorf("%w: id %q is not <txid>_<vout>", ErrInvalidBSV20, b.ID)
+		}
+	default:
+		return fmt.Errorf("%w: unknown op %q", ErrInvalidBSV20, b.Op)
+	}
+	return nil
+}
+
+// sameBSV20Token returns true if two tokens match: BSV-21 ids exactly, BSV-20
+// ticks regardless of case.
+func sameBSV20Token(a, b string) bool {
+	if isBSV21ID(a) || isBSV21ID(b) {
+		return a == b
+	}
+	return strings.EqualFold(a, b)
+}
+
+// isBSV21ID returns true for a "<txid>_<vout>" token id.
+func isBSV21ID(token string) bool {
+	txID, vout, ok := strings.Cut(token, "_")
+	if !ok || len(txID) != 64 {
+		return false
+	}
+	if _, err := hex.DecodeString(txID); err != nil {
+		return false
+	}
+	_, err := strconv.ParseUint(vout, 10, 32)
+	return err == nil
+}
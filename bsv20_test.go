@@ -0,0 +1,280 @@
+package bitcoin
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/bsv-blockchain/go-bt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBSV21ID is a BSV-21 token id (the outpoint of its deploy+mint)
+const testBSV21ID = "b7b0650a7c3a1bd4716369783876348b59f5404784970192cec1996e86950576_0"
+
+// newTestTokenUtxo returns a token utxo of testTxID at vout
+func newTestTokenUtxo(token string, amount uint64, vout uint32) *TokenUtxo {
+	utxo := newTestUtxo(OrdinalSatoshis)
+	utxo.Vout = vout
+	return &TokenUtxo{Utxo: *utxo, Token: token, Amount: amount}
+}
+
+// TestBSV20Builders will test the BSV-20/BSV-21 inscription builders
+func TestBSV20Builders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		build           func() (*InscriptionOutput, error)
+		expectedContent string
+		expectedError   error
+	}{
+		{"deploy", func() (*InscriptionOutput, error) { return NewBSV20Deploy(testAddress, "ORDI", 21000000, 1000, 8) },
+			`{"p":"bsv-20","op":"deploy","tick":"ORDI","max":"21000000","lim":"1000","dec":"8"}`, nil},
+		{"deploy without limit", func() (*InscriptionOutput, error) { return NewBSV20Deploy(testAddress, "ORDI", 21000000, 0, 0) },
+			`{"p":"bsv-20","op":"deploy","tick":"ORDI","max":"21000000"}`, nil},
+		{"mint", func() (*InscriptionOutput, error) { return NewBSV20Mint(testAddress, "ORDI", 1000) },
+			`{"p":"bsv-20","op":"mint","tick":"ORDI","amt":"1000"}`, nil},
+		{"deploy+mint", func() (*InscriptionOutput, error) { return NewBSV21DeployMint(testAddress, "TKN", 1000000, 2, "") },
+			`{"p":"bsv-20","op":"deploy+mint","sym":"TKN","amt":"1000000","dec":"2"}`, nil},
+		{"transfer tick", func() (*InscriptionOutput, error) { return NewBSV20Transfer(testAddress, "ORDI", 5) },
+			`{"p":"bsv-20","op":"transfer","tick":"ORDI","amt":"5"}`, nil},
+		{"transfer id", func() (*InscriptionOutput, error) { return NewBSV20Transfer(testAddress, testBSV21ID, 5) },
+			`{"p":"bsv-20","op":"transfer","id":"` + testBSV21ID + `","amt":"5"}`, nil},
+		{"deploy without max", func() (*InscriptionOutput, error) { return NewBSV20Deploy(testAddress, "ORDI", 0, 0, 0) }, "", ErrInvalidBSV20},
+		{"too many decimals", func() (*InscriptionOutput, error) { return NewBSV20Deploy(testAddress, "ORDI", 1, 0, 19) }, "", ErrInvalidBSV20},
+		{"mint without tick", func() (*InscriptionOutput, error) { return NewBSV20Mint(testAddress, "", 1000) }, "", ErrInvalidBSV20},
+		{"mint zero", func() (*InscriptionOutput, error) { return NewBSV20Mint(testAddress, "ORDI", 0) }, "", ErrInvalidBSV20},
+		{"deploy+mint zero", func() (*InscriptionOutput, error) { return NewBSV21DeployMint(testAddress, "TKN", 0, 0, "") }, "", ErrInvalidBSV20},
+		{"transfer zero", func() (*InscriptionOutput, error) { return NewBSV20Transfer(testAddress, "ORDI", 0) }, "", ErrInvalidBSV20},
+		{"transfer without token", func() (*InscriptionOutput, error) { return NewBSV20Transfer(testAddress, "", 5) }, "", ErrInvalidBSV20},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			output, err := test.build()
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testAddress, output.Address)
+			assert.Equal(t, BSV20ContentType, output.Inscription.ContentType)
+			assert.JSONEq(t, test.expectedContent, string(output.Inscription.Content))
+		})
+	}
+}
+
+// TestParseBSV20 will test the method ParseBSV20()
+func TestParseBSV20(t *testing.T) {
+	t.Parallel()
+
+	inscription := func(content string) *Inscription {
+		return &Inscription{ContentType: BSV20ContentType, Content: []byte(content)}
+	}
+
+	tests := []struct {
+		name          string
+		inscription   *Inscription
+		expected      *BSV20
+		expectedError error
+	}{
+		{"transfer", inscription(`{"p":"bsv-20","op":"transfer","tick":"ORDI","amt":"5"}`),
+			&BSV20{Protocol: BSV20Protocol, Op: BSV20Transfer, Tick: "ORDI", Amount: 5}, nil},
+		{"deploy+mint", inscription(`{"p":"bsv-20","op":"deploy+mint","sym":"TKN","amt":"100","dec":"2","icon":"` + testBSV21ID + `"}`),
+			&BSV20{Protocol: BSV20Protocol, Op: BSV21DeployMint, Symbol: "TKN", Amount: 100, Decimals: 2, Icon: testBSV21ID}, nil},
+		{"nil", nil, nil, ErrInvalidBSV20},
+		{"wrong content type", &Inscription{ContentType: "text/plain", Content: []byte(`{}`)}, nil, ErrInvalidBSV20},
+		{"not json", inscription(`hello`), nil, ErrInvalidBSV20},
+		{"numeric amount", inscription(`{"p":"bsv-20","op":"mint","tick":"ORDI","amt":5}`), nil, ErrInvalidBSV20},
+		{"negative amount", inscription(`{"p":"bsv-20","op":"mint","tick":"ORDI","amt":"-5"}`), nil, ErrInvalidBSV20},
+		{"wrong protocol", inscription(`{"p":"brc-20","op":"mint","tick":"ORDI","amt":"5"}`), nil, ErrInvalidBSV20},
+		{"unknown op", inscription(`{"p":"bsv-20","op":"burn","tick":"ORDI","amt":"5"}`), nil, ErrInvalidBSV20},
+		{"tick and id", inscription(`{"p":"bsv-20","op":"transfer","tick":"ORDI","id":"` + testBSV21ID + `","amt":"5"}`), nil, ErrInvalidBSV20},
+		{"invalid id", inscription(`{"p":"bsv-20","op":"transfer","id":"abc_0","amt":"5"}`), nil, ErrInvalidBSV20},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			b, err := ParseBSV20(test.inscription)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, b)
+		})
+	}
+}
+
+// TestCreateBSV20TransferTx will test the method CreateBSV20TransferTx()
+func TestCreateBSV20TransferTx(t *testing.T) {
+	t.Parallel()
+
+	privateKey := mustTestPrivKey(t)
+
+	t.Run("transfer with token change", func(t *testing.T) {
+		t.Parallel()
+		tx, err := CreateBSV20TransferTx(
+			testBSV21ID,
+			[]*TokenUtxo{newTestTokenUtxo(testBSV21ID, 60, 1), newTestTokenUtxo(testBSV21ID, 50, 2)},
+			[]*TokenTransfer{{Address: testAddress, Amount: 70}, {Address: testAddress2, Amount: 30}},
+			[]*Utxo{newTestUtxo(OrdinalSatoshis), newTestUtxo(1000)},
+			testChangeAddress, nil, nil, privateKey,
+		)
+		require.NoError(t, err)
+
+		// Token inputs first, then the fee input (the 1 sat utxo is not spent)
+		require.Len(t, tx.Inputs, 3)
+		assert.Equal(t, uint32(1), tx.Inputs[0].PreviousTxOutIndex)
+		assert.Equal(t, uint32(2), tx.Inputs[1].PreviousTxOutIndex)
+		assert.Equal(t, uint32(0), tx.Inputs[2].PreviousTxOutIndex)
+		assert.Equal(t, uint64(1000), tx.Inputs[2].PreviousTxSatoshis)
+
+		// Transfers, token change, then satoshi change
+		require.Len(t, tx.Outputs, 4)
+		outputs := GetBSV20Outputs(tx, NetworkMainnet)
+		require.Len(t, outputs, 3)
+		for i, expected := range []struct {
+			address string
+			amount  uint64
+		}{{testAddress, 70}, {testAddress2, 30}, {testChangeAddress, 10}} {
+			assert.Equal(t, uint32(i), outputs[i].Vout) // #nosec G115 -- test index
+			assert.Equal(t, expected.address, outputs[i].Address)
+			assert.Equal(t, testBSV21ID, outputs[i].Token)
+			assert.Equal(t, BSV20Transfer, outputs[i].BSV20.Op)
+			assert.Equal(t, expected.amount, outputs[i].BSV20.Amount)
+		}
+
+		// Satoshis in = satoshis out + fee
+		fee := 1002 - tx.TotalOutputSatoshis()
+		assert.GreaterOrEqual(t, fee, CalculateFeeForTx(tx, nil, nil))
+	})
+
+	t.Run("exact amount has no token change", func(t *testing.T) {
+		t.Parallel()
+		tx, err := CreateBSV20TransferTx(
+			"ORDI",
+			[]*TokenUtxo{newTestTokenUtxo("ORDI", 100, 1)},
+			[]*TokenTransfer{{Address: testAddress, Amount: 100}},
+			[]*Utxo{newTestUtxo(1000)},
+			testChangeAddress, nil, nil, privateKey,
+		)
+		require.NoError(t, err)
+		outputs := GetBSV20Outputs(tx, NetworkMainnet)
+		require.Len(t, outputs, 1)
+		assert.Equal(t, "ORDI", outputs[0].Token)
+		assert.Equal(t, "ORDI", outputs[0].BSV20.Tick)
+		require.Len(t, tx.Outputs, 2)
+	})
+
+	t.Run("ticks match regardless of case", func(t *testing.T) {
+		t.Parallel()
+		tx, err := CreateBSV20TransferTx(
+			"ordi",
+			[]*TokenUtxo{newTestTokenUtxo("ORDI", 100, 1)},
+			[]*TokenTransfer{{Address: testAddress, Amount: 100}},
+			[]*Utxo{newTestUtxo(1000)},
+			testChangeAddress, nil, nil, privateKey,
+		)
+		require.NoError(t, err)
+		require.Len(t, GetBSV20Outputs(tx, NetworkMainnet), 1)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		tokens := []*TokenUtxo{newTestTokenUtxo("ORDI", 100, 1)}
+		transfers := []*TokenTransfer{{Address: testAddress, Amount: 10}}
+		utxos := []*Utxo{newTestUtxo(1000)}
+
+		_, err := CreateBSV20TransferTx("ORDI", nil, transfers, utxos, testChangeAddress, nil, nil, privateKey)
+		require.ErrorIs(t, err, ErrUtxosRequired)
+		_, err = CreateBSV20TransferTx("ORDI", tokens, nil, utxos, testChangeAddress, nil, nil, privateKey)
+		require.ErrorIs(t, err, ErrInvalidBSV20)
+		_, err = CreateBSV20TransferTx("OTHER", tokens, transfers, utxos, testChangeAddress, nil, nil, privateKey)
+		require.ErrorIs(t, err, ErrTokenMismatch)
+		_, err = CreateBSV20TransferTx("ORDI", tokens, []*TokenTransfer{{Address: testAddress, Amount: 101}}, utxos, testChangeAddress, nil, nil, privateKey)
+		require.ErrorIs(t, err, ErrInsufficientTokens)
+		_, err = CreateBSV20TransferTx("ORDI", tokens, []*TokenTransfer{{Address: testAddress}}, utxos, testChangeAddress, nil, nil, privateKey)
+		require.ErrorIs(t, err, ErrInvalidBSV20)
+		_, err = CreateBSV20TransferTx("ORDI", tokens, []*TokenTransfer{
+			{Address: testAddress, Amount: math.MaxUint64}, {Address: testAddress2, Amount: 2},
+		}, utxos, testChangeAddress, nil, nil, privateKey)
+		require.ErrorIs(t, err, ErrInvalidBSV20)
+		_, err = CreateBSV20TransferTx("ORDI", []*TokenUtxo{
+			newTestTokenUtxo("ORDI", math.MaxUint64, 1), newTestTokenUtxo("ORDI", 2, 2),
+		}, transfers, utxos, testChangeAddress, nil, nil, privateKey)
+		require.ErrorIs(t, err, ErrInvalidBSV20)
+		_, err = CreateBSV20TransferTx(strings.ToUpper(testBSV21ID), []*TokenUtxo{newTestTokenUtxo(testBSV21ID, 100, 1)},
+			transfers, utxos, testChangeAddress, nil, nil, privateKey)
+		require.ErrorIs(t, err, ErrTokenMismatch)
+		_, err = CreateBSV20TransferTx("ORDI", tokens, transfers, nil, testChangeAddress, nil, nil, privateKey)
+		require.ErrorIs(t, err, ErrNoFeeUtxos)
+		_, err = CreateBSV20TransferTx("ORDI", tokens, transfers, utxos, "", nil, nil, privateKey)
+		require.ErrorIs(t, err, ErrChangeAddressRequired)
+	})
+}
+
+// TestGetBSV20Outputs will test the method GetBSV20Outputs() with a BSV-21 deploy+mint
+func TestGetBSV20Outputs(t *testing.T) {
+	t.Parallel()
+
+	deploy, err := NewBSV21DeployMint(testAddress, "TKN", 1000, 0, "")
+	require.NoError(t, err)
+	text := &InscriptionOutput{Address: testAddress, Inscription: &Inscription{ContentType: "text/plain", Content: []byte("hello")}}
+	invalid := &InscriptionOutput{Address: testAddress, Inscription: &Inscription{ContentType: BSV20ContentType, Content: []byte(`{}`)}}
+
+	tx, err := CreateInscriptionTx(
+		[]*Utxo{newTestUtxo(1000)},
+		[]*InscriptionOutput{text, invalid, deploy},
+		testChangeAddress, nil, nil, mustTestPrivKey(t),
+	)
+	require.NoError(t, err)
+
+	outputs := GetBSV20Outputs(tx, NetworkMainnet)
+	require.Len(t, outputs, 1)
+	assert.Equal(t, uint32(2), outputs[0].Vout)
+	assert.Equal(t, tx.TxID()+"_2", outputs[0].Token)
+	assert.True(t, isBSV21ID(outputs[0].Token))
+	assert.Equal(t, uint64(1000), outputs[0].BSV20.Amount)
+	assert.Empty(t, GetBSV20Outputs(bt.NewTx(), NetworkMainnet))
+}
+
+// TestIsBSV21ID will test the method isBSV21ID()
+func TestIsBSV21ID(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isBSV21ID(testBSV21ID))
+	assert.True(t, isBSV21ID(strings.Repeat("ab", 32)+"_4294967295"))
+	assert.False(t, isBSV21ID("ORDI"))
+	assert.False(t, isBSV21ID(testTxID))
+	assert.False(t, isBSV21ID(strings.Repeat("zz", 32)+"_0"))
+	assert.False(t, isBSV21ID(strings.Repeat("ab", 32)+"_x"))
+	assert.False(t, isBSV21ID(strings.Repeat("ab", 32)+"_4294967296"))
+}
+
+// ExampleNewBSV20Transfer example using NewBSV20Transfer()
+func ExampleNewBSV20Transfer() {
+	output, err := NewBSV20Transfer("1DfGxKmgL3ETwUdNnXLBueEvNpjcDGcKgK", "ORDI", 100)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("%s: %s", output.Inscription.ContentType, output.Inscription.Content)
+	// Output:application/bsv-20: {"p":"bsv-20","op":"transfer","tick":"ORDI","amt":"100"}
+}
+
+// BenchmarkParseBSV20 benchmarks the method ParseBSV20()
+func BenchmarkParseBSV20(b *testing.B) {
+	inscription := &Inscription{
+		ContentType: BSV20ContentType,
+		Content:     []byte(`{"p":"bsv-20","op":"transfer","tick":"ORDI","amt":"100"}`),
+	}
+	for b.Loop() {
+		_, _ = ParseBSV20(inscription)
+	}
+}
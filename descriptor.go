@@ -0,0 +1,438 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bsv-blockchain/go-bt/v2/bscript"
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+)
+
+// ErrInvalidDescriptor is returned when an output descriptor cannot be parsed
+var ErrInvalidDescriptor = errors.New("invalid descriptor")
+
+const (
+	// descriptorInputCharset is the character set of descriptors (BIP-380)
+	descriptorInputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+
+	// descriptorChecksumCharset is the character set of descriptor checksums
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	// descriptorChecksumLength is the length of a descriptor checksum
+	descriptorChecksumLength = 8
+
+	// maxMultiKeys is the maximum number of keys in multi() and sortedmulti()
+	maxMultiKeys = 16
+)
+
+// DescriptorType is the script type of an output descriptor
+type DescriptorType uint8
+
+const (
+	// DescriptorPKH is pkh(KEY): pay to public key hash (P2PKH)
+	DescriptorPKH DescriptorType = iota
+
+	// DescriptorPK is pk(KEY): pay to a bare public key (P2PK)
+	DescriptorPK
+
+	// DescriptorMulti is multi(k,KEY,...): bare k-of-n multisig, keys in order
+	DescriptorMulti
+
+	// DescriptorSortedMulti is sortedmulti(k,KEY,...): bare k-of-n multisig,
+	// keys sorted by their serialization
+	DescriptorSortedMulti
+)
+
+// String returns the descriptor function name
+func (t DescriptorType) String() string {
+	switch t {
+	case DescriptorPKH:
+		return "pkh"
+	case DescriptorPK:
+		return "pk"
+	case DescriptorMulti:
+		return "multi"
+	case DescriptorSortedMulti:
+		return "sortedmulti"
+	}
+	return "unknown"
+}
+
+// DescriptorKey is a key expression of a descriptor: a hex public key, or an
+// extended key with a derivation path that may end in a range (/* or /*')
+type DescriptorKey struct {
+	Origin        *KeyOrigin
+	PubKey        *ec.PublicKey      // Set for hex public keys and non-ranged extended keys
+	ExtendedKey   *bip32.ExtendedKey // Set for extended keys (xpub/xprv/tpub/tprv)
+	Path          []uint32           // Derivation path after the extended key (without the range)
+	Ranged        bool               // The path ends in /*
+	HardenedRange bool               // The path ends in /*' (requires an xprv)
+
+	compressed bool
+	key        string             // The key as written (hex or base58)
+	base       *bip32.ExtendedKey // ExtendedKey derived along Path
+}
+
+// Descriptor is an output descriptor like "pkh([d34db33f/44'/0'/0']xpub.../0/*)"
+//
+// Only the script types usable on BSV are supported: pkh(), pk(), multi() and sortedmulti().
+type Descriptor struct {
+	Type      DescriptorType
+	Threshold int // Required signatures for multi() and sortedmulti()
+	Keys      []*DescriptorKey
+}
+
+// ParseDescriptor will parse an output descriptor, verifying its checksum if
+// it has one (descriptor#checksum)
+func ParseDescriptor(descriptor string) (*Descriptor, error) {
+	// Verify the checksum
+	if body, checksum, found := strings.Cut(descriptor, "#"); found {
+		expected, err := DescriptorChecksum(body)
+		if err != nil {
+			return nil, err
+		} else if checksum != expected {
+			return nil, fmt.Errorf("%w: descriptor checksum %q, expected %q", ErrChecksumMismatch, checksum, expected)
+		}
+		descriptor = body
+	}
+
+	name, args, found := strings.Cut(descriptor, "(")
+	if !found || !strings.HasSuffix(args, ")") {
+		return nil, fmt.Errorf("%w: expected function(arguments): %q", ErrInvalidDescriptor, descriptor)
+	}
+	params := strings.Split(args[:len(args)-1], ",")
+
+	d := new(Descriptor)
+	switch name {
+	case "pkh", "pk":
+		d.Type = DescriptorPKH
+		if name == "pk" {
+			d.Type = DescriptorPK
+		}
+		if len(params) != 1 {
+			return nil, fmt.Errorf("%w: %s() takes a single key", ErrInvalidDescriptor, name)
+		}
+	case "multi", "sortedmulti":
+		d.Type = DescriptorMulti
+		if name == "sortedmulti" {
+			d.Type = DescriptorSortedMulti
+		}
+		threshold, err := strconv.Atoi(params[0])
+		params = params[1:]
+		if err != nil || threshold < 1 || threshold > len(params) || len(params) > maxMultiKeys {
+			return nil, fmt.Errorf("%w: %s() requires 1 <= k <= n <= %d", ErrInvalidDescriptor, name, maxMultiKeys)
+		}
+		d.Threshold = threshold
+	default:
+		return nil, fmt.Errorf("%w: unsupported function %q", ErrInvalidDescriptor, name)
+	}
+
+	for _, param := range params {
+		key, err := parseDescriptorKey(param)
+		if err != nil {
+			return nil, err
+		}
+		d.Keys = append(d.Keys, key)
+	}
+	return d, nil
+}
+
+// DescriptorChecksum will compute the 8 character checksum of a descriptor (BIP-380)
+func DescriptorChecksum(descriptor string) (string, error) {
+	c := uint64(1)
+	var class, classCount int
+	for _, ch := range descriptor {
+		pos := strings.IndexRune(descriptorInputCharset, ch)
+		if pos < 0 {
+			return "", fmt.Errorf("%w: invalid character %q", ErrInvalidDescriptor, ch)
+		}
+		c = descriptorPolyMod(c, uint64(pos&31)) // #nosec G115 -- pos < 95
+		class = class*3 + pos>>5
+		if classCount++; classCount == 3 {
+			c = descriptorPolyMod(c, uint64(class)) // #nosec G115 -- class < 27
+			class, classCount = 0, 0
+		}
+	}
+	if classCount > 0 {
+		c = descriptorPolyMod(c, uint64(class)) // #nosec G115 -- class < 27
+	}
+	for range descriptorChecksumLength {
+		c = descriptorPolyMod(c, 0)
+	}
+	c ^= 1
+
+	checksum := make([]byte, descriptorChecksumLength)
+	for j := range checksum {
+		checksum[j] = descriptorChecksumCharset[(c>>(5*(7-j)))&31]
+	}
+	return string(checksum), nil
+}
+
+// String returns the descriptor with its checksum
+func (d *Descriptor) String() string {
+	var b strings.Builder
+	b.WriteString(d.Type.String())
+	b.WriteByte('(')
+	if d.Type == DescriptorMulti || d.Type == DescriptorSortedMulti {
+		b.WriteString(strconv.Itoa(d.Threshold))
+		b.WriteByte(',')
+	}
+	for i, key := range d.Keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(key.String())
+	}
+	b.WriteByte(')')
+
+	// Descriptors only contain charset characters, so the checksum cannot fail
+	checksum, _ := DescriptorChecksum(b.String())
+	return b.String() + "#" + checksum
+}
+
+// IsRange returns true if the descriptor has a ranged key (/*)
+func (d *Descriptor) IsRange() bool {
+	return slices.ContainsFunc(d.Keys, func(key *DescriptorKey) bool { return key.Ranged })
+}
+
+// Script will get the locking script (hex) of the descriptor at the index
+//
+// The index is ignored by descriptors without a ranged key; ranged keys return
+// ErrInvalidRange for an index of 0x80000000 (bip32.HardenedKeyStart) or more.
+func (d *Descriptor) Script(index uint32) (string, error) {
+	if err := d.validate(); err != nil {
+		return "", err
+	}
+
+	pubKeys := make([][]byte, len(d.Keys))
+	for i, key := range d.Keys {
+		pubKey, err := key.PubKeyAt(index)
+		if err != nil {
+			return "", err
+		}
+
+		// P2PKH scripts are built from the address
+		if d.Type == DescriptorPKH {
+			var address *bscript.Address
+			if address, err = GetAddressFromPubKey(pubKey, key.compressed, true); err != nil {
+				return "", err
+			}
+			return ScriptFromAddress(address.AddressString)
+		}
+
+		if key.compressed {
+			pubKeys[i] = pubKey.Compressed()
+		} else {
+			pubKeys[i] = pubKey.Uncompressed()
+		}
+	}
+
+	switch d.Type {
+	case DescriptorPK:
+		script := appendPushData(nil, pubKeys[0])
+		return hex.EncodeToString(append(script, bscript.OpCHECKSIG)), nil
+	case DescriptorSortedMulti:
+		slices.SortFunc(pubKeys, bytes.Compare)
+		fallthrough
+	case DescriptorMulti:
+		script := []byte{smallIntOpcode(d.Threshold)}
+		for _, pubKey := range pubKeys {
+			script = appendPushData(script, pubKey)
+		}
+		script = append(script, smallIntOpcode(len(pubKeys)), bscript.OpCHECKMULTISIG)
+		return hex.EncodeToString(script), nil
+	case DescriptorPKH:
+	}
+	return "", fmt.Errorf("%w: unsupported type %s", ErrInvalidDescriptor, d.Type)
+}
+
+// Scripts will get the locking scripts (hex) of the descriptor for every index in [from, to)
+func (d *Descriptor) Scripts(from, to uint32) ([]string, error) {
+	if from > to || to > bip32.HardenedKeyStart {
+		return nil, ErrInvalidRange
+	}
+	scripts := make([]string, 0, to-from)
+	for index := from; index < to; index++ {
+		script, err := d.Script(index)
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, script)
+	}
+	return scripts, nil
+}
+
+// validate checks the number of keys (and the threshold) for the type, and
+// that every key can produce a public key.
+func (d *Descriptor) validate() error {
+	switch d.Type {
+	case DescriptorPKH, DescriptorPK:
+		if len(d.Keys) != 1 {
+			return fmt.Errorf("%w: %s() takes a single key, has %d", ErrInvalidDescriptor, d.Type, len(d.Keys))
+		}
+	case DescriptorMulti, DescriptorSortedMulti:
+		if d.Threshold < 1 || d.Threshold > len(d.Keys) || len(d.Keys) > maxMultiKeys {
+			return fmt.Errorf("%w: %s() requires 1 <= k <= n <= %d", ErrInvalidDescriptor, d.Type, maxMultiKeys)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported type %s", ErrInvalidDescriptor, d.Type)
+	}
+
+	for _, key := range d.Keys {
+		if key == nil || (key.Ranged && key.base == nil) || (!key.Ranged && key.PubKey == nil) {
+			return fmt.Errorf("%w: missing public key", ErrInvalidDescriptor)
+		}
+	}
+	return nil
+}
+
+// PubKeyAt will get the public key of the key expression at the index
+//
+// The index is ignored by keys that are not ranged; ranged keys take an index
+// below 0x80000000 (bip32.HardenedKeyStart), even for a hardened range (/*').
+func (k *DescriptorKey) PubKeyAt(index uint32) (*ec.PublicKey, error) {
+	if !k.Ranged {
+		return k.PubKey, nil
+	} else if index >= bip32.HardenedKeyStart {
+		return nil, fmt.Errorf("%w: index %d", ErrInvalidRange, index)
+	}
+	if k.HardenedRange {
+		index += bip32.HardenedKeyStart
+	}
+	child, err := GetHDKeyChild(k.base, index)
+	if err != nil {
+		return nil, err
+	}
+	return child.ECPubKey()
+}
+
+// String returns the key expression as written, like "[d34db33f/44'/0'/0']xpub.../0/*"
+func (k *DescriptorKey) String() string {
+	var b strings.Builder
+	if k.Origin != nil {
+		b.WriteString("[" + k.Origin.String() + "]")
+	}
+	b.WriteString(k.key)
+	b.WriteString(formatPath(k.Path))
+	if k.Ranged {
+		b.WriteString("/*")
+		if k.HardenedRange {
+			b.WriteByte('\'')
+		}
+	}
+	return b.String()
+}
+
+// parseDescriptorKey parses a key expression: [origin]KEY/path/*
+func parseDescriptorKey(param string) (*DescriptorKey, error) {
+	key := &DescriptorKey{compressed: true}
+	if strings.HasPrefix(param, "[") {
+		end := strings.IndexByte(param, ']')
+		if end < 0 {
+			return nil, fmt.Errorf("%w: missing ] in %q", ErrInvalidDescriptor, param)
+		}
+		origin, err := ParseKeyOrigin(param[1:end])
+		if err != nil {
+			return nil, err
+		}
+		key.Origin = &origin
+		param = param[end+1:]
+	}
+
+	parts := strings.Split(param, "/")
+	key.key = parts[0]
+
+	// A hex public key (compressed or uncompressed)
+	if len(key.key) == 66 || len(key.key) == 130 {
+		if len(parts) > 1 {
+			return nil, fmt.Errorf("%w: a public key cannot have a path: %q", ErrInvalidDescriptor, param)
+		}
+		pubKey, err := PubKeyFromString(key.key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidDescriptor, err)
+		}
+		key.PubKey = pubKey
+		key.compressed = len(key.key) == 66
+		return key, nil
+	}
+
+	// An extended key with a path
+	hdKey, err := bip32.NewKeyFromString(key.key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDescriptor, err)
+	}
+	key.ExtendedKey = hdKey
+	path := parts[1:]
+	if n := len(path); n > 0 {
+		switch path[n-1] {
+		case "*":
+			key.Ranged, path = true, path[:n-1]
+		case "*'", "*h":
+			key.Ranged, key.HardenedRange, path = true, true, path[:n-1]
+		}
+	}
+	for _, part := range path {
+		var index uint32
+		if index, err = parsePathIndex(part); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidDescriptor, err)
+		}
+		key.Path = append(key.Path, index)
+	}
+
+	// Derive the fixed part of the path once
+	key.base = hdKey
+	for _, index := range key.Path {
+		if key.base, err = GetHDKeyChild(key.base, index); err != nil {
+			return nil, err
+		}
+	}
+	if key.HardenedRange && !key.base.IsPrivate() {
+		return nil, bip32.ErrDeriveHardFromPublic
+	}
+	if !key.Ranged {
+		if key.PubKey, err = key.base.ECPubKey(); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// descriptorPolyMod is the BCH code generator of descriptor checksums (BIP-380).
+func descriptorPolyMod(c, value uint64) uint64 {
+	c0 := c >> 35
+	c = ((c & 0x7ffffffff) << 5) ^ value
+	if c0&1 != 0 {
+		c ^= 0xf5dee51989
+	}
+	if c0&2 != 0 {
+		c ^= 0xa9fdca3312
+	}
+	if c0&4 != 0 {
+		c ^= 0x1bab10e32d
+	}
+	if c0&8 != 0 {
+		c ^= 0x3706b1677a
+	}
+	if c0&16 != 0 {
+		c ^= 0x644d626ffd
+	}
+	return c
+}
+
+// appendPushData appends a push of data (up to 75 bytes) to the script.
+func appendPushData(script, data []byte) []byte {
+	return append(append(script, byte(len(data))), data...) // #nosec G115 -- public keys are 33 or 65 bytes
+}
+
+// smallIntOpcode returns the opcode pushing n (1 to 16).
+func smallIntOpcode(n int) byte {
+	return bscript.Op1 + byte(n-1) // #nosec G115 -- 1 <= n <= 16
+}
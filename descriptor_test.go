@@ -0,0 +1,291 @@
+package bitcoin
+
+import (
+	"fmt"
+	"testing"
+
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// testDescriptorXPub is the account xPub of the descriptor examples (Bitcoin Core doc/descriptors.md)
+	testDescriptorXPub = "xpub6ERApfZwUNrhLCkDtcHTcxd75RbzS1ed54G1LkBUHQVHQKqhMkhgbmJbZRkrgZw4koxb5JaHWkY4ALHY2grBGRjaDMzQLcgJvLJuZZvRcEL"
+
+	// testPubKeyG and testPubKey2G are the public keys of the private keys 1 and 2
+	testPubKeyG  = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	testPubKey2G = "02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5"
+)
+
+// TestDescriptorChecksum will test the method DescriptorChecksum()
+func TestDescriptorChecksum(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		descriptor string
+		expected   string
+	}{
+		{"raw(deadbeef)", "89f8spxm"},
+		{"pkh([d34db33f/44'/0'/0']" + testDescriptorXPub + "/1/*)", "ml40v0wf"},
+		{"pk(" + testPubKeyG + ")", "gn28ywm7"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.descriptor, func(t *testing.T) {
+			t.Parallel()
+			checksum, err := DescriptorChecksum(test.descriptor)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, checksum)
+		})
+	}
+
+	_, err := DescriptorChecksum("pk(é)")
+	require.ErrorIs(t, err, ErrInvalidDescriptor)
+}
+
+// TestParseDescriptor will test the method ParseDescriptor() and Descriptor.String()
+func TestParseDescriptor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		input     string
+		expected  DescriptorType
+		threshold int
+		keys      int
+		ranged    bool
+	}{
+		{"pkh ranged with origin", "pkh([d34db33f/44'/0'/0']" + testDescriptorXPub + "/1/*)#ml40v0wf", DescriptorPKH, 0, 1, true},
+		{"pkh hex key", "pkh(" + testPubKeyG + ")", DescriptorPKH, 0, 1, false},
+		{"pk hex key", "pk(" + testPubKeyG + ")#gn28ywm7", DescriptorPK, 0, 1, false},
+		{"pk xpub path", "pk(" + testDescriptorXPub + "/0/7)", DescriptorPK, 0, 1, false},
+		{"multi", "multi(1," + testPubKeyG + "," + testPubKey2G + ")", DescriptorMulti, 1, 2, false},
+		{"sortedmulti ranged", "sortedmulti(2," + testDescriptorXPub + "/0/*," + testPubKey2G + ")", DescriptorSortedMulti, 2, 2, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			descriptor, err := ParseDescriptor(test.input)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, descriptor.Type)
+			assert.Equal(t, test.threshold, descriptor.Threshold)
+			assert.Len(t, descriptor.Keys, test.keys)
+			assert.Equal(t, test.ranged, descriptor.IsRange())
+
+			// String() round trips with a checksum
+			reparsed, err := ParseDescriptor(descriptor.String())
+			require.NoError(t, err)
+			assert.Equal(t, descriptor.String(), reparsed.String())
+		})
+	}
+}
+
+// TestParseDescriptorErrors will test the error cases of ParseDescriptor()
+func TestParseDescriptorErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected error
+	}{
+		{"bad checksum", "pk(" + testPubKeyG + ")#gn28ywm8", ErrChecksumMismatch},
+		{"invalid checksum character", "pk(é)#gn28ywm7", ErrInvalidDescriptor},
+		{"no function", testPubKeyG, ErrInvalidDescriptor},
+		{"missing )", "pk(" + testPubKeyG, ErrInvalidDescriptor},
+		{"unsupported function", "wpkh(" + testPubKeyG + ")", ErrInvalidDescriptor},
+		{"two keys in pkh", "pkh(" + testPubKeyG + "," + testPubKey2G + ")", ErrInvalidDescriptor},
+		{"threshold too high", "multi(3," + testPubKeyG + "," + testPubKey2G + ")", ErrInvalidDescriptor},
+		{"threshold zero", "multi(0," + testPubKeyG + ")", ErrInvalidDescriptor},
+		{"threshold not a number", "multi(x," + testPubKeyG + ")", ErrInvalidDescriptor},
+		{"invalid hex key", "pk(zz" + testPubKeyG[2:] + ")", ErrInvalidDescriptor},
+		{"hex key with path", "pk(" + testPubKeyG + "/0)", ErrInvalidDescriptor},
+		{"invalid extended key", "pk(xpub-invalid/0)", ErrInvalidDescriptor},
+		{"invalid path", "pk(" + testDescriptorXPub + "/x/*)", ErrInvalidDescriptor},
+		{"missing ] in origin", "pk([d34db33f/44'" + testDescriptorXPub + ")", ErrInvalidDescriptor},
+		{"invalid origin", "pk([d34db3/44']" + testDescriptorXPub + ")", ErrInvalidKeyOrigin},
+		{"hardened path from xpub", "pk(" + testDescriptorXPub + "/0'/*)", bip32.ErrDeriveHardFromPublic},
+		{"hardened range from xpub", "pk(" + testDescriptorXPub + "/0/*')", bip32.ErrDeriveHardFromPublic},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			descriptor, err := ParseDescriptor(test.input)
+			require.ErrorIs(t, err, test.expected)
+			assert.Nil(t, descriptor)
+		})
+	}
+}
+
+// TestDescriptorScripts will test the methods Descriptor.Script() and Descriptor.Scripts()
+func TestDescriptorScripts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pkh ranged matches the HD helpers", func(t *testing.T) {
+		t.Parallel()
+		descriptor, err := ParseDescriptor("pkh([d34db33f/44'/0'/0']" + testDescriptorXPub + "/1/*)#ml40v0wf")
+		require.NoError(t, err)
+		scripts, err := descriptor.Scripts(0, 10)
+		require.NoError(t, err)
+
+		accountKey, err := GetHDKeyFromExtendedPublicKey(testDescriptorXPub)
+		require.NoError(t, err)
+		keys, err := DeriveRange(accountKey, 1, 0, 10, true)
+		require.NoError(t, err)
+		for i, key := range keys {
+			assert.Equal(t, key.Script, scripts[i])
+		}
+	})
+
+	t.Run("pkh hex key", func(t *testing.T) {
+		t.Parallel()
+		descriptor, err := ParseDescriptor("pkh(" + testPubKeyG + ")")
+		require.NoError(t, err)
+		script, err := descriptor.Script(0)
+		require.NoError(t, err)
+		assert.Equal(t, "76a914751e76e8199196d454941c45d1b3a323f1433bd688ac", script)
+
+		// Not ranged, so every index is the same script
+		scripts, err := descriptor.Scripts(0, 3)
+		require.NoError(t, err)
+		assert.Equal(t, []string{script, script, script}, scripts)
+	})
+
+	t.Run("pkh uncompressed key", func(t *testing.T) {
+		t.Parallel()
+		pubKey, err := PubKeyFromString(testPubKeyG)
+		require.NoError(t, err)
+		uncompressed := fmt.Sprintf("%x", pubKey.Uncompressed())
+		descriptor, err := ParseDescriptor("pkh(" + uncompressed + ")")
+		require.NoError(t, err)
+		script, err := descriptor.Script(0)
+		require.NoError(t, err)
+		assert.Equal(t, "76a91491b24bf9f5288532960ac687abb035127b1d28a588ac", script)
+	})
+
+	t.Run("pk", func(t *testing.T) {
+		t.Parallel()
+		descriptor, err := ParseDescriptor("pk(" + testPubKeyG + ")")
+		require.NoError(t, err)
+		script, err := descriptor.Script(0)
+		require.NoError(t, err)
+		assert.Equal(t, "21"+testPubKeyG+"ac", script)
+	})
+
+	t.Run("multi keeps the key order", func(t *testing.T) {
+		t.Parallel()
+		descriptor, err := ParseDescriptor("multi(1," + testPubKey2G + "," + testPubKeyG + ")")
+		require.NoError(t, err)
+		script, err := descriptor.Script(0)
+		require.NoError(t, err)
+		assert.Equal(t, "5121"+testPubKey2G+"21"+testPubKeyG+"52ae", script)
+	})
+
+	t.Run("sortedmulti sorts the keys", func(t *testing.T) {
+		t.Parallel()
+		descriptor, err := ParseDescriptor("sortedmulti(2," + testPubKey2G + "," + testPubKeyG + ")")
+		require.NoError(t, err)
+		script, err := descriptor.Script(0)
+		require.NoError(t, err)
+		assert.Equal(t, "5221"+testPubKeyG+"21"+testPubKey2G+"52ae", script)
+	})
+
+	t.Run("hardened range from xprv", func(t *testing.T) {
+		t.Parallel()
+		masterKey, err := bip32.NewKeyFromString(testBIP32Master)
+		require.NoError(t, err)
+		descriptor, err := ParseDescriptor("pkh(" + testBIP32Master + "/0'/*')")
+		require.NoError(t, err)
+		script, err := descriptor.Script(3)
+		require.NoError(t, err)
+
+		child, err := GetHDKeyByPath(masterKey, bip32.HardenedKeyStart, bip32.HardenedKeyStart+3)
+		require.NoError(t, err)
+		address, err := GetAddressFromHDKey(child, true)
+		require.NoError(t, err)
+		expected, err := ScriptFromAddress(address.AddressString)
+		require.NoError(t, err)
+		assert.Equal(t, expected, script)
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		t.Parallel()
+		descriptor, err := ParseDescriptor("pkh(" + testDescriptorXPub + "/0/*)")
+		require.NoError(t, err)
+		_, err = descriptor.Scripts(5, 4)
+		require.ErrorIs(t, err, ErrInvalidRange)
+		_, err = descriptor.Scripts(0, bip32.HardenedKeyStart+1)
+		require.ErrorIs(t, err, ErrInvalidRange)
+
+		// Hardened indexes would derive another child of a /* or /*' range
+		for _, expression := range []string{
+			"pkh(" + testDescriptorXPub + "/0/*)",
+			"pkh(" + testBIP32Master + "/0'/*')",
+		} {
+			descriptor, err = ParseDescriptor(expression)
+			require.NoError(t, err)
+			_, err = descriptor.Script(bip32.HardenedKeyStart)
+			require.ErrorIs(t, err, ErrInvalidRange)
+			_, err = descriptor.Keys[0].PubKeyAt(bip32.HardenedKeyStart + 3)
+			require.ErrorIs(t, err, ErrInvalidRange)
+		}
+	})
+
+	t.Run("invalid descriptors built from fields", func(t *testing.T) {
+		t.Parallel()
+		pubKey, err := PubKeyFromString(testPubKeyG)
+		require.NoError(t, err)
+		key := &DescriptorKey{PubKey: pubKey}
+
+		for _, descriptor := range []*Descriptor{
+			{Type: DescriptorPK},
+			{Type: DescriptorPKH},
+			{Type: DescriptorPKH, Keys: []*DescriptorKey{key, key}},
+			{Type: DescriptorMulti, Threshold: 2, Keys: []*DescriptorKey{key}},
+			{Type: DescriptorSortedMulti, Keys: []*DescriptorKey{key}},
+			{Type: DescriptorPK, Keys: []*DescriptorKey{nil}},
+			{Type: DescriptorPK, Keys: []*DescriptorKey{{}}},
+			{Type: DescriptorPK, Keys: []*DescriptorKey{{Ranged: true}}},
+			{Type: DescriptorType(99), Keys: []*DescriptorKey{key}},
+		} {
+			_, err = descriptor.Script(0)
+			require.ErrorIs(t, err, ErrInvalidDescriptor)
+		}
+
+		script, err := (&Descriptor{Type: DescriptorMulti, Threshold: 1, Keys: []*DescriptorKey{key}}).Script(0)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("5141%x51ae", pubKey.Uncompressed()), script)
+	})
+}
+
+// ExampleParseDescriptor example using ParseDescriptor()
+func ExampleParseDescriptor() {
+	descriptor, err := ParseDescriptor("pkh([d34db33f/44'/0'/0']" + testDescriptorXPub + "/1/*)#ml40v0wf")
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	var scripts []string
+	if scripts, err = descriptor.Scripts(0, 2); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	for index, script := range scripts {
+		fmt.Printf("%d: %s\n", index, script)
+	}
+	// Output:
+	// 0: 76a9142a05c214617c9b0434c92d0583200a85ef61818f88ac
+	// 1: 76a91449b2f81eea1ecb5bc97d78f2d8f89d9c861c3cf288ac
+}
+
+// BenchmarkDescriptorScript benchmarks the method Descriptor.Script()
+func BenchmarkDescriptorScript(b *testing.B) {
+	descriptor, _ := ParseDescriptor("pkh(" + testDescriptorXPub + "/1/*)")
+	for b.Loop() {
+		_, _ = descriptor.Script(42)
+	}
+}
@@ -1,15 +1,110 @@
 package bitcoin
 
 import (
+	"bytes"
 	"encoding/hex"
+	"errors"
 
 	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
 )
 
+// ErrUnknownEncryptionFormat is returned when an encrypted payload does not
+// match any of the supported wire formats
+var ErrUnknownEncryptionFormat = errors.New("unknown encryption format")
+
+// EncryptionFormat identifies the wire format of an encrypted payload
+type EncryptionFormat uint8
+
+const (
+	// EncryptionFormatUnknown is returned when the payload is not recognized
+	EncryptionFormatUnknown EncryptionFormat = iota
+
+	// EncryptionFormatLegacy is the Pyelliptic / ANSI-X9.63 AES-CBC+HMAC format
+	// produced by EncryptWithPrivateKey and EncryptShared
+	EncryptionFormatLegacy
+
+	// EncryptionFormatBRC78 is the BRC-78 AES-GCM format produced by EncryptBRC78
+	EncryptionFormatBRC78
+
+	// EncryptionFormatBIE1 is the Electrum compatible format produced by EncryptBIE1
+	EncryptionFormatBIE1
+)
+
+// legacyMinLength is IV + Curve params/X/Y + 1 block + HMAC-256
+const legacyMinLength = 16 + 70 + 16 + 32
+
+// String returns the name of the encryption format
+func (f EncryptionFormat) String() string {
+	switch f {
+	case EncryptionFormatLegacy:
+		return "legacy"
+	case EncryptionFormatBRC78:
+		return "brc78"
+	case EncryptionFormatBIE1:
+		return "bie1"
+	case EncryptionFormatUnknown:
+		return "unknown"
+	}
+	return "unknown"
+}
+
+// DetectEncryptionFormat inspects a raw (not hex encoded) encrypted payload and
+// returns the wire format it was produced with
+//
+// The legacy format is identified by its fixed curve and coordinate length
+// markers following the IV, the BRC-78 format by its version prefix and the
+// BIE1 format by its magic bytes.
+func DetectEncryptionFormat(data []byte) EncryptionFormat {
+	if len(data) >= legacyMinLength &&
+		bytes.Equal(data[16:18], ciphCurveBytes[:]) &&
+		bytes.Equal(data[18:20], ciphCoordLength[:]) &&
+		bytes.Equal(data[52:54], ciphCoordLength[:]) {
+		return EncryptionFormatLegacy
+	}
+	if len(data) >= brc78MinLength && bytes.Equal(data[:4], brc78Version[:]) {
+		return EncryptionFormatBRC78
+	}
+	if len(data) >= bie1MinLength && bytes.Equal(data[:4], bie1Magic[:]) {
+		return EncryptionFormatBIE1
+	}
+	return EncryptionFormatUnknown
+}
+
+// DetectEncryptionFormatString is a convenience wrapper for DetectEncryptionFormat()
+// that accepts a hex encoded payload
+func DetectEncryptionFormatString(data string) EncryptionFormat {
+	rawData, err := hex.DecodeString(data)
+	if err != nil {
+		return EncryptionFormatUnknown
+	}
+	return DetectEncryptionFormat(rawData)
+}
+
 // EncryptWithPrivateKey will encrypt the data using a given private key
 func EncryptWithPrivateKey(privateKey *ec.PrivateKey, data string) (string, error) {
-	// Encrypt using ec
-	encryptedData, err := eciesEncrypt(privateKey.PubKey(), []byte(data))
+	return EncryptWithPrivateKeyFormat(privateKey, data, EncryptionFormatLegacy)
+}
+
+// EncryptWithPrivateKeyFormat will encrypt the data to the given private key
+// (hex encoded) using the chosen wire format
+//
+// The result can be decrypted with DecryptWithPrivateKey, which detects the format
+func EncryptWithPrivateKeyFormat(privateKey *ec.PrivateKey, data string, format EncryptionFormat) (string, error) {
+	// Encrypt using the chosen format (panics on a nil key, like the legacy format)
+	var encryptedData []byte
+	var err error
+	switch format {
+	case EncryptionFormatLegacy:
+		encryptedData, err = eciesEncrypt(privateKey.PubKey(), []byte(data))
+	case EncryptionFormatBRC78:
+		encryptedData, err = EncryptBRC78(privateKey, privateKey.PubKey(), []byte(data))
+	case EncryptionFormatBIE1:
+		encryptedData, err = EncryptBIE1(privateKey.PubKey(), []byte(data), nil, false)
+	case EncryptionFormatUnknown:
+		fallthrough
+	default:
+		err = ErrUnknownEncryptionFormat
+	}
 	if err != nil {
 		return "", err
 	}
@@ -20,6 +115,9 @@ func EncryptWithPrivateKey(privateKey *ec.PrivateKey, data string) (string, erro
 
 // DecryptWithPrivateKey is a wrapper to decrypt the previously encrypted
 // information, given a corresponding private key
+//
+// The wire format is detected automatically (see DetectEncryptionFormat), so
+// legacy, BRC-78 and BIE1 payloads addressed to the key are all accepted
 func DecryptWithPrivateKey(privateKey *ec.PrivateKey, data string) (string, error) {
 	// Decode the hex encoded string
 	rawData, err := hex.DecodeString(data)
@@ -29,22 +127,45 @@ func DecryptWithPrivateKey(privateKey *ec.PrivateKey, data string) (string, erro
 
 	// Decrypt the data
 	var decrypted []byte
-	if decrypted, err = eciesDecrypt(privateKey, rawData); err != nil {
+	if decrypted, err = decryptAnyFormat(privateKey, rawData); err != nil {
 		return "", err
 	}
 	return string(decrypted), nil
 }
 
+// decryptAnyFormat decrypts a raw payload using the wire format detected by
+// DetectEncryptionFormat, falling back to the legacy format
+func decryptAnyFormat(privateKey *ec.PrivateKey, rawData []byte) ([]byte, error) {
+	switch DetectEncryptionFormat(rawData) {
+	case EncryptionFormatBRC78:
+		decrypted, _, err := DecryptBRC78(privateKey, rawData)
+		return decrypted, err
+	case EncryptionFormatBIE1:
+		// Without an embedded ephemeral key the payload was self-encrypted
+		if privateKey != nil && !bie1HasKey(rawData) {
+			return DecryptBIE1(privateKey, rawData, privateKey.PubKey())
+		}
+		return DecryptBIE1(privateKey, rawData, nil)
+	case EncryptionFormatLegacy, EncryptionFormatUnknown:
+	}
+	return eciesDecrypt(privateKey, rawData)
+}
+
 // EncryptWithPrivateKeyString is a convenience wrapper for EncryptWithPrivateKey()
 func EncryptWithPrivateKeyString(privateKey, data string) (string, error) {
+	return EncryptWithPrivateKeyStringFormat(privateKey, data, EncryptionFormatLegacy)
+}
+
+// EncryptWithPrivateKeyStringFormat is a convenience wrapper for EncryptWithPrivateKeyFormat()
+func EncryptWithPrivateKeyStringFormat(privateKey, data string, format EncryptionFormat) (string, error) {
 	// Get the private key from string
 	rawPrivateKey, err := PrivateKeyFromString(privateKey)
 	if err != nil {
 		return "", err
 	}
 
-	// Encrypt using bec
-	return EncryptWithPrivateKey(rawPrivateKey, data)
+	// Encrypt using the chosen format
+	return EncryptWithPrivateKeyFormat(rawPrivateKey, data, format)
 }
 
 // DecryptWithPrivateKeyString is a convenience wrapper for DecryptWithPrivateKey()
@@ -565,3 +565,56 @@ func TestEncryptSharedStringPanic(t *testing.T) {
 }
 
 // todo: examples and benchmark for EncryptSharedString()
+
+// TestDetectEncryptionFormat will test the method DetectEncryptionFormat()
+func TestDetectEncryptionFormat(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := CreatePrivateKey()
+	require.NoError(t, err)
+
+	legacy, err := eciesEncrypt(privateKey.PubKey(), []byte(testEncryptionMessage))
+	require.NoError(t, err)
+
+	brc78, err := EncryptBRC78(privateKey, privateKey.PubKey(), []byte(testEncryptionMessage))
+	require.NoError(t, err)
+
+	bie1, err := EncryptBIE1(privateKey.PubKey(), []byte(testEncryptionMessage), nil, false)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		input    []byte
+		expected EncryptionFormat
+	}{
+		{"nil", nil, EncryptionFormatUnknown},
+		{caseEmpty, []byte{}, EncryptionFormatUnknown},
+		{"random bytes", []byte("not an encrypted payload"), EncryptionFormatUnknown},
+		{"legacy", legacy, EncryptionFormatLegacy},
+		{"brc78", brc78, EncryptionFormatBRC78},
+		{"brc78 header only", brc78[:brc78HeaderLength], EncryptionFormatUnknown},
+		{"bie1", bie1, EncryptionFormatBIE1},
+		{"bie1 magic only", []byte("BIE1"), EncryptionFormatUnknown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.expected, DetectEncryptionFormat(test.input))
+		})
+	}
+
+	assert.Equal(t, EncryptionFormatUnknown, DetectEncryptionFormatString("zz"))
+	assert.Equal(t, EncryptionFormatLegacy, DetectEncryptionFormatString(hex.EncodeToString(legacy)))
+}
+
+// TestEncryptionFormatString will test the method EncryptionFormat.String()
+func TestEncryptionFormatString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "unknown", EncryptionFormatUnknown.String())
+	assert.Equal(t, "legacy", EncryptionFormatLegacy.String())
+	assert.Equal(t, "brc78", EncryptionFormatBRC78.String())
+	assert.Equal(t, "bie1", EncryptionFormatBIE1.String())
+	assert.Equal(t, "unknown", EncryptionFormat(255).String())
+}
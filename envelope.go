@@ -0,0 +1,347 @@
+package bitcoin
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"slices"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+)
+
+// This file adds multi-recipient envelopes: the payload is encrypted once with
+// a random content key (AES-256-GCM) and the content key is wrapped for every
+// recipient public key using eciesEncrypt. Recipients can be added by anyone
+// who can open the envelope, and removed without touching the payload. The
+// structure it encodes everything into is:
+//
+//	struct {
+//		Magic [4]byte               // "ENV1"
+//		Count [2]byte               // number of recipients (big endian)
+//		Recipients []struct {
+//			PublicKey [33]byte      // compressed recipient key
+//			Length [2]byte          // wrapped key length (big endian)
+//			WrappedKey []byte       // eciesEncrypt(PublicKey, content key)
+//		}
+//		Nonce [12]byte              // AES-256-GCM nonce
+//		Data []byte                 // cipher text + GCM tag
+//	}
+
+var (
+	// ErrEnvelopeNoRecipients is returned when an envelope would have no recipients
+	ErrEnvelopeNoRecipients = errors.New("envelope requires at least one recipient")
+
+	// ErrEnvelopeNotRecipient is returned when a key has no slot in the envelope
+	ErrEnvelopeNotRecipient = errors.New("key is not a recipient of the envelope")
+
+	// ErrEnvelopeMalformed is returned when a serialized envelope cannot be parsed
+	ErrEnvelopeMalformed = errors.New("malformed envelope")
+
+	// ErrEnvelopeTooManyRecipients is returned when adding more recipients than
+	// the wire format can hold
+	ErrEnvelopeTooManyRecipients = errors.New("too many envelope recipients")
+)
+
+const (
+	// envelopeKeyLength is the length of the random content key (AES-256)
+	envelopeKeyLength = 32
+
+	// envelopeNonceLength is the length of the AES-256-GCM nonce
+	envelopeNonceLength = 12
+
+	// envelopeMaxRecipients is the most recipients an envelope can hold
+	envelopeMaxRecipients = 0xffff
+)
+
+// envelopeMagic is the envelope wire-format magic prefix.
+//
+//nolint:gochecknoglobals // fixed envelope wire-format marker; byte arrays cannot be const
+var envelopeMagic = [4]byte{'E', 'N', 'V', '1'}
+
+// EnvelopeRecipient is a recipient slot holding the content key wrapped for
+// the recipient's public key
+type EnvelopeRecipient struct {
+	PubKey     *ec.PublicKey
+	WrappedKey []byte
+}
+
+// Envelope is a payload encrypted once for many recipients
+type Envelope struct {
+	Recipients []*EnvelopeRecipient
+	Nonce      []byte
+	Data       []byte
+}
+
+// NewEnvelope will encrypt the data for all the recipient public keys
+//
+// If sender is not nil, the sender's public key is added as a recipient so the
+// sender can open the envelope later. Duplicate keys get a single slot.
+func NewEnvelope(data []byte, recipients []*ec.PublicKey, sender *ec.PrivateKey) (*Envelope, error) {
+	if sender != nil {
+		recipients = append(append([]*ec.PublicKey{}, recipients...), sender.PubKey())
+	}
+	if len(recipients) == 0 {
+		return nil, ErrEnvelopeNoRecipients
+	}
+
+	// Generate the content key and seal the payload
+	contentKey := make([]byte, envelopeKeyLength)
+	if _, err := io.ReadFull(rand.Reader, contentKey); err != nil {
+		return nil, err
+	}
+	envelope := &Envelope{}
+	if err := envelope.seal(contentKey, data); err != nil {
+		return nil, err
+	}
+
+	// Wrap the content key for every recipient
+	for _, pubKey := range recipients {
+		if err := envelope.addRecipient(contentKey, pubKey); err != nil {
+			return nil, err
+		}
+	}
+	return envelope, nil
+}
+
+// EnvelopeFromBytes will parse a serialized envelope (see Envelope.Bytes)
+func EnvelopeFromBytes(data []byte) (*Envelope, error) {
+	reader := bytes.NewReader(data)
+
+	// Magic and recipient count
+	header := make([]byte, len(envelopeMagic)+2)
+	if _, err := io.ReadFull(reader, header); err != nil || !bytes.Equal(header[:4], envelopeMagic[:]) {
+		return nil, ErrEnvelopeMalformed
+	}
+	count := int(binary.BigEndian.Uint16(header[4:]))
+	if count == 0 {
+		return nil, ErrEnvelopeNoRecipients
+	}
+
+	// Recipient slots
+	envelope := &Envelope{Recipients: make([]*EnvelopeRecipient, 0, count)}
+	for range count {
+		slot := make([]byte, 33+2)
+		if _, err := io.ReadFull(reader, slot); err != nil {
+			return nil, ErrEnvelopeMalformed
+		}
+		pubKey, err := ec.ParsePubKey(slot[:33])
+		if err != nil {
+			return nil, err
+		}
+		wrappedKey := make([]byte, binary.BigEndian.Uint16(slot[33:]))
+		if _, err = io.ReadFull(reader, wrappedKey); err != nil {
+			return nil, ErrEnvelopeMalformed
+		}
+		envelope.Recipients = append(envelope.Recipients, &EnvelopeRecipient{PubKey: pubKey, WrappedKey: wrappedKey})
+	}
+
+	// Nonce and cipher text (at least the GCM tag)
+	envelope.Nonce = make([]byte, envelopeNonceLength)
+	if _, err := io.ReadFull(reader, envelope.Nonce); err != nil {
+		return nil, ErrEnvelopeMalformed
+	}
+	if reader.Len() < aes.BlockSize {
+		return nil, ErrEnvelopeMalformed
+	}
+	envelope.Data = make([]byte, reader.Len())
+	_, _ = io.ReadFull(reader, envelope.Data)
+	return envelope, nil
+}
+
+// EnvelopeFromString will parse a hex encoded envelope (see Envelope.Hex)
+func EnvelopeFromString(data string) (*Envelope, error) {
+	rawData, err := hex.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+	return EnvelopeFromBytes(rawData)
+}
+
+// Open will find the slot for the private key and decrypt the payload
+func (e *Envelope) Open(privateKey *ec.PrivateKey) ([]byte, error) {
+	contentKey, err := e.contentKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var gcm cipher.AEAD
+	if gcm, err = newEnvelopeCipher(contentKey); err != nil {
+		return nil, err
+	}
+	if len(e.Nonce) != gcm.NonceSize() {
+		return nil, ErrEnvelopeMalformed
+	}
+	return gcm.Open(nil, e.Nonce, e.Data, envelopeMagic[:])
+}
+
+// IsRecipient returns whether the public key has a slot in the envelope
+func (e *Envelope) IsRecipient(pubKey *ec.PublicKey) bool {
+	return e.findRecipient(pubKey) >= 0
+}
+
+// AddRecipient will wrap the content key for a new recipient public key, using
+// the private key of an existing recipient to unwrap it
+//
+// Adding a key that is already a recipient is a no-op
+func (e *Envelope) AddRecipient(privateKey *ec.PrivateKey, pubKey *ec.PublicKey) error {
+	contentKey, err := e.contentKey(privateKey)
+	if err != nil {
+		return err
+	}
+	return e.addRecipient(contentKey, pubKey)
+}
+
+// RemoveRecipient will remove the slot for the public key
+//
+// The removed recipient can no longer open this copy of the envelope, but may
+// have kept the content key; use Rekey to re-encrypt the payload with a new one
+func (e *Envelope) RemoveRecipient(pubKey *ec.PublicKey) error {
+	index := e.findRecipient(pubKey)
+	if index < 0 {
+		return ErrEnvelopeNotRecipient
+	} else if len(e.Recipients) == 1 {
+		return ErrEnvelopeNoRecipients
+	}
+	e.Recipients = slices.Delete(e.Recipients, index, index+1)
+	return nil
+}
+
+// Rekey will re-encrypt the payload with a new content key and wrap it for the
+// remaining recipients, using the private key of an existing recipient
+func (e *Envelope) Rekey(privateKey *ec.PrivateKey) error {
+	data, err := e.Open(privateKey)
+	if err != nil {
+		return err
+	}
+
+	var rekeyed *Envelope
+	recipients := make([]*ec.PublicKey, 0, len(e.Recipients))
+	for _, recipient := range e.Recipients {
+		recipients = append(recipients, recipient.PubKey)
+	}
+	if rekeyed, err = NewEnvelope(data, recipients, nil); err != nil {
+		return err
+	}
+	*e = *rekeyed
+	return nil
+}
+
+// Bytes will serialize the envelope
+//
+// Envelopes that EnvelopeFromBytes could not parse back (no recipients, more
+// than the format holds, a missing key or a bad nonce) return an error.
+func (e *Envelope) Bytes() ([]byte, error) {
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(envelopeMagic)+2+len(e.Nonce)+len(e.Data))
+	out = append(out, envelopeMagic[:]...)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(e.Recipients))) // #nosec G115 -- checked by validate
+	for _, recipient := range e.Recipients {
+		out = append(out, recipient.PubKey.Compressed()...)
+		out = binary.BigEndian.AppendUint16(out, uint16(len(recipient.WrappedKey))) // #nosec G115 -- checked by validate
+		out = append(out, recipient.WrappedKey...)
+	}
+	out = append(out, e.Nonce...)
+	return append(out, e.Data...), nil
+}
+
+// Hex will serialize the envelope hex encoded (see Bytes)
+func (e *Envelope) Hex() (string, error) {
+	raw, err := e.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// validate checks that the envelope fits the wire format.
+func (e *Envelope) validate() error {
+	if len(e.Recipients) == 0 {
+		return ErrEnvelopeNoRecipients
+	} else if len(e.Recipients) > envelopeMaxRecipients {
+		return ErrEnvelopeTooManyRecipients
+	} else if len(e.Nonce) != envelopeNonceLength {
+		return ErrEnvelopeMalformed
+	}
+	for _, recipient := range e.Recipients {
+		if recipient == nil || recipient.PubKey == nil {
+			return ErrPublicKeyNil
+		} else if len(recipient.WrappedKey) > math.MaxUint16 {
+			return ErrEnvelopeMalformed
+		}
+	}
+	return nil
+}
+
+// seal encrypts the payload with the content key using a random nonce.
+func (e *Envelope) seal(contentKey, data []byte) error {
+	gcm, err := newEnvelopeCipher(contentKey)
+	if err != nil {
+		return err
+	}
+	e.Nonce = make([]byte, envelopeNonceLength)
+	if _, err = io.ReadFull(rand.Reader, e.Nonce); err != nil {
+		return err
+	}
+	e.Data = gcm.Seal(nil, e.Nonce, data, envelopeMagic[:])
+	return nil
+}
+
+// addRecipient wraps the content key for the public key, skipping duplicates.
+func (e *Envelope) addRecipient(contentKey []byte, pubKey *ec.PublicKey) error {
+	if pubKey == nil {
+		return ErrPublicKeyNil
+	} else if e.IsRecipient(pubKey) {
+		return nil
+	} else if len(e.Recipients) >= envelopeMaxRecipients {
+		return ErrEnvelopeTooManyRecipients
+	}
+
+	wrappedKey, err := eciesEncrypt(pubKey, contentKey)
+	if err != nil {
+		return err
+	}
+	e.Recipients = append(e.Recipients, &EnvelopeRecipient{PubKey: pubKey, WrappedKey: wrappedKey})
+	return nil
+}
+
+// contentKey unwraps the content key from the slot of the private key.
+func (e *Envelope) contentKey(privateKey *ec.PrivateKey) ([]byte, error) {
+	if privateKey == nil {
+		return nil, ErrPrivateKeyMissing
+	}
+	index := e.findRecipient(privateKey.PubKey())
+	if index < 0 {
+		return nil, ErrEnvelopeNotRecipient
+	}
+	return eciesDecrypt(privateKey, e.Recipients[index].WrappedKey)
+}
+
+// findRecipient returns the slot index of the public key, or -1.
+func (e *Envelope) findRecipient(pubKey *ec.PublicKey) int {
+	if pubKey == nil {
+		return -1
+	}
+	for index, recipient := range e.Recipients {
+		if recipient.PubKey.IsEqual(pubKey) {
+			return index
+		}
+	}
+	return -1
+}
+
+// newEnvelopeCipher creates the AES-256-GCM cipher for a content key.
+func newEnvelopeCipher(contentKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
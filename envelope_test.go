@@ -0,0 +1,276 @@
+package bitcoin
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestKeys returns n random private keys, failing the test on error
+func newTestKeys(t *testing.T, n int) []*ec.PrivateKey {
+	t.Helper()
+	keys := make([]*ec.PrivateKey, 0, n)
+	for range n {
+		key, err := CreatePrivateKey()
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// pubKeysOf returns the public keys of the private keys
+func pubKeysOf(keys []*ec.PrivateKey) []*ec.PublicKey {
+	pubKeys := make([]*ec.PublicKey, 0, len(keys))
+	for _, key := range keys {
+		pubKeys = append(pubKeys, key.PubKey())
+	}
+	return pubKeys
+}
+
+// TestNewEnvelope will test the methods NewEnvelope() and Envelope.Open()
+func TestNewEnvelope(t *testing.T) {
+	t.Parallel()
+
+	keys := newTestKeys(t, 4)
+	sender, outsider := keys[0], keys[3]
+	recipients := keys[1:3]
+
+	t.Run("every recipient can open", func(t *testing.T) {
+		t.Parallel()
+		envelope, err := NewEnvelope([]byte(testEncryptionMessage), pubKeysOf(recipients), nil)
+		require.NoError(t, err)
+		require.Len(t, envelope.Recipients, 2)
+
+		for _, recipient := range recipients {
+			data, openErr := envelope.Open(recipient)
+			require.NoError(t, openErr)
+			assert.Equal(t, testEncryptionMessage, string(data))
+		}
+
+		_, err = envelope.Open(sender)
+		require.ErrorIs(t, err, ErrEnvelopeNotRecipient)
+		_, err = envelope.Open(nil)
+		require.ErrorIs(t, err, ErrPrivateKeyMissing)
+	})
+
+	t.Run("sender slot", func(t *testing.T) {
+		t.Parallel()
+		envelope, err := NewEnvelope([]byte(testEncryptionMessage), pubKeysOf(recipients), sender)
+		require.NoError(t, err)
+		require.Len(t, envelope.Recipients, 3)
+		assert.True(t, envelope.IsRecipient(sender.PubKey()))
+		assert.False(t, envelope.IsRecipient(outsider.PubKey()))
+		assert.False(t, envelope.IsRecipient(nil))
+
+		data, err := envelope.Open(sender)
+		require.NoError(t, err)
+		assert.Equal(t, testEncryptionMessage, string(data))
+	})
+
+	t.Run("duplicates share a slot", func(t *testing.T) {
+		t.Parallel()
+		pubKeys := append(pubKeysOf(recipients), recipients[0].PubKey())
+		envelope, err := NewEnvelope([]byte(testEncryptionMessage), pubKeys, recipients[1])
+		require.NoError(t, err)
+		assert.Len(t, envelope.Recipients, 2)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewEnvelope([]byte("data"), nil, nil)
+		require.ErrorIs(t, err, ErrEnvelopeNoRecipients)
+		_, err = NewEnvelope([]byte("data"), []*ec.PublicKey{nil}, nil)
+		require.ErrorIs(t, err, ErrPublicKeyNil)
+	})
+
+	t.Run("tampered data", func(t *testing.T) {
+		t.Parallel()
+		envelope, err := NewEnvelope([]byte(testEncryptionMessage), pubKeysOf(recipients), nil)
+		require.NoError(t, err)
+		envelope.Data[0] ^= 0x01
+		_, err = envelope.Open(recipients[0])
+		require.Error(t, err)
+	})
+
+	t.Run("invalid nonce", func(t *testing.T) {
+		t.Parallel()
+		envelope, err := NewEnvelope([]byte(testEncryptionMessage), pubKeysOf(recipients), nil)
+		require.NoError(t, err)
+		for _, nonce := range [][]byte{nil, envelope.Nonce[:4], append(envelope.Nonce, 0x00)} {
+			envelope.Nonce = nonce
+			_, err = envelope.Open(recipients[0])
+			require.ErrorIs(t, err, ErrEnvelopeMalformed)
+		}
+	})
+}
+
+// TestEnvelopeRecipients will test the methods AddRecipient(), RemoveRecipient() and Rekey()
+func TestEnvelopeRecipients(t *testing.T) {
+	t.Parallel()
+
+	keys := newTestKeys(t, 3)
+	alice, bob, carol := keys[0], keys[1], keys[2]
+
+	envelope, err := NewEnvelope([]byte(testEncryptionMessage), []*ec.PublicKey{bob.PubKey()}, alice)
+	require.NoError(t, err)
+
+	// Only existing recipients can add others
+	require.ErrorIs(t, envelope.AddRecipient(carol, carol.PubKey()), ErrEnvelopeNotRecipient)
+	require.ErrorIs(t, envelope.AddRecipient(bob, nil), ErrPublicKeyNil)
+	require.NoError(t, envelope.AddRecipient(bob, carol.PubKey()))
+	require.NoError(t, envelope.AddRecipient(bob, carol.PubKey()))
+	require.Len(t, envelope.Recipients, 3)
+
+	data, err := envelope.Open(carol)
+	require.NoError(t, err)
+	assert.Equal(t, testEncryptionMessage, string(data))
+
+	// Remove bob
+	require.NoError(t, envelope.RemoveRecipient(bob.PubKey()))
+	require.ErrorIs(t, envelope.RemoveRecipient(bob.PubKey()), ErrEnvelopeNotRecipient)
+	_, err = envelope.Open(bob)
+	require.ErrorIs(t, err, ErrEnvelopeNotRecipient)
+
+	// Rekey for the remaining recipients
+	previousData := append([]byte{}, envelope.Data...)
+	require.ErrorIs(t, envelope.Rekey(bob), ErrEnvelopeNotRecipient)
+	require.NoError(t, envelope.Rekey(alice))
+	assert.NotEqual(t, previousData, envelope.Data)
+	for _, key := range []*ec.PrivateKey{alice, carol} {
+		data, err = envelope.Open(key)
+		require.NoError(t, err)
+		assert.Equal(t, testEncryptionMessage, string(data))
+	}
+
+	// The last recipient cannot be removed
+	require.NoError(t, envelope.RemoveRecipient(alice.PubKey()))
+	require.ErrorIs(t, envelope.RemoveRecipient(carol.PubKey()), ErrEnvelopeNoRecipients)
+}
+
+// TestEnvelopeFromBytes will test the serialization of an Envelope
+func TestEnvelopeFromBytes(t *testing.T) {
+	t.Parallel()
+
+	keys := newTestKeys(t, 2)
+	envelope, err := NewEnvelope([]byte(testEncryptionMessage), pubKeysOf(keys), nil)
+	require.NoError(t, err)
+
+	encoded, err := envelope.Hex()
+	require.NoError(t, err)
+	parsed, err := EnvelopeFromString(encoded)
+	require.NoError(t, err)
+	raw, err := envelope.Bytes()
+	require.NoError(t, err)
+	parsedRaw, err := parsed.Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, raw, parsedRaw)
+	for _, key := range keys {
+		data, openErr := parsed.Open(key)
+		require.NoError(t, openErr)
+		assert.Equal(t, testEncryptionMessage, string(data))
+	}
+
+	tests := []struct {
+		name     string
+		input    []byte
+		expected error
+	}{
+		{"nil", nil, ErrEnvelopeMalformed},
+		{"bad magic", append([]byte("ENV2"), raw[4:]...), ErrEnvelopeMalformed},
+		{"no recipients", append([]byte("ENV1"), 0x00, 0x00), ErrEnvelopeNoRecipients},
+		{"truncated slot", raw[:20], ErrEnvelopeMalformed},
+		{"truncated wrapped key", raw[:6+35+10], ErrEnvelopeMalformed},
+		{"truncated nonce", raw[:len(raw)-len(envelope.Data)-1], ErrEnvelopeMalformed},
+		{"missing tag", raw[:len(raw)-len(envelope.Data)+15], ErrEnvelopeMalformed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, parseErr := EnvelopeFromBytes(test.input)
+			require.ErrorIs(t, parseErr, test.expected)
+		})
+	}
+
+	_, err = EnvelopeFromString("zz")
+	require.Error(t, err)
+
+	badKey := append([]byte{}, raw...)
+	badKey[6] = 0x05
+	_, err = EnvelopeFromBytes(badKey)
+	require.Error(t, err)
+}
+
+// TestEnvelopeBytes will test that Bytes() rejects envelopes the format cannot hold
+func TestEnvelopeBytes(t *testing.T) {
+	t.Parallel()
+
+	keys := newTestKeys(t, 1)
+	envelope, err := NewEnvelope([]byte(testEncryptionMessage), pubKeysOf(keys), nil)
+	require.NoError(t, err)
+	recipient := envelope.Recipients[0]
+
+	tests := []struct {
+		name       string
+		recipients []*EnvelopeRecipient
+		nonce      []byte
+		expected   error
+	}{
+		{"no recipients", nil, envelope.Nonce, ErrEnvelopeNoRecipients},
+		{"too many recipients", slices.Repeat([]*EnvelopeRecipient{recipient}, envelopeMaxRecipients+1), envelope.Nonce, ErrEnvelopeTooManyRecipients},
+		{"nil recipient", []*EnvelopeRecipient{nil}, envelope.Nonce, ErrPublicKeyNil},
+		{"missing public key", []*EnvelopeRecipient{{WrappedKey: recipient.WrappedKey}}, envelope.Nonce, ErrPublicKeyNil},
+		{"wrapped key too long", []*EnvelopeRecipient{{PubKey: recipient.PubKey, WrappedKey: make([]byte, 0x10000)}}, envelope.Nonce, ErrEnvelopeMalformed},
+		{"invalid nonce", envelope.Recipients, envelope.Nonce[:8], ErrEnvelopeMalformed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			invalid := &Envelope{Recipients: test.recipients, Nonce: test.nonce, Data: envelope.Data}
+			_, bytesErr := invalid.Bytes()
+			require.ErrorIs(t, bytesErr, test.expected)
+			_, bytesErr = invalid.Hex()
+			require.ErrorIs(t, bytesErr, test.expected)
+		})
+	}
+}
+
+// ExampleNewEnvelope example using NewEnvelope()
+func ExampleNewEnvelope() {
+	sender, _ := CreatePrivateKey()
+	bob, _ := CreatePrivateKey()
+	carol, _ := CreatePrivateKey()
+
+	// Encrypt once for bob, carol and the sender
+	envelope, err := NewEnvelope([]byte("meeting at noon"), []*ec.PublicKey{bob.PubKey(), carol.PubKey()}, sender)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	// Carol finds her slot and opens it
+	var data []byte
+	if data, err = envelope.Open(carol); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("recipients: %d message: %s", len(envelope.Recipients), data)
+	// Output:recipients: 3 message: meeting at noon
+}
+
+// BenchmarkNewEnvelope benchmarks the method NewEnvelope() with 10 recipients
+func BenchmarkNewEnvelope(b *testing.B) {
+	pubKeys := make([]*ec.PublicKey, 0, 10)
+	for range 10 {
+		key, _ := CreatePrivateKey()
+		pubKeys = append(pubKeys, key.PubKey())
+	}
+	for b.Loop() {
+		_, _ = NewEnvelope([]byte("some-data"), pubKeys, nil)
+	}
+}
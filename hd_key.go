@@ -2,6 +2,7 @@ package bitcoin
 
 import (
 	"encoding/hex"
+	"fmt"
 
 	"github.com/bsv-blockchain/go-bt/v2/bscript"
 	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
@@ -27,6 +28,12 @@ const (
 
 // GenerateHDKey will create a new master node for use in creating a hierarchical deterministic keychain
 func GenerateHDKey(seedLength uint8) (*bip32.ExtendedKey, error) {
+	return GenerateHDKeyForNetwork(seedLength, NetworkMainnet)
+}
+
+// GenerateHDKeyForNetwork will create a new master node for the network (an
+// xprv for mainnet, a tprv for testnet and regtest)
+func GenerateHDKeyForNetwork(seedLength uint8, network Network) (*bip32.ExtendedKey, error) {
 	// Missing or invalid seed length
 	if seedLength == 0 {
 		seedLength = RecommendedSeedLength
@@ -39,7 +46,7 @@ func GenerateHDKey(seedLength uint8) (*bip32.ExtendedKey, error) {
 	}
 
 	// Generate a new master key
-	return bip32.NewMaster(seed, &chaincfg.MainNet)
+	return bip32.NewMaster(seed, network.Params())
 }
 
 // GenerateHDKeyFromString will create a new master node for use in creating a
@@ -50,9 +57,15 @@ func GenerateHDKeyFromString(xPriv string) (hdKey *bip32.ExtendedKey, err error)
 
 // GenerateHDKeyPair will generate a new xPub HD master node (xPrivateKey & xPublicKey)
 func GenerateHDKeyPair(seedLength uint8) (xPrivateKey, xPublicKey string, err error) {
+	return GenerateHDKeyPairForNetwork(seedLength, NetworkMainnet)
+}
+
+// GenerateHDKeyPairForNetwork will generate a new HD master node for the network
+// (xprv/xpub for mainnet, tprv/tpub for testnet and regtest)
+func GenerateHDKeyPairForNetwork(seedLength uint8, network Network) (xPrivateKey, xPublicKey string, err error) {
 	// Generate an HD master key
 	var masterKey *bip32.ExtendedKey
-	if masterKey, err = GenerateHDKey(seedLength); err != nil {
+	if masterKey, err = GenerateHDKeyForNetwork(seedLength, network); err != nil {
 		return "", "", err
 	}
 
@@ -127,10 +140,39 @@ func GetPublicKeyFromHDKey(hdKey *bip32.ExtendedKey) (*ec.PublicKey, error) {
 	return hdKey.ECPubKey()
 }
 
+// GetHDKeyNetwork will get the network of an hdKey from its version bytes
+//
+// Testnet and regtest share version bytes, so NetworkTestnet is returned for both.
+// Expects hdKey to not be nil (otherwise will panic)
+func GetHDKeyNetwork(hdKey *bip32.ExtendedKey) (Network, error) {
+	switch {
+	case hdKey.IsForNet(&chaincfg.MainNet):
+		return NetworkMainnet, nil
+	case hdKey.IsForNet(&chaincfg.TestNet):
+		return NetworkTestnet, nil
+	}
+	return NetworkMainnet, ErrUnknownNetwork
+}
+
+// checkHDKeyNetwork returns ErrNetworkMismatch if the hdKey belongs to the other
+// network; keys with unknown version bytes are not checked.
+func checkHDKeyNetwork(hdKey *bip32.ExtendedKey, mainnet bool) error {
+	network, err := GetHDKeyNetwork(hdKey)
+	if err != nil || network.IsMainnet() == mainnet {
+		return nil
+	}
+	return fmt.Errorf("%w: %s key used for %s", ErrNetworkMismatch, network, networkFromMainnet(mainnet))
+}
+
 // GetAddressFromHDKey is a helper function to get the Address associated with a given hdKey
 //
+// Returns ErrNetworkMismatch if the hdKey belongs to the other network (for
+// example a tprv with mainnet true); see GetHDKeyAddress to infer the network.
 // Expects hdKey to not be nil (otherwise will panic)
 func GetAddressFromHDKey(hdKey *bip32.ExtendedKey, mainnet bool) (*bscript.Address, error) {
+	if err := checkHDKeyNetwork(hdKey, mainnet); err != nil {
+		return nil, err
+	}
 	pubKey, err := GetPublicKeyFromHDKey(hdKey)
 	if err != nil {
 		return nil, err
@@ -149,6 +191,30 @@ func GetAddressStringFromHDKey(hdKey *bip32.ExtendedKey, mainnet bool) (string,
 	return address.AddressString, nil
 }
 
+// GetHDKeyAddress is a helper function to get the Address associated with a
+// given hdKey on the network of the key itself (see GetHDKeyNetwork)
+//
+// Expects hdKey to not be nil (otherwise will panic)
+func GetHDKeyAddress(hdKey *bip32.ExtendedKey) (*bscript.Address, error) {
+	network, err := GetHDKeyNetwork(hdKey)
+	if err != nil {
+		return nil, err
+	}
+	return GetAddressFromHDKey(hdKey, network.IsMainnet())
+}
+
+// GetHDKeyAddressString is a helper function to get the Address (string)
+// associated with a given hdKey on the network of the key itself
+//
+// Expects hdKey to not be nil (otherwise will panic)
+func GetHDKeyAddressString(hdKey *bip32.ExtendedKey) (string, error) {
+	address, err := GetHDKeyAddress(hdKey)
+	if err != nil {
+		return "", err
+	}
+	return address.AddressString, nil
+}
+
 // GetPublicKeysForPath gets the PublicKeys for a given derivation path
 // Uses the standard m/0/0 (external) and m/0/1 (internal) paths
 // Reference: https://en.bitcoin.it/wiki/BIP_0032#The_default_wallet_layout
@@ -173,7 +239,13 @@ func GetPublicKeysForPath(hdKey *bip32.ExtendedKey, num uint32) (pubKeys []*ec.P
 
 // GetAddressesForPath will get the corresponding addresses for the PublicKeys at the given path m/0/x
 // Returns 2 keys, first is internal and second is external
+//
+// Returns ErrNetworkMismatch if the hdKey belongs to the other network
 func GetAddressesForPath(hdKey *bip32.ExtendedKey, num uint32, mainnet bool) (addresses []string, err error) {
+	if err = checkHDKeyNetwork(hdKey, mainnet); err != nil {
+		return nil, err
+	}
+
 	// Get the public keys for the corresponding chain/num (using default chain)
 	var pubKeys []*ec.PublicKey
 	if pubKeys, err = GetPublicKeysForPath(hdKey, num); err != nil {
@@ -0,0 +1,156 @@
+package bitcoin
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bsv-blockchain/go-bt/v2/bscript"
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+)
+
+// ErrInvalidRange is returned when a derivation range is reversed or reaches
+// into the hardened indexes
+var ErrInvalidRange = errors.New("range must satisfy from <= to <= 0x80000000")
+
+// DerivedKey is a key derived at chain/num with its address and P2PKH locking script
+type DerivedKey struct {
+	Chain   uint32
+	Num     uint32
+	PubKey  *ec.PublicKey
+	Address string
+	Script  string
+}
+
+// DeriveRange will derive the keys at chain/num for every num in [from, to)
+//
+// The chain node is derived once (unlike calling GetAddressesForPath per index)
+// and the keys are returned in order. See DeriveRangeParallel for large ranges.
+//
+// Expects hdKey to not be nil (otherwise will panic)
+func DeriveRange(hdKey *bip32.ExtendedKey, chain, from, to uint32, mainnet bool) ([]*DerivedKey, error) {
+	return DeriveRangeParallel(hdKey, chain, from, to, mainnet, 1)
+}
+
+// DeriveRangeParallel will derive the keys at chain/num for every num in
+// [from, to) using a pool of workers goroutines (runtime.GOMAXPROCS if workers <= 0)
+//
+// The keys are returned in order. Returns ErrNetworkMismatch if the hdKey
+// belongs to the other network. Expects hdKey to not be nil (otherwise will panic)
+func DeriveRangeParallel(hdKey *bip32.ExtendedKey, chain, from, to uint32, mainnet bool,
+	workers int,
+) ([]*DerivedKey, error) {
+	if from > to || to > bip32.HardenedKeyStart {
+		return nil, ErrInvalidRange
+	} else if err := checkHDKeyNetwork(hdKey, mainnet); err != nil {
+		return nil, err
+	}
+
+	// Derive the chain node once
+	chainKey, err := GetHDKeyChild(hdKey, chain)
+	if err != nil {
+		return nil, err
+	}
+	return deriveChainRange(chainKey, chain, from, to, mainnet, workers)
+}
+
+// GetAddressesForRange will get the addresses at chain/num for every num in [from, to)
+//
+// Expects hdKey to not be nil (otherwise will panic)
+func GetAddressesForRange(hdKey *bip32.ExtendedKey, chain, from, to uint32, mainnet bool) ([]string, error) {
+	keys, err := DeriveRangeParallel(hdKey, chain, from, to, mainnet, 0)
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]string, len(keys))
+	for i, key := range keys {
+		addresses[i] = key.Address
+	}
+	return addresses, nil
+}
+
+// deriveChainRange derives the children [from, to) of an already derived chain
+// node, splitting the indexes between the workers.
+func deriveChainRange(chainKey *bip32.ExtendedKey, chain, from, to uint32, mainnet bool,
+	workers int,
+) ([]*DerivedKey, error) {
+	count := int(to - from)
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, count)
+
+	keys := make([]*DerivedKey, count)
+	if workers <= 1 {
+		for i := range keys {
+			var err error
+			if keys[i], err = deriveChildKey(chainKey, chain, from+uint32(i), mainnet); err != nil { // #nosec G115 -- i < count
+				return nil, err
+			}
+		}
+		return keys, nil
+	}
+
+	// Every worker claims the next index until all are done or one fails
+	var (
+		next     atomic.Int64
+		failed   atomic.Bool
+		firstErr error
+		errOnce  sync.Once
+		wg       sync.WaitGroup
+	)
+	for range workers {
+		wg.Go(func() {
+			for !failed.Load() {
+				i := int(next.Add(1) - 1)
+				if i >= count {
+					return
+				}
+				key, err := deriveChildKey(chainKey, chain, from+uint32(i), mainnet) // #nosec G115 -- i < count
+				if err != nil {
+					errOnce.Do(func() { firstErr = err })
+					failed.Store(true)
+					return
+				}
+				keys[i] = key
+			}
+		})
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return keys, nil
+}
+
+// deriveChildKey derives the child num of the chain node with its address and script.
+func deriveChildKey(chainKey *bip32.ExtendedKey, chain, num uint32, mainnet bool) (*DerivedKey, error) {
+	child, err := GetHDKeyChild(chainKey, num)
+	if err != nil {
+		return nil, err
+	}
+
+	var pubKey *ec.PublicKey
+	if pubKey, err = child.ECPubKey(); err != nil {
+		return nil, err
+	}
+	var address *bscript.Address
+	if address, err = GetAddressFromPubKey(pubKey, true, mainnet); err != nil {
+		return nil, err
+	}
+	var script *bscript.Script
+	if script, err = bscript.NewP2PKHFromPubKeyHashStr(address.PublicKeyHash); err != nil {
+		return nil, err
+	}
+
+	return &DerivedKey{
+		Chain:   chain,
+		Num:     num,
+		PubKey:  pubKey,
+		Address: address.AddressString,
+		Script:  script.String(),
+	}, nil
+}
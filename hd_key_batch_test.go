@@ -0,0 +1,197 @@
+package bitcoin
+
+import (
+	"fmt"
+	"testing"
+
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBatchSize is the number of addresses derived by the batch benchmarks
+const testBatchSize = 1000
+
+// TestDeriveRange will test the methods DeriveRange() and DeriveRangeParallel()
+func TestDeriveRange(t *testing.T) {
+	t.Parallel()
+
+	hdKey := mustHDKey(t)
+
+	tests := []struct {
+		name    string
+		chain   uint32
+		from    uint32
+		to      uint32
+		workers int
+	}{
+		{"sequential external", DefaultExternalChain, 0, 25, 1},
+		{"sequential internal", DefaultInternalChain, 10, 30, 1},
+		{"parallel default workers", DefaultExternalChain, 0, 40, 0},
+		{"parallel more workers than keys", DefaultInternalChain, 5, 8, 64},
+		{"parallel offset", DefaultExternalChain, 100, 137, 4},
+		{"empty range", DefaultExternalChain, 7, 7, 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			keys, err := DeriveRangeParallel(hdKey, test.chain, test.from, test.to, true, test.workers)
+			require.NoError(t, err)
+			require.Len(t, keys, int(test.to-test.from))
+
+			// Matches the per-index helpers, in order
+			for i, key := range keys {
+				num := test.from + uint32(i) // #nosec G115 -- small test range
+				assert.Equal(t, test.chain, key.Chain)
+				assert.Equal(t, num, key.Num)
+
+				child, childErr := GetHDKeyByPath(hdKey, test.chain, num)
+				require.NoError(t, childErr)
+				expectedPubKey, childErr := GetPublicKeyFromHDKey(child)
+				require.NoError(t, childErr)
+				assert.True(t, expectedPubKey.IsEqual(key.PubKey))
+
+				expectedAddress, childErr := GetAddressStringFromHDKey(child, true)
+				require.NoError(t, childErr)
+				assert.Equal(t, expectedAddress, key.Address)
+
+				expectedScript, childErr := ScriptFromAddress(key.Address)
+				require.NoError(t, childErr)
+				assert.Equal(t, expectedScript, key.Script)
+			}
+		})
+	}
+
+	t.Run("sequential equals parallel", func(t *testing.T) {
+		t.Parallel()
+		testnetKey := mustTestnetHDKey(t, hdKey)
+		sequential, err := DeriveRange(testnetKey, DefaultExternalChain, 0, 50, false)
+		require.NoError(t, err)
+		parallel, err := DeriveRangeParallel(testnetKey, DefaultExternalChain, 0, 50, false, 8)
+		require.NoError(t, err)
+		assert.Equal(t, sequential, parallel)
+	})
+
+	t.Run("xpub", func(t *testing.T) {
+		t.Parallel()
+		xPub, err := GetExtendedPublicKey(hdKey)
+		require.NoError(t, err)
+		pubKey, err := GetHDKeyFromExtendedPublicKey(xPub)
+		require.NoError(t, err)
+
+		fromXPub, err := DeriveRange(pubKey, DefaultInternalChain, 0, 10, true)
+		require.NoError(t, err)
+		fromXPriv, err := DeriveRange(hdKey, DefaultInternalChain, 0, 10, true)
+		require.NoError(t, err)
+		assert.Equal(t, fromXPriv, fromXPub)
+	})
+}
+
+// TestDeriveRangeErrors will test the error cases of DeriveRangeParallel()
+func TestDeriveRangeErrors(t *testing.T) {
+	t.Parallel()
+
+	hdKey := mustHDKey(t)
+	xPub, err := GetExtendedPublicKey(hdKey)
+	require.NoError(t, err)
+	pubKey, err := GetHDKeyFromExtendedPublicKey(xPub)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		hdKey    *bip32.ExtendedKey
+		chain    uint32
+		from     uint32
+		to       uint32
+		expected error
+	}{
+		{"reversed range", hdKey, DefaultExternalChain, 10, 9, ErrInvalidRange},
+		{"hardened range", hdKey, DefaultExternalChain, 0, bip32.HardenedKeyStart + 1, ErrInvalidRange},
+		{"hardened chain from xpub", pubKey, bip32.HardenedKeyStart, 0, 1, bip32.ErrDeriveHardFromPublic},
+		{"testnet key", mustTestnetHDKey(t, hdKey), DefaultExternalChain, 0, 1, ErrNetworkMismatch},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			for _, workers := range []int{1, 4} {
+				keys, deriveErr := DeriveRangeParallel(test.hdKey, test.chain, test.from, test.to, true, workers)
+				require.ErrorIs(t, deriveErr, test.expected)
+				assert.Nil(t, keys)
+			}
+			_, deriveErr := GetAddressesForRange(test.hdKey, test.chain, test.from, test.to, true)
+			require.ErrorIs(t, deriveErr, test.expected)
+		})
+	}
+}
+
+// TestGetAddressesForRange will test the method GetAddressesForRange()
+func TestGetAddressesForRange(t *testing.T) {
+	t.Parallel()
+
+	hdKey := mustHDKey(t)
+	addresses, err := GetAddressesForRange(hdKey, DefaultExternalChain, 0, 5, true)
+	require.NoError(t, err)
+	require.Len(t, addresses, 5)
+
+	for num, address := range addresses {
+		expected, pathErr := GetAddressesForPath(hdKey, uint32(num), true) // #nosec G115 -- small test range
+		require.NoError(t, pathErr)
+		assert.Equal(t, expected[0], address)
+	}
+}
+
+// ExampleDeriveRange example using DeriveRange()
+func ExampleDeriveRange() {
+	hdKey, err := GenerateHDKeyFromString(
+		"xprv9s21ZrQH143K3PZSwbEeXEYq74EbnfMngzAiMCZcfjzyRpUvt2vQJnaHRTZjeuEmLXeN6BzYRoFsEckfobxE9XaRzeLGfQoxzPzTRyRb6oE",
+	)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	var keys []*DerivedKey
+	if keys, err = DeriveRange(hdKey, DefaultExternalChain, 0, 2, true); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	for _, key := range keys {
+		fmt.Printf("%d/%d: %s\n", key.Chain, key.Num, key.Address)
+	}
+	// Output:
+	// 0/0: 1AFc9feffQmxT61iEftzkaYvWTgLCyU6j
+	// 0/1: 1MFuvC4FL82TiMZTdfpUB3BwgXCfjdJPBT
+}
+
+// BenchmarkGetAddressesForPathRange benchmarks deriving testBatchSize addresses
+// with GetAddressesForPath() per index (the baseline)
+func BenchmarkGetAddressesForPathRange(b *testing.B) {
+	hdKey, _ := GenerateHDKey(RecommendedSeedLength)
+	for b.Loop() {
+		for num := range uint32(testBatchSize) {
+			_, _ = GetAddressesForPath(hdKey, num, true)
+		}
+	}
+}
+
+// BenchmarkDeriveRange benchmarks the method DeriveRange() with testBatchSize
+// addresses on both chains
+func BenchmarkDeriveRange(b *testing.B) {
+	hdKey, _ := GenerateHDKey(RecommendedSeedLength)
+	for b.Loop() {
+		_, _ = DeriveRange(hdKey, DefaultExternalChain, 0, testBatchSize, true)
+		_, _ = DeriveRange(hdKey, DefaultInternalChain, 0, testBatchSize, true)
+	}
+}
+
+// BenchmarkDeriveRangeParallel benchmarks the method DeriveRangeParallel() with
+// testBatchSize addresses on both chains
+func BenchmarkDeriveRangeParallel(b *testing.B) {
+	hdKey, _ := GenerateHDKey(RecommendedSeedLength)
+	for b.Loop() {
+		_, _ = DeriveRangeParallel(hdKey, DefaultExternalChain, 0, testBatchSize, true, 0)
+		_, _ = DeriveRangeParallel(hdKey, DefaultInternalChain, 0, testBatchSize, true, 0)
+	}
+}
@@ -0,0 +1,196 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/bsv-blockchain/go-bt/v2/bscript"
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	hash "github.com/bsv-blockchain/go-sdk/primitives/hash"
+)
+
+// ErrAddressNotIndexed is returned when an address, hash or script is not in the AddressIndex
+var ErrAddressNotIndexed = errors.New("address is not in the index")
+
+// KeyPath is the HD path of an indexed key: root/account/chain/num
+type KeyPath struct {
+	Account uint32 `json:"account"`
+	Chain   uint32 `json:"chain"`
+	Num     uint32 `json:"num"`
+}
+
+// String returns the path as "account/chain/num" (hardened accounts end in ')
+func (p KeyPath) String() string {
+	if p.Account >= bip32.HardenedKeyStart {
+		return fmt.Sprintf("%d'/%d/%d", p.Account-bip32.HardenedKeyStart, p.Chain, p.Num)
+	}
+	return fmt.Sprintf("%d/%d/%d", p.Account, p.Chain, p.Num)
+}
+
+// AddressIndex maps derived P2PKH addresses back to their HD path
+//
+// Keys are indexed by the hash160 of the (compressed) public key and by the
+// script hash (single SHA-256 of the locking script, as used by Electrum
+// servers), so an incoming output can be matched by its address, its script or
+// either hash. It is safe for concurrent use.
+type AddressIndex struct {
+	mu           sync.RWMutex
+	byHash160    map[[20]byte]KeyPath
+	byScriptHash map[[32]byte]KeyPath
+}
+
+// NewAddressIndex will create an empty AddressIndex
+func NewAddressIndex() *AddressIndex {
+	return &AddressIndex{
+		byHash160:    make(map[[20]byte]KeyPath),
+		byScriptHash: make(map[[32]byte]KeyPath),
+	}
+}
+
+// AddRange will derive the keys at account/chain/num of the root key for every
+// num in [from, to) and add them to the index
+//
+// The account is a child index of the root key; use account + 0x80000000
+// (bip32.HardenedKeyStart) for a hardened account. The keys are derived on the
+// network of the root key (see GetHDKeyNetwork). Expects rootKey to not be nil.
+func (i *AddressIndex) AddRange(rootKey *bip32.ExtendedKey, account, chain, from, to uint32) error {
+	accountKey, err := GetHDKeyChild(rootKey, account)
+	if err != nil {
+		return err
+	}
+
+	// Keys with unknown version bytes are derived as mainnet (not checked)
+	network, _ := GetHDKeyNetwork(rootKey)
+	var keys []*DerivedKey
+	if keys, err = DeriveRangeParallel(accountKey, chain, from, to, network.IsMainnet(), 0); err != nil {
+		return err
+	}
+	i.AddKeys(account, keys)
+	return nil
+}
+
+// AddKeys will add keys derived from an account key (see DeriveRange) to the index
+//
+// Use this for watch-only wallets that only hold the account xPub
+func (i *AddressIndex) AddKeys(account uint32, keys []*DerivedKey) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, key := range keys {
+		i.add(KeyPath{Account: account, Chain: key.Chain, Num: key.Num}, key.PubKey)
+	}
+}
+
+// Add will add a single public key at the path to the index
+func (i *AddressIndex) Add(path KeyPath, pubKey *ec.PublicKey) error {
+	if pubKey == nil {
+		return ErrPublicKeyNil
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.add(path, pubKey)
+	return nil
+}
+
+// Len returns the number of indexed keys
+func (i *AddressIndex) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.byHash160)
+}
+
+// Lookup returns the path of an address (mainnet or testnet)
+func (i *AddressIndex) Lookup(address string) (KeyPath, bool) {
+	pubKeyHash, err := addressHash160(address)
+	if err != nil {
+		return KeyPath{}, false
+	}
+	return i.LookupHash160(pubKeyHash)
+}
+
+// LookupHash160 returns the path of a public key hash (hash160)
+func (i *AddressIndex) LookupHash160(pubKeyHash []byte) (KeyPath, bool) {
+	if len(pubKeyHash) != 20 {
+		return KeyPath{}, false
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	path, ok := i.byHash160[[20]byte(pubKeyHash)]
+	return path, ok
+}
+
+// LookupScript returns the path of a P2PKH locking script (raw bytes)
+func (i *AddressIndex) LookupScript(script []byte) (KeyPath, bool) {
+	return i.LookupScriptHash(hash.Sha256(script))
+}
+
+// LookupScriptHash returns the path of a script hash (SHA-256 of the locking
+// script, not reversed)
+func (i *AddressIndex) LookupScriptHash(scriptHash []byte) (KeyPath, bool) {
+	if len(scriptHash) != 32 {
+		return KeyPath{}, false
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	path, ok := i.byScriptHash[[32]byte(scriptHash)]
+	return path, ok
+}
+
+// GetPrivateKeyForAddress will look up the path of the address and derive its
+// spending key from the root key (see GetPrivateKeyByPath)
+//
+// Returns ErrAddressNotIndexed for unknown addresses, and ErrAddressNotFound if
+// the root key does not derive the address at the indexed path.
+//
+// Expects rootKey to not be nil (otherwise will panic)
+func (i *AddressIndex) GetPrivateKeyForAddress(rootKey *bip32.ExtendedKey,
+	address string,
+) (*ec.PrivateKey, KeyPath, error) {
+	pubKeyHash, err := addressHash160(address)
+	if err != nil {
+		return nil, KeyPath{}, err
+	}
+	path, ok := i.LookupHash160(pubKeyHash)
+	if !ok {
+		return nil, KeyPath{}, fmt.Errorf("%w: %s", ErrAddressNotIndexed, address)
+	}
+
+	var accountKey *bip32.ExtendedKey
+	if accountKey, err = GetHDKeyChild(rootKey, path.Account); err != nil {
+		return nil, path, err
+	}
+	var privateKey *ec.PrivateKey
+	if privateKey, err = GetPrivateKeyByPath(accountKey, path.Chain, path.Num); err != nil {
+		return nil, path, err
+	}
+
+	// Guard against a root key that is not the one the index was built from
+	if !bytes.Equal(hash.Hash160(privateKey.PubKey().Compressed()), pubKeyHash) {
+		return nil, path, fmt.Errorf("%w: root key does not derive %s at %s", ErrAddressNotFound, address, path)
+	}
+	return privateKey, path, nil
+}
+
+// add indexes the public key at the path; the caller holds the lock.
+func (i *AddressIndex) add(path KeyPath, pubKey *ec.PublicKey) {
+	pubKeyHash := hash.Hash160(pubKey.Compressed())
+	i.byHash160[[20]byte(pubKeyHash)] = path
+
+	script := make([]byte, 0, 25)
+	script = append(script, bscript.OpDUP, bscript.OpHASH160, bscript.OpDATA20)
+	script = append(script, pubKeyHash...)
+	script = append(script, bscript.OpEQUALVERIFY, bscript.OpCHECKSIG)
+	i.byScriptHash[[32]byte(hash.Sha256(script))] = path
+}
+
+// addressHash160 decodes the public key hash of a P2PKH address.
+func addressHash160(address string) ([]byte, error) {
+	decoded, err := bscript.NewAddressFromString(address)
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(decoded.PublicKeyHash)
+}
@@ -0,0 +1,231 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	hash "github.com/bsv-blockchain/go-sdk/primitives/hash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAddressIndex will test the lookups of AddressIndex
+func TestAddressIndex(t *testing.T) {
+	t.Parallel()
+
+	rootKey := mustHDKey(t)
+	index := NewAddressIndex()
+	hardened := uint32(bip32.HardenedKeyStart + 1)
+	require.NoError(t, index.AddRange(rootKey, 0, DefaultExternalChain, 0, 10))
+	require.NoError(t, index.AddRange(rootKey, 0, DefaultInternalChain, 0, 10))
+	require.NoError(t, index.AddRange(rootKey, hardened, DefaultExternalChain, 5, 10))
+	assert.Equal(t, 25, index.Len())
+
+	tests := []struct {
+		name     string
+		expected KeyPath
+	}{
+		{"account 0 receive", KeyPath{0, DefaultExternalChain, 3}},
+		{"account 0 change", KeyPath{0, DefaultInternalChain, 9}},
+		{"hardened account", KeyPath{hardened, DefaultExternalChain, 5}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			accountKey, err := GetHDKeyChild(rootKey, test.expected.Account)
+			require.NoError(t, err)
+			child, err := GetHDKeyByPath(accountKey, test.expected.Chain, test.expected.Num)
+			require.NoError(t, err)
+			address, err := GetAddressFromHDKey(child, true)
+			require.NoError(t, err)
+
+			// By address, on either network
+			path, ok := index.Lookup(address.AddressString)
+			require.True(t, ok)
+			assert.Equal(t, test.expected, path)
+
+			testnetAddress, err := GetHDKeyAddressString(mustTestnetHDKey(t, child))
+			require.NoError(t, err)
+			path, ok = index.Lookup(testnetAddress)
+			require.True(t, ok)
+			assert.Equal(t, test.expected, path)
+
+			// By hash160
+			pubKeyHash, err := hex.DecodeString(address.PublicKeyHash)
+			require.NoError(t, err)
+			path, ok = index.LookupHash160(pubKeyHash)
+			require.True(t, ok)
+			assert.Equal(t, test.expected, path)
+
+			// By script and script hash
+			script, err := ScriptFromAddress(address.AddressString)
+			require.NoError(t, err)
+			scriptBytes, err := hex.DecodeString(script)
+			require.NoError(t, err)
+			path, ok = index.LookupScript(scriptBytes)
+			require.True(t, ok)
+			assert.Equal(t, test.expected, path)
+			path, ok = index.LookupScriptHash(hash.Sha256(scriptBytes))
+			require.True(t, ok)
+			assert.Equal(t, test.expected, path)
+
+			// The spending key
+			privateKey, path, err := index.GetPrivateKeyForAddress(rootKey, address.AddressString)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, path)
+			expectedKey, err := GetPrivateKeyByPath(accountKey, test.expected.Chain, test.expected.Num)
+			require.NoError(t, err)
+			assert.Equal(t, expectedKey.Serialize(), privateKey.Serialize())
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		t.Parallel()
+		_, ok := index.Lookup(testAddress)
+		assert.False(t, ok)
+		_, ok = index.Lookup("invalid-address")
+		assert.False(t, ok)
+		_, ok = index.LookupHash160([]byte{1, 2, 3})
+		assert.False(t, ok)
+		_, ok = index.LookupScriptHash([]byte{1, 2, 3})
+		assert.False(t, ok)
+		_, ok = index.LookupScript([]byte{0x6a})
+		assert.False(t, ok)
+
+		_, _, err := index.GetPrivateKeyForAddress(rootKey, testAddress)
+		require.ErrorIs(t, err, ErrAddressNotIndexed)
+		_, _, err = index.GetPrivateKeyForAddress(rootKey, "invalid-address")
+		require.Error(t, err)
+	})
+
+	t.Run("wrong root key", func(t *testing.T) {
+		t.Parallel()
+		accountKey, err := GetHDKeyChild(rootKey, 0)
+		require.NoError(t, err)
+		addresses, err := GetAddressesForRange(accountKey, DefaultExternalChain, 0, 1, true)
+		require.NoError(t, err)
+
+		privateKey, path, err := index.GetPrivateKeyForAddress(mustHDKey(t), addresses[0])
+		require.ErrorIs(t, err, ErrAddressNotFound)
+		assert.Nil(t, privateKey)
+		assert.Equal(t, KeyPath{0, DefaultExternalChain, 0}, path)
+	})
+}
+
+// TestAddressIndexTestnet will test indexing the keys of a testnet (tprv) root key
+func TestAddressIndexTestnet(t *testing.T) {
+	t.Parallel()
+
+	rootKey := mustTestnetHDKey(t, mustHDKey(t))
+	index := NewAddressIndex()
+	require.NoError(t, index.AddRange(rootKey, 0, DefaultExternalChain, 0, 5))
+	assert.Equal(t, 5, index.Len())
+
+	accountKey, err := GetHDKeyChild(rootKey, 0)
+	require.NoError(t, err)
+	addresses, err := GetAddressesForRange(accountKey, DefaultExternalChain, 4, 5, false)
+	require.NoError(t, err)
+
+	path, ok := index.Lookup(addresses[0])
+	require.True(t, ok)
+	assert.Equal(t, KeyPath{0, DefaultExternalChain, 4}, path)
+
+	privateKey, _, err := index.GetPrivateKeyForAddress(rootKey, addresses[0])
+	require.NoError(t, err)
+	address, err := GetAddressFromPrivateKey(privateKey, true, false)
+	require.NoError(t, err)
+	assert.Equal(t, addresses[0], address)
+}
+
+// TestAddressIndexWatchOnly will test indexing keys derived from an account xPub
+func TestAddressIndexWatchOnly(t *testing.T) {
+	t.Parallel()
+
+	rootKey := mustHDKey(t)
+	account := uint32(bip32.HardenedKeyStart + 44)
+	accountKey, err := GetHDKeyChild(rootKey, account)
+	require.NoError(t, err)
+	accountXPub, err := GetExtendedPublicKey(accountKey)
+	require.NoError(t, err)
+
+	// The watch-only side only has the account xPub
+	watchKey, err := GetHDKeyFromExtendedPublicKey(accountXPub)
+	require.NoError(t, err)
+	keys, err := DeriveRange(watchKey, DefaultExternalChain, 0, 5, true)
+	require.NoError(t, err)
+
+	index := NewAddressIndex()
+	index.AddKeys(account, keys)
+	assert.Equal(t, 5, index.Len())
+
+	// The signing side derives the spending key from the root
+	privateKey, path, err := index.GetPrivateKeyForAddress(rootKey, keys[4].Address)
+	require.NoError(t, err)
+	assert.Equal(t, KeyPath{account, DefaultExternalChain, 4}, path)
+	assert.True(t, privateKey.PubKey().IsEqual(keys[4].PubKey))
+
+	// Adding from the xPub root fails for a hardened account
+	require.ErrorIs(t, index.AddRange(watchKey, account, 0, 0, 1), bip32.ErrDeriveHardFromPublic)
+	require.ErrorIs(t, index.AddRange(rootKey, 0, DefaultExternalChain, 2, 1), ErrInvalidRange)
+}
+
+// TestAddressIndexAdd will test the method Add()
+func TestAddressIndexAdd(t *testing.T) {
+	t.Parallel()
+
+	index := NewAddressIndex()
+	require.ErrorIs(t, index.Add(KeyPath{}, nil), ErrPublicKeyNil)
+
+	privateKey := mustTestPrivKey(t)
+	require.NoError(t, index.Add(KeyPath{1, 2, 3}, privateKey.PubKey()))
+	address, err := GetAddressFromPrivateKey(privateKey, true, true)
+	require.NoError(t, err)
+	path, ok := index.Lookup(address)
+	require.True(t, ok)
+	assert.Equal(t, KeyPath{1, 2, 3}, path)
+}
+
+// TestKeyPathString will test the method KeyPath.String()
+func TestKeyPathString(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "0/1/2", KeyPath{0, 1, 2}.String())
+	assert.Equal(t, "44'/0/7", KeyPath{bip32.HardenedKeyStart + 44, 0, 7}.String())
+}
+
+// ExampleAddressIndex example using AddressIndex
+func ExampleAddressIndex() {
+	rootKey, err := GenerateHDKeyFromString(
+		"xprv9s21ZrQH143K3PZSwbEeXEYq74EbnfMngzAiMCZcfjzyRpUvt2vQJnaHRTZjeuEmLXeN6BzYRoFsEckfobxE9XaRzeLGfQoxzPzTRyRb6oE",
+	)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	// Index the first 100 receive addresses of account 0
+	index := NewAddressIndex()
+	if err = index.AddRange(rootKey, 0, DefaultExternalChain, 0, 100); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	// An output paid to one of them (1GuikgpJDhpqvYjEiTD5mvS5YC5pQoPraM) arrives
+	path, _ := index.Lookup("1GuikgpJDhpqvYjEiTD5mvS5YC5pQoPraM")
+	fmt.Printf("path: %s", path)
+	// Output:path: 0/0/42
+}
+
+// BenchmarkAddressIndexLookup benchmarks the method Lookup()
+func BenchmarkAddressIndexLookup(b *testing.B) {
+	rootKey, _ := GenerateHDKey(RecommendedSeedLength)
+	index := NewAddressIndex()
+	_ = index.AddRange(rootKey, 0, DefaultExternalChain, 0, 1000)
+	accountKey, _ := GetHDKeyChild(rootKey, 0)
+	addresses, _ := GetAddressesForRange(accountKey, DefaultExternalChain, 500, 501, true)
+	for b.Loop() {
+		_, _ = index.Lookup(addresses[0])
+	}
+}
@@ -0,0 +1,238 @@
+package bitcoin
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	base58 "github.com/bsv-blockchain/go-sdk/compat/base58"
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	hash "github.com/bsv-blockchain/go-sdk/primitives/hash"
+)
+
+// ErrInvalidKeyOrigin is returned when a key origin ([fingerprint/path]) is
+// malformed or does not match the key it describes
+var ErrInvalidKeyOrigin = errors.New("invalid key origin")
+
+// HDKeyMetadata is the metadata serialized in an extended key
+type HDKeyMetadata struct {
+	Depth             uint8   `json:"depth"`
+	Fingerprint       uint32  `json:"fingerprint"`
+	ParentFingerprint uint32  `json:"parent_fingerprint"`
+	ChildIndex        uint32  `json:"child_index"`
+	Hardened          bool    `json:"hardened"`
+	Network           Network `json:"network"`
+	Private           bool    `json:"private"`
+}
+
+// GetHDKeyMetadata will get the depth, fingerprints, child index, network and
+// type (xprv or xpub) of an hdKey
+//
+// Expects hdKey to not be nil (otherwise will panic)
+func GetHDKeyMetadata(hdKey *bip32.ExtendedKey) (*HDKeyMetadata, error) {
+	network, err := GetHDKeyNetwork(hdKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var fingerprint uint32
+	if fingerprint, err = GetHDKeyFingerprint(hdKey); err != nil {
+		return nil, err
+	}
+
+	// The child index is only available in the serialized key:
+	// version (4) || depth (1) || parent fingerprint (4) || child index (4) || ...
+	decoded, err := base58.Decode(hdKey.String())
+	if err != nil {
+		return nil, err
+	}
+	childIndex := binary.BigEndian.Uint32(decoded[9:13])
+
+	return &HDKeyMetadata{
+		Depth:             hdKey.Depth(),
+		Fingerprint:       fingerprint,
+		ParentFingerprint: hdKey.ParentFingerprint(),
+		ChildIndex:        childIndex,
+		Hardened:          childIndex >= bip32.HardenedKeyStart,
+		Network:           network,
+		Private:           hdKey.IsPrivate(),
+	}, nil
+}
+
+// GetHDKeyFingerprint will get the fingerprint of an hdKey (the first 4 bytes
+// of the hash160 of its public key), as used by the parent fingerprint of its children
+//
+// Expects hdKey to not be nil (otherwise will panic)
+func GetHDKeyFingerprint(hdKey *bip32.ExtendedKey) (uint32, error) {
+	pubKey, err := hdKey.ECPubKey()
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(hash.Hash160(pubKey.Compressed())[:4]), nil
+}
+
+// KeyOrigin is the fingerprint of a master key and the path of a key derived
+// from it, as used by descriptors, PSBTs and hardware wallets
+type KeyOrigin struct {
+	Fingerprint uint32
+	Path        []uint32
+}
+
+// String returns the origin as "d34db33f/44'/236'/0'" (without the brackets)
+func (o KeyOrigin) String() string {
+	return fingerprintHex(o.Fingerprint) + formatPath(o.Path)
+}
+
+// ParseKeyOrigin will parse an origin like "d34db33f/44'/236'/0'" (without
+// the brackets); hardened indexes may end in ' or h
+func ParseKeyOrigin(origin string) (KeyOrigin, error) {
+	parts := strings.Split(origin, "/")
+	fingerprint, err := hex.DecodeString(parts[0])
+	if err != nil || len(fingerprint) != 4 {
+		return KeyOrigin{}, fmt.Errorf("%w: fingerprint must be 8 hex characters: %q", ErrInvalidKeyOrigin, parts[0])
+	}
+
+	keyOrigin := KeyOrigin{Fingerprint: binary.BigEndian.Uint32(fingerprint)}
+	for _, part := range parts[1:] {
+		var index uint32
+		if index, err = parsePathIndex(part); err != nil {
+			return KeyOrigin{}, err
+		}
+		keyOrigin.Path = append(keyOrigin.Path, index)
+	}
+	return keyOrigin, nil
+}
+
+// GetExtendedPublicKeyWithOrigin will derive the key at path from the master
+// key and return its xPub with the key origin, like "[d34db33f/44'/236'/0']xpub..."
+//
+// Expects masterKey to not be nil (otherwise will panic)
+func GetExtendedPublicKeyWithOrigin(masterKey *bip32.ExtendedKey, path ...uint32) (string, error) {
+	fingerprint, err := GetHDKeyFingerprint(masterKey)
+	if err != nil {
+		return "", err
+	}
+
+	hdKey := masterKey
+	for _, index := range path {
+		if hdKey, err = GetHDKeyChild(hdKey, index); err != nil {
+			return "", err
+		}
+	}
+	return FormatKeyWithOrigin(KeyOrigin{Fingerprint: fingerprint, Path: path}, hdKey)
+}
+
+// FormatKeyWithOrigin will format the xPub of an hdKey with its key origin,
+// like "[d34db33f/44'/236'/0']xpub..."
+//
+// The path must end at the hdKey (same depth and child index). Expects hdKey to
+// not be nil (otherwise will panic)
+func FormatKeyWithOrigin(origin KeyOrigin, hdKey *bip32.ExtendedKey) (string, error) {
+	if err := checkKeyOrigin(origin, hdKey); err != nil {
+		return "", err
+	}
+	xPub, err := GetExtendedPublicKey(hdKey)
+	if err != nil {
+		return "", err
+	}
+	return "[" + origin.String() + "]" + xPub, nil
+}
+
+// ParseKeyWithOrigin will parse an extended key with a key origin, like
+// "[d34db33f/44'/236'/0']xpub...", checking that the path ends at the key
+//
+// A key without an origin is returned with a zero KeyOrigin.
+func ParseKeyWithOrigin(key string) (KeyOrigin, *bip32.ExtendedKey, error) {
+	var keyOrigin KeyOrigin
+	hasOrigin := strings.HasPrefix(key, "[")
+	if hasOrigin {
+		end := strings.IndexByte(key, ']')
+		if end < 0 {
+			return KeyOrigin{}, nil, fmt.Errorf("%w: missing ]", ErrInvalidKeyOrigin)
+		}
+		var err error
+		if keyOrigin, err = ParseKeyOrigin(key[1:end]); err != nil {
+			return KeyOrigin{}, nil, err
+		}
+		key = key[end+1:]
+	}
+
+	hdKey, err := bip32.NewKeyFromString(key)
+	if err != nil {
+		return KeyOrigin{}, nil, err
+	}
+	if hasOrigin {
+		if err = checkKeyOrigin(keyOrigin, hdKey); err != nil {
+			return KeyOrigin{}, nil, err
+		}
+	}
+	return keyOrigin, hdKey, nil
+}
+
+// checkKeyOrigin checks that the origin path ends at the hdKey.
+func checkKeyOrigin(origin KeyOrigin, hdKey *bip32.ExtendedKey) error {
+	metadata, err := GetHDKeyMetadata(hdKey)
+	if err != nil {
+		return err
+	}
+	if len(origin.Path) != int(metadata.Depth) {
+		return fmt.Errorf("%w: path %s has %d indexes but the key depth is %d",
+			ErrInvalidKeyOrigin, origin, len(origin.Path), metadata.Depth)
+	}
+	if len(origin.Path) > 0 && origin.Path[len(origin.Path)-1] != metadata.ChildIndex {
+		return fmt.Errorf("%w: path %s does not end at child index %d",
+			ErrInvalidKeyOrigin, origin, metadata.ChildIndex)
+	}
+
+	// The master fingerprint can only be checked against a master key or its child
+	switch {
+	case len(origin.Path) == 0 && metadata.Fingerprint != origin.Fingerprint:
+		return fmt.Errorf("%w: fingerprint %s is not the key fingerprint %s",
+			ErrInvalidKeyOrigin, fingerprintHex(origin.Fingerprint), fingerprintHex(metadata.Fingerprint))
+	case len(origin.Path) == 1 && metadata.ParentFingerprint != origin.Fingerprint:
+		return fmt.Errorf("%w: fingerprint %s is not the parent fingerprint %s",
+			ErrInvalidKeyOrigin, fingerprintHex(origin.Fingerprint), fingerprintHex(metadata.ParentFingerprint))
+	}
+	return nil
+}
+
+// parsePathIndex parses a path index like "44" or a hardened index like "44'" or "44h".
+func parsePathIndex(part string) (uint32, error) {
+	hardened := strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h")
+	if hardened {
+		part = part[:len(part)-1]
+	}
+	index, err := strconv.ParseUint(part, 10, 32)
+	if err != nil || index >= uint64(bip32.HardenedKeyStart) {
+		return 0, fmt.Errorf("%w: invalid path index %q", ErrInvalidKeyOrigin, part)
+	}
+	if hardened {
+		index += uint64(bip32.HardenedKeyStart)
+	}
+	return uint32(index), nil // #nosec G115 -- index < 2^32
+}
+
+// formatPath formats a path as "/44'/0/7" (empty for an empty path).
+func formatPath(path []uint32) string {
+	var b strings.Builder
+	for _, index := range path {
+		b.WriteByte('/')
+		if index >= bip32.HardenedKeyStart {
+			b.WriteString(strconv.FormatUint(uint64(index-bip32.HardenedKeyStart), 10))
+			b.WriteByte('\'')
+		} else {
+			b.WriteString(strconv.FormatUint(uint64(index), 10))
+		}
+	}
+	return b.String()
+}
+
+// fingerprintHex formats a fingerprint as 8 hex characters.
+func fingerprintHex(fingerprint uint32) string {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], fingerprint)
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,212 @@
+package bitcoin
+
+import (
+	"fmt"
+	"testing"
+
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBIP32Master is the master key of BIP32 test vector 1 (seed 000102...0f)
+const testBIP32Master = "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"
+
+// TestGetHDKeyMetadata will test the method GetHDKeyMetadata()
+func TestGetHDKeyMetadata(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		key      string
+		expected HDKeyMetadata
+	}{
+		{
+			"master xprv",
+			testBIP32Master,
+			HDKeyMetadata{Depth: 0, Fingerprint: 0x3442193e, Network: NetworkMainnet, Private: true},
+		},
+		{
+			"m/0' xprv",
+			"xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7",
+			HDKeyMetadata{
+				Depth: 1, Fingerprint: 0x5c1bd648, ParentFingerprint: 0x3442193e,
+				ChildIndex: bip32.HardenedKeyStart, Hardened: true, Network: NetworkMainnet, Private: true,
+			},
+		},
+		{
+			"m/0'/1 xpub",
+			"xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ",
+			HDKeyMetadata{
+				Depth: 2, Fingerprint: 0xbef5a2f9, ParentFingerprint: 0x5c1bd648,
+				ChildIndex: 1, Network: NetworkMainnet,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			hdKey, err := bip32.NewKeyFromString(test.key)
+			require.NoError(t, err)
+			metadata, err := GetHDKeyMetadata(hdKey)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, *metadata)
+		})
+	}
+
+	t.Run("testnet", func(t *testing.T) {
+		t.Parallel()
+		hdKey, err := GenerateHDKeyForNetwork(RecommendedSeedLength, NetworkTestnet)
+		require.NoError(t, err)
+		metadata, err := GetHDKeyMetadata(hdKey)
+		require.NoError(t, err)
+		assert.Equal(t, NetworkTestnet, metadata.Network)
+	})
+
+	t.Run("zeroed key", func(t *testing.T) {
+		t.Parallel()
+		metadata, err := GetHDKeyMetadata(new(bip32.ExtendedKey))
+		require.Error(t, err)
+		assert.Nil(t, metadata)
+		_, err = GetHDKeyFingerprint(new(bip32.ExtendedKey))
+		require.Error(t, err)
+	})
+}
+
+// TestKeyOrigin will test the methods KeyOrigin.String() and ParseKeyOrigin()
+func TestKeyOrigin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		expected string
+		origin   KeyOrigin
+	}{
+		{"d34db33f", "d34db33f", KeyOrigin{Fingerprint: 0xd34db33f}},
+		{"d34db33f/44'/236'/0'", "d34db33f/44'/236'/0'", KeyOrigin{0xd34db33f, []uint32{
+			bip32.HardenedKeyStart + 44, bip32.HardenedKeyStart + 236, bip32.HardenedKeyStart,
+		}}},
+		{"00000001/44h/0/7", "00000001/44'/0/7", KeyOrigin{1, []uint32{bip32.HardenedKeyStart + 44, 0, 7}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			t.Parallel()
+			origin, err := ParseKeyOrigin(test.input)
+			require.NoError(t, err)
+			assert.Equal(t, test.origin, origin)
+			assert.Equal(t, test.expected, origin.String())
+		})
+	}
+
+	for _, invalid := range []string{"", "d34db33", "d34db33fff", "zz4db33f", "d34db33f/", "d34db33f/x", "d34db33f/2147483648", "d34db33f/-1"} {
+		_, err := ParseKeyOrigin(invalid)
+		require.ErrorIs(t, err, ErrInvalidKeyOrigin, invalid)
+	}
+}
+
+// TestKeyWithOrigin will test the methods GetExtendedPublicKeyWithOrigin(),
+// FormatKeyWithOrigin() and ParseKeyWithOrigin()
+func TestKeyWithOrigin(t *testing.T) {
+	t.Parallel()
+
+	masterKey, err := bip32.NewKeyFromString(testBIP32Master)
+	require.NoError(t, err)
+	path := []uint32{bip32.HardenedKeyStart, 1}
+
+	key, err := GetExtendedPublicKeyWithOrigin(masterKey, path...)
+	require.NoError(t, err)
+	assert.Equal(t,
+		"[3442193e/0'/1]xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ",
+		key,
+	)
+
+	origin, hdKey, err := ParseKeyWithOrigin(key)
+	require.NoError(t, err)
+	assert.Equal(t, KeyOrigin{0x3442193e, path}, origin)
+	assert.False(t, hdKey.IsPrivate())
+
+	t.Run("master key", func(t *testing.T) {
+		t.Parallel()
+		masterWithOrigin, masterErr := GetExtendedPublicKeyWithOrigin(masterKey)
+		require.NoError(t, masterErr)
+		masterOrigin, _, masterErr := ParseKeyWithOrigin(masterWithOrigin)
+		require.NoError(t, masterErr)
+		assert.Equal(t, KeyOrigin{Fingerprint: 0x3442193e}, masterOrigin)
+	})
+
+	t.Run("no origin", func(t *testing.T) {
+		t.Parallel()
+		noOrigin, noOriginKey, noOriginErr := ParseKeyWithOrigin(testBIP32Master)
+		require.NoError(t, noOriginErr)
+		assert.Equal(t, KeyOrigin{}, noOrigin)
+		assert.True(t, noOriginKey.IsPrivate())
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		t.Parallel()
+		xPub := key[len("[3442193e/0'/1]"):]
+		for _, invalid := range []string{
+			"[3442193e/0'/2]" + xPub,       // wrong child index
+			"[3442193e/1]" + xPub,          // wrong depth
+			"[3442193e/0'/1" + xPub,        // missing ]
+			"[3442193e/x/1]" + xPub,        // invalid index
+			"[d34db33f]" + testBIP32Master, // wrong master fingerprint
+			"[d34db33f/0']" + mustChildXPub(t, masterKey, bip32.HardenedKeyStart), // wrong parent fingerprint
+		} {
+			_, _, parseErr := ParseKeyWithOrigin(invalid)
+			require.ErrorIs(t, parseErr, ErrInvalidKeyOrigin, invalid)
+		}
+
+		_, _, parseErr := ParseKeyWithOrigin("[3442193e/0'/1]xpub-invalid")
+		require.Error(t, parseErr)
+
+		_, formatErr := FormatKeyWithOrigin(KeyOrigin{0x3442193e, path[:1]}, hdKey)
+		require.ErrorIs(t, formatErr, ErrInvalidKeyOrigin)
+	})
+
+	t.Run("hardened from xpub", func(t *testing.T) {
+		t.Parallel()
+		masterPub, neuterErr := masterKey.Neuter()
+		require.NoError(t, neuterErr)
+		_, deriveErr := GetExtendedPublicKeyWithOrigin(masterPub, bip32.HardenedKeyStart)
+		require.ErrorIs(t, deriveErr, bip32.ErrDeriveHardFromPublic)
+	})
+}
+
+// mustChildXPub derives the child of an hdKey and returns its xPub, failing the test on error
+func mustChildXPub(t *testing.T, hdKey *bip32.ExtendedKey, index uint32) string {
+	t.Helper()
+	child, err := GetHDKeyChild(hdKey, index)
+	require.NoError(t, err)
+	xPub, err := GetExtendedPublicKey(child)
+	require.NoError(t, err)
+	return xPub
+}
+
+// ExampleGetExtendedPublicKeyWithOrigin example using GetExtendedPublicKeyWithOrigin()
+func ExampleGetExtendedPublicKeyWithOrigin() {
+	masterKey, err := GenerateHDKeyFromString(testBIP32Master)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	// Export the account xPub (m/0') for a co-signer
+	var key string
+	if key, err = GetExtendedPublicKeyWithOrigin(masterKey, bip32.HardenedKeyStart); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("key: %s", key)
+	// Output:key: [3442193e/0']xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw
+}
+
+// BenchmarkGetHDKeyMetadata benchmarks the method GetHDKeyMetadata()
+func BenchmarkGetHDKeyMetadata(b *testing.B) {
+	hdKey, _ := GenerateHDKey(RecommendedSeedLength)
+	for b.Loop() {
+		_, _ = GetHDKeyMetadata(hdKey)
+	}
+}
@@ -135,6 +135,105 @@ func BenchmarkGenerateHDKeyPairSecure(b *testing.B) {
 	}
 }
 
+// TestGenerateHDKeyForNetwork will test the methods GenerateHDKeyForNetwork()
+// and GenerateHDKeyPairForNetwork()
+func TestGenerateHDKeyForNetwork(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		network        Network
+		expectedPrefix string
+		expectedPub    string
+		expectedAddr   string
+	}{
+		{NetworkMainnet, "xprv", "xpub", "1"},
+		{NetworkTestnet, "tprv", "tpub", "mn"},
+		{NetworkRegtest, "tprv", "tpub", "mn"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.network.String(), func(t *testing.T) {
+			t.Parallel()
+			hdKey, err := GenerateHDKeyForNetwork(RecommendedSeedLength, test.network)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedPrefix, hdKey.String()[:4])
+
+			network, err := GetHDKeyNetwork(hdKey)
+			require.NoError(t, err)
+			assert.Equal(t, test.network.IsMainnet(), network.IsMainnet())
+
+			address, err := GetHDKeyAddressString(hdKey)
+			require.NoError(t, err)
+			assert.Contains(t, test.expectedAddr, address[:1])
+
+			// The explicit network must match the key
+			_, err = GetAddressFromHDKey(hdKey, !test.network.IsMainnet())
+			require.ErrorIs(t, err, ErrNetworkMismatch)
+
+			xPrivateKey, xPublicKey, err := GenerateHDKeyPairForNetwork(RecommendedSeedLength, test.network)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedPrefix, xPrivateKey[:4])
+			assert.Equal(t, test.expectedPub, xPublicKey[:4])
+
+			// A tpub/tprv parses back to the same network
+			pubKey, err := GetHDKeyFromExtendedPublicKey(xPublicKey)
+			require.NoError(t, err)
+			network, err = GetHDKeyNetwork(pubKey)
+			require.NoError(t, err)
+			assert.Equal(t, test.network.IsMainnet(), network.IsMainnet())
+		})
+	}
+
+	t.Run("invalid seed length", func(t *testing.T) {
+		t.Parallel()
+		hdKey, err := GenerateHDKeyForNetwork(1, NetworkTestnet)
+		require.Error(t, err)
+		assert.Nil(t, hdKey)
+		_, _, err = GenerateHDKeyPairForNetwork(1, NetworkTestnet)
+		require.Error(t, err)
+	})
+}
+
+// TestGetHDKeyNetwork will test the method GetHDKeyNetwork()
+func TestGetHDKeyNetwork(t *testing.T) {
+	t.Parallel()
+
+	network, err := GetHDKeyNetwork(new(bip32.ExtendedKey))
+	require.ErrorIs(t, err, ErrUnknownNetwork)
+	assert.Equal(t, NetworkMainnet, network)
+
+	_, err = GetHDKeyAddress(new(bip32.ExtendedKey))
+	require.ErrorIs(t, err, ErrUnknownNetwork)
+	_, err = GetHDKeyAddressString(new(bip32.ExtendedKey))
+	require.ErrorIs(t, err, ErrUnknownNetwork)
+}
+
+// ExampleGetHDKeyAddressString example using GetHDKeyAddressString()
+func ExampleGetHDKeyAddressString() {
+	hdKey, err := GenerateHDKeyFromString("tprv8ZgxMBicQKsPeCnycA69gtApRBep2BPo2Y5qDcz59iVTDRE1sQG9pXwjLdjPfGd5hyB96HcJb9qfhUJQvpJAxar2XHYaKmY1uVjssmcpSxf")
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	var address string
+	if address, err = GetHDKeyAddressString(hdKey); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	fmt.Printf("address: %s", address)
+	// Output:address: mnmyqUN2bSkgjFJQZ4DrAzzVAh4G9sGbCk
+}
+
+// BenchmarkGetHDKeyAddressString benchmarks the method GetHDKeyAddressString()
+func BenchmarkGetHDKeyAddressString(b *testing.B) {
+	hdKey, _ := GenerateHDKeyForNetwork(SecureSeedLength, NetworkTestnet)
+	for b.Loop() {
+		_, _ = GetHDKeyAddressString(hdKey)
+	}
+}
+
 // TestGetPrivateKeyByPath will test the method GetPrivateKeyByPath()
 func TestGetPrivateKeyByPath(t *testing.T) {
 	t.Parallel()
@@ -742,6 +841,7 @@ func TestGetAddressFromHDKey(t *testing.T) {
 	validHdKey, err := GenerateHDKeyFromString("xprv9s21ZrQH143K4FdJCmPQe1CFUvK3PKVrcp3b5xVr5Bs3cP5ab6ytszeHggTmHoqTXpaa8CgYPxZZzigSGCDjtyWdUDJqPogb1JGWAPkBLdF")
 	require.NoError(t, err)
 	assert.NotNil(t, validHdKey)
+	testnetHdKey := mustTestnetHDKey(t, validHdKey)
 
 	tests := []struct {
 		name            string
@@ -753,7 +853,9 @@ func TestGetAddressFromHDKey(t *testing.T) {
 	}{
 		{"zeroed extended key mainnet", new(bip32.ExtendedKey), "", true, true, true},
 		{"valid hd key mainnet", validHdKey, "13xHrMdZuqa2gpweHf37w8hu6tfv3JrnaW", false, false, true},
-		{"valid hd key testnet", validHdKey, "miUF9QiYis1HTwRG1E1Vm3vDxtGczs2oph", false, false, false},
+		{"valid hd key testnet", testnetHdKey, "miUF9QiYis1HTwRG1E1Vm3vDxtGczs2oph", false, false, false},
+		{"mainnet hd key testnet", validHdKey, "", true, true, false},
+		{"testnet hd key mainnet", testnetHdKey, "", true, true, true},
 	}
 
 	for _, test := range tests {
@@ -818,6 +920,7 @@ func TestGetAddressStringFromHDKey(t *testing.T) {
 	validHdKey, err := GenerateHDKeyFromString("xprv9s21ZrQH143K4FdJCmPQe1CFUvK3PKVrcp3b5xVr5Bs3cP5ab6ytszeHggTmHoqTXpaa8CgYPxZZzigSGCDjtyWdUDJqPogb1JGWAPkBLdF")
 	require.NoError(t, err)
 	assert.NotNil(t, validHdKey)
+	testnetHdKey := mustTestnetHDKey(t, validHdKey)
 
 	tests := []struct {
 		name            string
@@ -828,7 +931,9 @@ func TestGetAddressStringFromHDKey(t *testing.T) {
 	}{
 		{"zeroed extended key mainnet", new(bip32.ExtendedKey), "", true, true},
 		{"valid hd key mainnet", validHdKey, "13xHrMdZuqa2gpweHf37w8hu6tfv3JrnaW", false, true},
-		{"valid hd key testnet", validHdKey, "miUF9QiYis1HTwRG1E1Vm3vDxtGczs2oph", false, false},
+		{"valid hd key testnet", testnetHdKey, "miUF9QiYis1HTwRG1E1Vm3vDxtGczs2oph", false, false},
+		{"mainnet hd key testnet", validHdKey, "", true, false},
+		{"testnet hd key mainnet", testnetHdKey, "", true, true},
 	}
 
 	for _, test := range tests {
@@ -969,6 +1074,7 @@ func TestGetAddressesForPath(t *testing.T) {
 	validHdKey, err := GenerateHDKeyFromString("xprv9s21ZrQH143K4FdJCmPQe1CFUvK3PKVrcp3b5xVr5Bs3cP5ab6ytszeHggTmHoqTXpaa8CgYPxZZzigSGCDjtyWdUDJqPogb1JGWAPkBLdF")
 	require.NoError(t, err)
 	assert.NotNil(t, validHdKey)
+	testnetHdKey := mustTestnetHDKey(t, validHdKey)
 
 	tests := []struct {
 		name             string
@@ -983,7 +1089,8 @@ func TestGetAddressesForPath(t *testing.T) {
 		{"zeroed extended key mainnet", new(bip32.ExtendedKey), 1, "", "", true, true, true},
 		{"valid hd key num 1 mainnet", validHdKey, 1, "1KMxfSfRCkC1jrBAuYaLde4XBzdsWApbdH", "174DL9ZbBWx568ssAg8w2YwW6FTTBwXGEu", false, false, true},
 		{"valid hd key num 2 mainnet", validHdKey, 2, "18s3peTU7fMSwgui54avpnqm1126pRVccw", "1KgZZ3NsJDw3v1GPHBj8ASnxutA1kFxo2i", false, false, true},
-		{"valid hd key num 1 testnet", validHdKey, 1, "mysuxVkQ1mdGWxend7YiTZGr3zEaTcMjrz", "mmaAdCeZzYPKsFMUtF7JrU9pxF4AAgMHK5", false, false, false},
+		{"valid hd key num 1 testnet", testnetHdKey, 1, "mysuxVkQ1mdGWxend7YiTZGr3zEaTcMjrz", "mmaAdCeZzYPKsFMUtF7JrU9pxF4AAgMHK5", false, false, false},
+		{"mainnet hd key num 1 testnet", validHdKey, 1, "", "", true, true, false},
 		{"zeroed extended key testnet", new(bip32.ExtendedKey), 1, "", "", true, true, false},
 	}
 
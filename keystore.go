@@ -0,0 +1,746 @@
+package bitcoin
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bsv-blockchain/go-bt/v2"
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	// ErrKeystoreLocked is returned when a locked keystore is used
+	ErrKeystoreLocked = errors.New("keystore is locked")
+
+	// ErrInvalidPassword is returned when the keystore password is wrong (or the
+	// file has been tampered with)
+	ErrInvalidPassword = errors.New("invalid keystore password")
+
+	// ErrUnsupportedKeystore is returned for an unknown keystore version, KDF or cipher
+	ErrUnsupportedKeystore = errors.New("unsupported keystore")
+
+	// ErrKeyNotFound is returned when no key has the name
+	ErrKeyNotFound = errors.New("key not found")
+
+	// ErrKeyExists is returned when adding a key with a name already in use
+	ErrKeyExists = errors.New("key already exists")
+
+	// ErrInvalidKeyName is returned for an empty key name
+	ErrInvalidKeyName = errors.New("key name cannot be empty")
+
+	// ErrInvalidScryptParams is returned for scrypt parameters that are out of bounds
+	ErrInvalidScryptParams = errors.New("invalid scrypt parameters")
+
+	// ErrInvalidKeyType is returned when an operation does not apply to the key
+	// type (for example a derivation path on a single private key)
+	ErrInvalidKeyType = errors.New("operation not supported by the key type")
+)
+
+const (
+	// keystoreVersion is the version of the keystore file format
+	keystoreVersion = 1
+
+	// keystoreKDF and keystoreCipher name the algorithms of the file format
+	keystoreKDF    = "scrypt"
+	keystoreCipher = "aes-256-gcm"
+
+	// keystoreKeyLength is the length of the AES-256 key derived from the password
+	keystoreKeyLength = 32
+
+	// keystoreSaltLength is the length of the random scrypt salt
+	keystoreSaltLength = 32
+
+	// DefaultScryptN is the default scrypt cost (2^17 takes about 0.3s)
+	DefaultScryptN = 1 << 17
+
+	// DefaultScryptR is the default scrypt block size
+	DefaultScryptR = 8
+
+	// DefaultScryptP is the default scrypt parallelism
+	DefaultScryptP = 1
+
+	// maxScryptN is the highest scrypt cost accepted (2^20)
+	maxScryptN = 1 << 20
+
+	// maxScryptMemory bounds the memory of the scrypt parameters (128*N*r bytes)
+	maxScryptMemory = 1 << 30
+
+	// maxScryptRP bounds r*p, the number of sequential scrypt mixes
+	maxScryptRP = 64
+)
+
+// KeyType is the type of a key in the keystore
+type KeyType string
+
+const (
+	// KeyTypePrivateKey is a single private key
+	KeyTypePrivateKey KeyType = "private_key"
+
+	// KeyTypeHDSeed is an HD seed (the master key of the entry network is derived from it)
+	KeyTypeHDSeed KeyType = "hd_seed"
+
+	// KeyTypeHDKey is an extended private key (xprv or tprv)
+	KeyTypeHDKey KeyType = "hd_key"
+)
+
+// ScryptParams are the cost parameters of the scrypt KDF
+type ScryptParams struct {
+	N int `json:"n"`
+	R int `json:"r"`
+	P int `json:"p"`
+}
+
+// KeyInfo describes a key in the keystore without its secret
+type KeyInfo struct {
+	Name      string            `json:"name"`
+	Type      KeyType           `json:"type"`
+	Network   Network           `json:"network,omitempty"` // Network of HD seeds and HD keys
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// keystoreHeader is the authenticated, unencrypted part of the keystore file.
+type keystoreHeader struct {
+	Version int          `json:"version"`
+	KDF     string       `json:"kdf"`
+	Scrypt  ScryptParams `json:"scrypt"`
+	Salt    []byte       `json:"salt"`
+	Cipher  string       `json:"cipher"`
+}
+
+// keystoreFile is the keystore file: the header, and the entries encrypted
+// with AES-256-GCM using the header as additional data.
+type keystoreFile struct {
+	keystoreHeader
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// keystoreEntry is a key in the encrypted part of the keystore file.
+type keystoreEntry struct {
+	KeyInfo
+	Secret []byte `json:"secret"`
+}
+
+// Keystore is an encrypted file of named private keys, HD seeds and HD keys
+//
+// The file is encrypted with AES-256-GCM under a key derived from the password
+// with scrypt. While unlocked, the keys can sign messages and transactions
+// (SignMessage, CreateTx) without the caller handling them; Lock (or the
+// unlock timeout) zeroes them. It is safe for concurrent use.
+type Keystore struct {
+	mu      sync.Mutex
+	path    string
+	file    keystoreFile
+	entries []*keystoreEntry // nil when locked
+	aesKey  []byte           // nil when locked
+	timer   *time.Timer
+}
+
+// CreateKeystore will create a new, empty and unlocked keystore file at path
+// using the default scrypt parameters
+//
+// Returns an error wrapping fs.ErrExist if the file already exists.
+func CreateKeystore(path string, password []byte) (*Keystore, error) {
+	return CreateKeystoreWithScrypt(path, password, ScryptParams{N: DefaultScryptN, R: DefaultScryptR, P: DefaultScryptP})
+}
+
+// CreateKeystoreWithScrypt will create a new, empty and unlocked keystore file
+// at path with custom scrypt parameters
+func CreateKeystoreWithScrypt(path string, password []byte, params ScryptParams) (*Keystore, error) {
+	header, aesKey, err := newKeystoreHeader(password, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Claim the path (fails if it exists), then write the file over it
+	var file *os.File
+	if file, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600); err != nil { // #nosec G304 -- the caller chooses the keystore path
+		clear(aesKey)
+		return nil, err
+	}
+	_ = file.Close()
+
+	ks := &Keystore{path: path, entries: []*keystoreEntry{}, aesKey: aesKey}
+	if err = ks.write(header, aesKey, ks.entries); err != nil {
+		clear(aesKey)
+		_ = os.Remove(path)
+		return nil, err
+	}
+	return ks, nil
+}
+
+// OpenKeystore will open a keystore file; it is locked until Unlock is called
+func OpenKeystore(path string) (*Keystore, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- the caller chooses the keystore path
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &Keystore{path: path}
+	if err = json.Unmarshal(data, &ks.file); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedKeystore, err)
+	}
+	if err = ks.file.validate(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Unlock will decrypt the keys with the password
+//
+// The keystore locks itself after timeout (a timeout of 0 keeps it unlocked
+// until Lock is called). Returns ErrInvalidPassword for a wrong password.
+func (ks *Keystore) Unlock(password []byte, timeout time.Duration) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	aesKey, entries, err := ks.decrypt(password)
+	if err != nil {
+		return err
+	}
+	ks.lock()
+	ks.aesKey, ks.entries = aesKey, entries
+	if timeout > 0 {
+		// A timer that fires after a later Unlock or Lock must not lock the keystore
+		var timer *time.Timer
+		timer = time.AfterFunc(timeout, func() {
+			ks.mu.Lock()
+			defer ks.mu.Unlock()
+			if ks.timer == timer {
+				ks.lock()
+			}
+		})
+		ks.timer = timer
+	}
+	return nil
+}
+
+// Lock will zero the decrypted keys; the keystore must be unlocked again to use them
+func (ks *Keystore) Lock() {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.lock()
+}
+
+// IsLocked returns true if the keystore is locked
+func (ks *Keystore) IsLocked() bool {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	return ks.entries == nil
+}
+
+// ChangePassword will re-encrypt the keystore with a new password (and a new salt)
+//
+// The keystore does not need to be unlocked, and stays locked or unlocked.
+func (ks *Keystore) ChangePassword(oldPassword, newPassword []byte) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	oldKey, entries, err := ks.decrypt(oldPassword)
+	if err != nil {
+		return err
+	}
+	clear(oldKey)
+	defer zeroEntries(entries)
+
+	header, aesKey, err := newKeystoreHeader(newPassword, ks.file.Scrypt)
+	if err != nil {
+		return err
+	}
+	if err = ks.write(header, aesKey, entries); err != nil {
+		clear(aesKey)
+		return err
+	}
+
+	// An unlocked keystore keeps saving with the new key
+	if ks.entries == nil {
+		clear(aesKey)
+	} else {
+		clear(ks.aesKey)
+		ks.aesKey = aesKey
+	}
+	return nil
+}
+
+// Keys will list the keys in the keystore (sorted by name)
+//
+// Each KeyInfo has its own copy of the metadata.
+func (ks *Keystore) Keys() ([]KeyInfo, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if ks.entries == nil {
+		return nil, ErrKeystoreLocked
+	}
+	keys := make([]KeyInfo, len(ks.entries))
+	for i, entry := range ks.entries {
+		keys[i] = entry.KeyInfo
+		keys[i].Metadata = maps.Clone(entry.Metadata)
+	}
+	return keys, nil
+}
+
+// AddPrivateKey will add a private key to the keystore and save it
+func (ks *Keystore) AddPrivateKey(name string, key *SecretKey, metadata map[string]string) error {
+	if key == nil {
+		return ErrPrivateKeyMissing
+	}
+	secret, err := key.Bytes()
+	if err != nil {
+		return err
+	}
+	defer clear(secret)
+	return ks.add(name, KeyTypePrivateKey, NetworkMainnet, secret, metadata)
+}
+
+// AddHDSeed will add a mainnet HD seed (16 to 64 bytes) to the keystore and save it
+//
+// The caller should zero seed after the call.
+func (ks *Keystore) AddHDSeed(name string, seed []byte, metadata map[string]string) error {
+	return ks.AddHDSeedWithNetwork(name, seed, NetworkMainnet, metadata)
+}
+
+// AddHDSeedWithNetwork will add an HD seed (16 to 64 bytes) for the network
+// to the keystore and save it
+//
+// The caller should zero seed after the call.
+func (ks *Keystore) AddHDSeedWithNetwork(name string, seed []byte, network Network, metadata map[string]string) error {
+	if _, err := bip32.NewMaster(seed, network.Params()); err != nil {
+		return err
+	}
+	return ks.add(name, KeyTypeHDSeed, network, seed, metadata)
+}
+
+// AddHDKey will add an extended private key (xprv or tprv) to the keystore and save it
+func (ks *Keystore) AddHDKey(name string, hdKey *bip32.ExtendedKey, metadata map[string]string) error {
+	if hdKey == nil || !hdKey.IsPrivate() {
+		return fmt.Errorf("%w: an extended private key is required", ErrInvalidKeyType)
+	}
+	secret := []byte(hdKey.String())
+	defer clear(secret)
+	network, _ := GetHDKeyNetwork(hdKey)
+	return ks.add(name, KeyTypeHDKey, network, secret, metadata)
+}
+
+// GeneratePrivateKey will generate a private key inside the keystore, save it
+// and return its public key
+func (ks *Keystore) GeneratePrivateKey(name string, metadata map[string]string) (*ec.PublicKey, error) {
+	key, err := GenerateSecretKey()
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	if err = ks.AddPrivateKey(name, key, metadata); err != nil {
+		return nil, err
+	}
+	return key.PubKey()
+}
+
+// GenerateHDSeed will generate a mainnet HD seed of seedLength bytes (see
+// GenerateHDKey) inside the keystore, save it and return the master xPub
+func (ks *Keystore) GenerateHDSeed(name string, seedLength uint8, metadata map[string]string) (string, error) {
+	return ks.GenerateHDSeedWithNetwork(name, seedLength, NetworkMainnet, metadata)
+}
+
+// GenerateHDSeedWithNetwork will generate an HD seed of seedLength bytes for
+// the network inside the keystore, save it and return the master xPub (or tpub)
+func (ks *Keystore) GenerateHDSeedWithNetwork(name string, seedLength uint8, network Network,
+	metadata map[string]string,
+) (string, error) {
+	if seedLength == 0 {
+		seedLength = RecommendedSeedLength
+	}
+	seed, err := bip32.GenerateSeed(seedLength)
+	if err != nil {
+		return "", err
+	}
+	defer clear(seed)
+	if err = ks.AddHDSeedWithNetwork(name, seed, network, metadata); err != nil {
+		return "", err
+	}
+	return ks.XPub(name)
+}
+
+// Remove will remove a key from the keystore and save it
+func (ks *Keystore) Remove(name string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	index, err := ks.find(name)
+	if err != nil {
+		return err
+	}
+	removed := ks.entries[index]
+	ks.entries = slices.Delete(ks.entries, index, index+1)
+	if err = ks.write(ks.file.keystoreHeader, ks.aesKey, ks.entries); err != nil {
+		ks.entries = slices.Insert(ks.entries, index, removed)
+		return err
+	}
+	clear(removed.Secret)
+	return nil
+}
+
+// XPub will get the xPub of an HD seed or HD key
+func (ks *Keystore) XPub(name string) (string, error) {
+	var xPub string
+	err := ks.useHDKey(name, func(hdKey *bip32.ExtendedKey) (err error) {
+		xPub, err = GetExtendedPublicKey(hdKey)
+		return err
+	})
+	return xPub, err
+}
+
+// PubKey will get the public key of a private key, or of the child at path
+// (indexes from the stored key, hardened from bip32.HardenedKeyStart) of an HD seed or HD key
+func (ks *Keystore) PubKey(name string, path ...uint32) (*ec.PublicKey, error) {
+	var pubKey *ec.PublicKey
+	err := ks.UseKey(name, func(privateKey *ec.PrivateKey) error {
+		pubKey = privateKey.PubKey()
+		return nil
+	}, path...)
+	return pubKey, err
+}
+
+// SignMessage will sign a message (see SignMessage) with a private key, or the
+// child at path of an HD seed or HD key
+func (ks *Keystore) SignMessage(name, message string, sigRefCompressedKey bool, path ...uint32) (string, error) {
+	var signature string
+	err := ks.UseKey(name, func(privateKey *ec.PrivateKey) error {
+		sigBytes, err := bsm.SignMessageWithCompression(privateKey, []byte(message), sigRefCompressedKey)
+		if err != nil {
+			return err
+		}
+		signature = base64.StdEncoding.EncodeToString(sigBytes)
+		return nil
+	}, path...)
+	return signature, err
+}
+
+// CreateTx will create and sign a transaction (see CreateTx) with a private
+// key, or the child at path of an HD seed or HD key
+func (ks *Keystore) CreateTx(name string, utxos []*Utxo, addresses []*PayToAddress,
+	opReturns []OpReturnData, path ...uint32,
+) (*bt.Tx, error) {
+	var tx *bt.Tx
+	err := ks.UseKey(name, func(privateKey *ec.PrivateKey) (err error) {
+		tx, err = CreateTx(utxos, addresses, opReturns, privateKey)
+		return err
+	}, path...)
+	return tx, err
+}
+
+// CreateTxWithChange will create and sign a transaction with a change output
+// (see CreateTxWithChange) with a private key, or the child at path of an HD seed or HD key
+func (ks *Keystore) CreateTxWithChange(name string, utxos []*Utxo, payToAddresses []*PayToAddress,
+	opReturns []OpReturnData, changeAddress string, standardRate, dataRate *bt.Fee, path ...uint32,
+) (*bt.Tx, error) {
+	var tx *bt.Tx
+	err := ks.UseKey(name, func(privateKey *ec.PrivateKey) (err error) {
+		tx, err = CreateTxWithChange(utxos, payToAddresses, opReturns, changeAddress, standardRate, dataRate, privateKey)
+		return err
+	}, path...)
+	return tx, err
+}
+
+// UseKey will call fn with a private key, or the child at path of an HD seed
+// or HD key, wiping it when fn returns
+//
+// fn must not keep a reference to the private key.
+func (ks *Keystore) UseKey(name string, fn func(privateKey *ec.PrivateKey) error, path ...uint32) error {
+	ks.mu.Lock()
+	index, err := ks.find(name)
+	if err != nil {
+		ks.mu.Unlock()
+		return err
+	}
+	entry := ks.entries[index]
+	if entry.Type == KeyTypePrivateKey {
+		if len(path) > 0 {
+			ks.mu.Unlock()
+			return fmt.Errorf("%w: %s is a single private key and has no path", ErrInvalidKeyType, name)
+		}
+		privateKey, _ := ec.PrivateKeyFromBytes(entry.Secret)
+		ks.mu.Unlock()
+		defer ZeroPrivateKey(privateKey)
+		return fn(privateKey)
+	}
+	ks.mu.Unlock()
+
+	return ks.useHDKey(name, func(hdKey *bip32.ExtendedKey) error {
+		child := hdKey
+		for _, index := range path {
+			var childErr error
+			if child, childErr = GetHDKeyChild(child, index); childErr != nil {
+				return childErr
+			}
+			defer child.Zero()
+		}
+		privateKey, keyErr := GetPrivateKeyFromHDKey(child)
+		if keyErr != nil {
+			return keyErr
+		}
+		defer ZeroPrivateKey(privateKey)
+		return fn(privateKey)
+	})
+}
+
+// useHDKey calls fn with the extended key of an HD seed or HD key entry, wiping it after.
+func (ks *Keystore) useHDKey(name string, fn func(hdKey *bip32.ExtendedKey) error) error {
+	ks.mu.Lock()
+	index, err := ks.find(name)
+	if err != nil {
+		ks.mu.Unlock()
+		return err
+	}
+
+	var hdKey *bip32.ExtendedKey
+	switch entry := ks.entries[index]; entry.Type {
+	case KeyTypeHDSeed:
+		hdKey, err = bip32.NewMaster(entry.Secret, entry.Network.Params())
+	case KeyTypeHDKey:
+		hdKey, err = bip32.NewKeyFromString(string(entry.Secret))
+	case KeyTypePrivateKey:
+		err = fmt.Errorf("%w: %s is not an HD key", ErrInvalidKeyType, name)
+	}
+	ks.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	defer hdKey.Zero()
+	return fn(hdKey)
+}
+
+// add adds an entry and saves the keystore.
+func (ks *Keystore) add(name string, keyType KeyType, network Network, secret []byte,
+	metadata map[string]string,
+) error {
+	if name == "" {
+		return ErrInvalidKeyName
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if ks.entries == nil {
+		return ErrKeystoreLocked
+	}
+	index, found := slices.BinarySearchFunc(ks.entries, name, func(entry *keystoreEntry, name string) int {
+		return strings.Compare(entry.Name, name)
+	})
+	if found {
+		return fmt.Errorf("%w: %s", ErrKeyExists, name)
+	}
+
+	entry := &keystoreEntry{
+		KeyInfo: KeyInfo{Name: name, Type: keyType, Network: network, Metadata: maps.Clone(metadata), CreatedAt: time.Now().UTC()},
+		Secret:  bytes.Clone(secret),
+	}
+	ks.entries = slices.Insert(ks.entries, index, entry)
+	if err := ks.write(ks.file.keystoreHeader, ks.aesKey, ks.entries); err != nil {
+		ks.entries = slices.Delete(ks.entries, index, index+1)
+		clear(entry.Secret)
+		return err
+	}
+	return nil
+}
+
+// find returns the index of the named entry; the caller holds the lock.
+func (ks *Keystore) find(name string) (int, error) {
+	if ks.entries == nil {
+		return 0, ErrKeystoreLocked
+	}
+	index, found := slices.BinarySearchFunc(ks.entries, name, func(entry *keystoreEntry, name string) int {
+		return strings.Compare(entry.Name, name)
+	})
+	if !found {
+		return 0, fmt.Errorf("%w: %s", ErrKeyNotFound, name)
+	}
+	return index, nil
+}
+
+// lock zeroes the decrypted keys; the caller holds the lock.
+func (ks *Keystore) lock() {
+	if ks.timer != nil {
+		ks.timer.Stop()
+		ks.timer = nil
+	}
+	zeroEntries(ks.entries)
+	ks.entries = nil
+	clear(ks.aesKey)
+	ks.aesKey = nil
+}
+
+// newKeystoreHeader derives an AES key from the password and a new random salt.
+func newKeystoreHeader(password []byte, params ScryptParams) (keystoreHeader, []byte, error) {
+	if err := params.validate(); err != nil {
+		return keystoreHeader{}, nil, err
+	}
+	salt := make([]byte, keystoreSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return keystoreHeader{}, nil, err
+	}
+	aesKey, err := scrypt.Key(password, salt, params.N, params.R, params.P, keystoreKeyLength)
+	if err != nil {
+		return keystoreHeader{}, nil, err
+	}
+	return keystoreHeader{
+		Version: keystoreVersion,
+		KDF:     keystoreKDF,
+		Scrypt:  params,
+		Salt:    salt,
+		Cipher:  keystoreCipher,
+	}, aesKey, nil
+}
+
+// decrypt derives the AES key from the password and decrypts the entries; the
+// caller holds the lock.
+func (ks *Keystore) decrypt(password []byte) ([]byte, []*keystoreEntry, error) {
+	header := ks.file.keystoreHeader
+	if err := header.validate(); err != nil {
+		return nil, nil, err
+	}
+	aesKey, err := scrypt.Key(password, header.Salt, header.Scrypt.N, header.Scrypt.R, header.Scrypt.P, keystoreKeyLength)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrUnsupportedKeystore, err)
+	}
+
+	var aead cipher.AEAD
+	if aead, err = newKeystoreAEAD(aesKey); err != nil {
+		clear(aesKey)
+		return nil, nil, err
+	}
+	if len(ks.file.Nonce) != aead.NonceSize() {
+		clear(aesKey)
+		return nil, nil, fmt.Errorf("%w: invalid nonce", ErrUnsupportedKeystore)
+	}
+	additionalData, _ := json.Marshal(header)
+	plaintext, err := aead.Open(nil, ks.file.Nonce, ks.file.Ciphertext, additionalData)
+	if err != nil {
+		clear(aesKey)
+		return nil, nil, ErrInvalidPassword
+	}
+	defer clear(plaintext)
+
+	var entries []*keystoreEntry
+	if err = json.Unmarshal(plaintext, &entries); err != nil {
+		clear(aesKey)
+		return nil, nil, fmt.Errorf("%w: %w", ErrUnsupportedKeystore, err)
+	}
+	if entries == nil {
+		entries = []*keystoreEntry{}
+	}
+	return aesKey, entries, nil
+}
+
+// write encrypts the entries with AES-256-GCM, using the header as additional
+// data, and atomically replaces the keystore file; the caller holds the lock.
+func (ks *Keystore) write(header keystoreHeader, aesKey []byte, entries []*keystoreEntry) error {
+	plaintext, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	defer clear(plaintext)
+
+	var aead cipher.AEAD
+	if aead, err = newKeystoreAEAD(aesKey); err != nil {
+		return err
+	}
+	file := keystoreFile{keystoreHeader: header, Nonce: make([]byte, aead.NonceSize())}
+	if _, err = rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	additionalData, _ := json.Marshal(header)
+	file.Ciphertext = aead.Seal(nil, file.Nonce, plaintext, additionalData)
+
+	var data []byte
+	if data, err = json.MarshalIndent(file, "", "  "); err != nil {
+		return err
+	}
+	if err = writeFileAtomic(ks.path, data); err != nil {
+		return err
+	}
+	ks.file = file
+	return nil
+}
+
+// validate checks the version, algorithms, scrypt parameters and salt, so a
+// crafted file cannot make scrypt allocate unbounded memory.
+func (h keystoreHeader) validate() error {
+	if h.Version != keystoreVersion || h.KDF != keystoreKDF || h.Cipher != keystoreCipher {
+		return fmt.Errorf("%w: version %d, kdf %q, cipher %q", ErrUnsupportedKeystore, h.Version, h.KDF, h.Cipher)
+	}
+	if len(h.Salt) != keystoreSaltLength {
+		return fmt.Errorf("%w: salt is %d bytes, expected %d", ErrUnsupportedKeystore, len(h.Salt), keystoreSaltLength)
+	}
+	if err := h.Scrypt.validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnsupportedKeystore, err)
+	}
+	return nil
+}
+
+// validate checks that N is a power of two up to maxScryptN and that the memory
+// (128*N*r) and r*p are bounded.
+func (p ScryptParams) validate() error {
+	switch {
+	case p.N < 2 || p.N > maxScryptN || p.N&(p.N-1) != 0:
+		return fmt.Errorf("%w: scrypt N must be a power of two up to %d", ErrInvalidScryptParams, maxScryptN)
+	case p.R < 1 || p.P < 1 || p.R > maxScryptRP || p.P > maxScryptRP || p.R*p.P > maxScryptRP:
+		return fmt.Errorf("%w: scrypt r*p must be between 1 and %d", ErrInvalidScryptParams, maxScryptRP)
+	case 128*int64(p.N)*int64(p.R) > maxScryptMemory:
+		return fmt.Errorf("%w: scrypt memory (128*N*r) is over %d bytes", ErrInvalidScryptParams, maxScryptMemory)
+	}
+	return nil
+}
+
+// newKeystoreAEAD returns AES-256-GCM with the key.
+func newKeystoreAEAD(aesKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(aesKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// zeroEntries zeroes the secrets of the entries.
+func zeroEntries(entries []*keystoreEntry) {
+	for _, entry := range entries {
+		clear(entry.Secret)
+	}
+}
+
+// writeFileAtomic writes data to a temporary file (mode 0600) and renames it over path.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,554 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKeystoreScrypt are cheap scrypt parameters so the tests run quickly
+var testKeystoreScrypt = ScryptParams{N: 1 << 10, R: 8, P: 1} //nolint:gochecknoglobals // test fixture
+
+// testKeystorePassword is the password of the test keystores
+const testKeystorePassword = "correct horse battery staple"
+
+// mustTestKeystore creates an unlocked keystore in a temporary directory holding
+// the test private key as "key" and the BIP32 test vector master key as "hd",
+// failing the test on error
+func mustTestKeystore(t *testing.T) (*Keystore, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	ks, err := CreateKeystoreWithScrypt(path, []byte(testKeystorePassword), testKeystoreScrypt)
+	require.NoError(t, err)
+	require.NoError(t, ks.AddPrivateKey("key", mustSecretKey(t), map[string]string{"label": "test"}))
+	require.NoError(t, ks.AddHDKey("hd", mustBIP32Master(t), nil))
+	return ks, path
+}
+
+// mustBIP32Master parses testBIP32Master, failing the test on error
+func mustBIP32Master(t *testing.T) *bip32.ExtendedKey {
+	t.Helper()
+	masterKey, err := bip32.NewKeyFromString(testBIP32Master)
+	require.NoError(t, err)
+	return masterKey
+}
+
+// mustKeystoreTestKey parses testPrivateKeyHex (the "key" of the test keystores),
+// failing the test on error
+func mustKeystoreTestKey(t *testing.T) *ec.PrivateKey {
+	t.Helper()
+	privateKey, err := PrivateKeyFromString(testPrivateKeyHex)
+	require.NoError(t, err)
+	return privateKey
+}
+
+// TestCreateKeystore will test creating and opening keystore files
+func TestCreateKeystore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("create and reopen", func(t *testing.T) {
+		t.Parallel()
+		_, path := mustTestKeystore(t)
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, fs.FileMode(0o600), info.Mode().Perm())
+
+		data, err := os.ReadFile(path) //nolint:gosec // test file
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), testPrivateKeyHex)
+		assert.NotContains(t, string(data), testBIP32Master)
+		assert.Contains(t, string(data), `"kdf": "scrypt"`)
+		assert.Contains(t, string(data), `"cipher": "aes-256-gcm"`)
+
+		ks, err := OpenKeystore(path)
+		require.NoError(t, err)
+		assert.True(t, ks.IsLocked())
+		require.NoError(t, ks.Unlock([]byte(testKeystorePassword), 0))
+		assert.False(t, ks.IsLocked())
+
+		keys, err := ks.Keys()
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.Equal(t, "hd", keys[0].Name)
+		assert.Equal(t, KeyTypeHDKey, keys[0].Type)
+		assert.Equal(t, "key", keys[1].Name)
+		assert.Equal(t, KeyTypePrivateKey, keys[1].Type)
+		assert.Equal(t, map[string]string{"label": "test"}, keys[1].Metadata)
+		assert.False(t, keys[1].CreatedAt.IsZero())
+
+		// Changing the listed metadata does not change the keystore
+		keys[1].Metadata["label"] = "changed"
+		keys, err = ks.Keys()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"label": "test"}, keys[1].Metadata)
+
+		// Neither does changing the metadata it was added with
+		metadata := map[string]string{"label": "new"}
+		_, err = ks.GeneratePrivateKey("new", metadata)
+		require.NoError(t, err)
+		metadata["label"] = "changed"
+		keys, err = ks.Keys()
+		require.NoError(t, err)
+		require.Len(t, keys, 3)
+		assert.Equal(t, map[string]string{"label": "new"}, keys[2].Metadata)
+	})
+
+	t.Run("file exists", func(t *testing.T) {
+		t.Parallel()
+		_, path := mustTestKeystore(t)
+		_, err := CreateKeystoreWithScrypt(path, []byte(testKeystorePassword), testKeystoreScrypt)
+		require.ErrorIs(t, err, fs.ErrExist)
+	})
+
+	t.Run("invalid scrypt parameters", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "keystore.json")
+		_, err := CreateKeystoreWithScrypt(path, []byte(testKeystorePassword), ScryptParams{N: 1000, R: 8, P: 1})
+		require.Error(t, err)
+		_, err = os.Stat(path)
+		require.ErrorIs(t, err, fs.ErrNotExist)
+
+		for _, params := range []ScryptParams{
+			{N: 1000, R: 8, P: 1},
+			{N: 0, R: 8, P: 1},
+			{N: 1 << 21, R: 1, P: 1},
+			{N: 1 << 20, R: 16, P: 1},
+			{N: 1 << 10, R: 0, P: 1},
+			{N: 1 << 10, R: 8, P: 16},
+		} {
+			_, err = CreateKeystoreWithScrypt(path, []byte(testKeystorePassword), params)
+			require.ErrorIs(t, err, ErrInvalidScryptParams)
+		}
+	})
+
+	t.Run("crafted header", func(t *testing.T) {
+		t.Parallel()
+		tests := []struct {
+			name  string
+			field string
+			value any
+		}{
+			{"huge scrypt N", "scrypt", map[string]int{"n": 1 << 30, "r": 8, "p": 1}},
+			{"huge scrypt r", "scrypt", map[string]int{"n": 1 << 10, "r": 1 << 20, "p": 1}},
+			{"huge scrypt p", "scrypt", map[string]int{"n": 1 << 10, "r": 8, "p": 1 << 20}},
+			{"short salt", "salt", []byte{0x01}},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				t.Parallel()
+				_, path := mustTestKeystore(t)
+				data, err := os.ReadFile(path) //nolint:gosec // test file
+				require.NoError(t, err)
+				var file map[string]any
+				require.NoError(t, json.Unmarshal(data, &file))
+				file[test.field] = test.value
+				data, err = json.Marshal(file)
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(path, data, 0o600))
+
+				_, err = OpenKeystore(path)
+				require.ErrorIs(t, err, ErrUnsupportedKeystore)
+			})
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Parallel()
+		_, err := OpenKeystore(filepath.Join(t.TempDir(), "missing.json"))
+		require.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		t.Parallel()
+		_, path := mustTestKeystore(t)
+		data, err := os.ReadFile(path) //nolint:gosec // test file
+		require.NoError(t, err)
+		data = bytes.Replace(data, []byte(`"version": 1`), []byte(`"version": 2`), 1)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		_, err = OpenKeystore(path)
+		require.ErrorIs(t, err, ErrUnsupportedKeystore)
+	})
+
+	t.Run("not json", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "keystore.json")
+		require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+		_, err := OpenKeystore(path)
+		require.ErrorIs(t, err, ErrUnsupportedKeystore)
+	})
+}
+
+// TestKeystore_Unlock will test locking, unlocking and the unlock timeout
+func TestKeystore_Unlock(t *testing.T) {
+	t.Parallel()
+
+	t.Run("wrong password", func(t *testing.T) {
+		t.Parallel()
+		_, path := mustTestKeystore(t)
+		ks, err := OpenKeystore(path)
+		require.NoError(t, err)
+		require.ErrorIs(t, ks.Unlock([]byte("wrong"), 0), ErrInvalidPassword)
+		assert.True(t, ks.IsLocked())
+	})
+
+	t.Run("tampered header", func(t *testing.T) {
+		t.Parallel()
+		_, path := mustTestKeystore(t)
+		data, err := os.ReadFile(path) //nolint:gosec // test file
+		require.NoError(t, err)
+
+		// The header is authenticated, so changing the scrypt parameters fails
+		var file map[string]any
+		require.NoError(t, json.Unmarshal(data, &file))
+		file["scrypt"].(map[string]any)["p"] = 2
+		data, err = json.Marshal(file)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		ks, err := OpenKeystore(path)
+		require.NoError(t, err)
+		require.ErrorIs(t, ks.Unlock([]byte(testKeystorePassword), 0), ErrInvalidPassword)
+	})
+
+	t.Run("locked keystore", func(t *testing.T) {
+		t.Parallel()
+		ks, _ := mustTestKeystore(t)
+		ks.Lock()
+		assert.True(t, ks.IsLocked())
+
+		_, err := ks.Keys()
+		require.ErrorIs(t, err, ErrKeystoreLocked)
+		_, err = ks.SignMessage("key", "message", true)
+		require.ErrorIs(t, err, ErrKeystoreLocked)
+		_, err = ks.XPub("hd")
+		require.ErrorIs(t, err, ErrKeystoreLocked)
+		require.ErrorIs(t, ks.AddPrivateKey("other", mustSecretKey(t), nil), ErrKeystoreLocked)
+		require.ErrorIs(t, ks.Remove("key"), ErrKeystoreLocked)
+
+		require.NoError(t, ks.Unlock([]byte(testKeystorePassword), 0))
+		_, err = ks.SignMessage("key", "message", true)
+		require.NoError(t, err)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+		ks, _ := mustTestKeystore(t)
+		require.NoError(t, ks.Unlock([]byte(testKeystorePassword), 10*time.Millisecond))
+		assert.False(t, ks.IsLocked())
+		assert.Eventually(t, ks.IsLocked, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("unlock again resets the timeout", func(t *testing.T) {
+		t.Parallel()
+		ks, _ := mustTestKeystore(t)
+		require.NoError(t, ks.Unlock([]byte(testKeystorePassword), 10*time.Millisecond))
+		require.NoError(t, ks.Unlock([]byte(testKeystorePassword), 0))
+		time.Sleep(50 * time.Millisecond)
+		assert.False(t, ks.IsLocked())
+	})
+}
+
+// TestKeystore_ChangePassword will test changing the keystore password
+func TestKeystore_ChangePassword(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unlocked", func(t *testing.T) {
+		t.Parallel()
+		ks, path := mustTestKeystore(t)
+		require.NoError(t, ks.ChangePassword([]byte(testKeystorePassword), []byte("new password")))
+		assert.False(t, ks.IsLocked())
+
+		// Changes are saved with the new password
+		require.NoError(t, ks.AddHDSeed("seed", bytes.Repeat([]byte{0x01}, 32), nil))
+
+		reopened, err := OpenKeystore(path)
+		require.NoError(t, err)
+		require.ErrorIs(t, reopened.Unlock([]byte(testKeystorePassword), 0), ErrInvalidPassword)
+		require.NoError(t, reopened.Unlock([]byte("new password"), 0))
+		keys, err := reopened.Keys()
+		require.NoError(t, err)
+		assert.Len(t, keys, 3)
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		t.Parallel()
+		ks, _ := mustTestKeystore(t)
+		ks.Lock()
+		require.NoError(t, ks.ChangePassword([]byte(testKeystorePassword), []byte("new password")))
+		assert.True(t, ks.IsLocked())
+		require.NoError(t, ks.Unlock([]byte("new password"), 0))
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		t.Parallel()
+		ks, _ := mustTestKeystore(t)
+		require.ErrorIs(t, ks.ChangePassword([]byte("wrong"), []byte("new password")), ErrInvalidPassword)
+		ks.Lock()
+		require.NoError(t, ks.Unlock([]byte(testKeystorePassword), 0))
+	})
+}
+
+// TestKeystore_Keys will test adding, generating and removing keys
+func TestKeystore_Keys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("add errors", func(t *testing.T) {
+		t.Parallel()
+		ks, _ := mustTestKeystore(t)
+		require.ErrorIs(t, ks.AddPrivateKey("key", mustSecretKey(t), nil), ErrKeyExists)
+		require.ErrorIs(t, ks.AddPrivateKey("", mustSecretKey(t), nil), ErrInvalidKeyName)
+		require.ErrorIs(t, ks.AddPrivateKey("nil", nil, nil), ErrPrivateKeyMissing)
+
+		destroyed := mustSecretKey(t)
+		destroyed.Destroy()
+		require.ErrorIs(t, ks.AddPrivateKey("destroyed", destroyed, nil), ErrSecretKeyDestroyed)
+
+		require.Error(t, ks.AddHDSeed("short", []byte{0x01}, nil))
+
+		xPub, err := mustBIP32Master(t).Neuter()
+		require.NoError(t, err)
+		require.ErrorIs(t, ks.AddHDKey("xpub", xPub, nil), ErrInvalidKeyType)
+		require.ErrorIs(t, ks.AddHDKey("nil", nil, nil), ErrInvalidKeyType)
+	})
+
+	t.Run("generate", func(t *testing.T) {
+		t.Parallel()
+		ks, path := mustTestKeystore(t)
+		pubKey, err := ks.GeneratePrivateKey("generated", nil)
+		require.NoError(t, err)
+		xPub, err := ks.GenerateHDSeed("seed", 0, nil)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(xPub, "xpub"))
+
+		reopened, err := OpenKeystore(path)
+		require.NoError(t, err)
+		require.NoError(t, reopened.Unlock([]byte(testKeystorePassword), 0))
+		reopenedPubKey, err := reopened.PubKey("generated")
+		require.NoError(t, err)
+		assert.True(t, pubKey.IsEqual(reopenedPubKey))
+		reopenedXPub, err := reopened.XPub("seed")
+		require.NoError(t, err)
+		assert.Equal(t, xPub, reopenedXPub)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		t.Parallel()
+		ks, path := mustTestKeystore(t)
+		require.NoError(t, ks.Remove("key"))
+		require.ErrorIs(t, ks.Remove("key"), ErrKeyNotFound)
+		_, err := ks.PubKey("key")
+		require.ErrorIs(t, err, ErrKeyNotFound)
+
+		reopened, err := OpenKeystore(path)
+		require.NoError(t, err)
+		require.NoError(t, reopened.Unlock([]byte(testKeystorePassword), 0))
+		keys, err := reopened.Keys()
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.Equal(t, "hd", keys[0].Name)
+	})
+
+	t.Run("hd seed", func(t *testing.T) {
+		t.Parallel()
+		ks, _ := mustTestKeystore(t)
+		seed := bytes.Repeat([]byte{0x01}, 32)
+		require.NoError(t, ks.AddHDSeed("seed", seed, nil))
+
+		masterKey, err := bip32.NewMaster(seed, &chaincfg.MainNet)
+		require.NoError(t, err)
+		expected, err := GetExtendedPublicKey(masterKey)
+		require.NoError(t, err)
+		xPub, err := ks.XPub("seed")
+		require.NoError(t, err)
+		assert.Equal(t, expected, xPub)
+	})
+
+	t.Run("testnet hd seed", func(t *testing.T) {
+		t.Parallel()
+		ks, path := mustTestKeystore(t)
+		seed := bytes.Repeat([]byte{0x01}, 32)
+		require.NoError(t, ks.AddHDSeedWithNetwork("seed", seed, NetworkTestnet, nil))
+		tPub, err := ks.GenerateHDSeedWithNetwork("generated", 0, NetworkTestnet, nil)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(tPub, "tpub"))
+
+		masterKey, err := bip32.NewMaster(seed, &chaincfg.TestNet)
+		require.NoError(t, err)
+		expected, err := GetExtendedPublicKey(masterKey)
+		require.NoError(t, err)
+
+		// The network is kept in the file
+		reopened, err := OpenKeystore(path)
+		require.NoError(t, err)
+		require.NoError(t, reopened.Unlock([]byte(testKeystorePassword), 0))
+		xPub, err := reopened.XPub("seed")
+		require.NoError(t, err)
+		assert.Equal(t, expected, xPub)
+
+		keys, err := reopened.Keys()
+		require.NoError(t, err)
+		networks := make(map[string]Network, len(keys))
+		for _, key := range keys {
+			networks[key.Name] = key.Network
+		}
+		assert.Equal(t, map[string]Network{
+			"generated": NetworkTestnet, "hd": NetworkMainnet, "key": NetworkMainnet, "seed": NetworkTestnet,
+		}, networks)
+	})
+}
+
+// TestKeystore_UseKey will test using keys without handling them
+func TestKeystore_UseKey(t *testing.T) {
+	t.Parallel()
+
+	t.Run("private key", func(t *testing.T) {
+		t.Parallel()
+		ks, _ := mustTestKeystore(t)
+		pubKey, err := ks.PubKey("key")
+		require.NoError(t, err)
+		assert.Equal(t, mustKeystoreTestKey(t).PubKey().Compressed(), pubKey.Compressed())
+
+		_, err = ks.PubKey("key", 0)
+		require.ErrorIs(t, err, ErrInvalidKeyType)
+		_, err = ks.XPub("key")
+		require.ErrorIs(t, err, ErrInvalidKeyType)
+		_, err = ks.PubKey("missing")
+		require.ErrorIs(t, err, ErrKeyNotFound)
+	})
+
+	t.Run("hd key path", func(t *testing.T) {
+		t.Parallel()
+		ks, _ := mustTestKeystore(t)
+		hdKey, err := GetHDKeyByPath(mustBIP32Master(t), 0, 7)
+		require.NoError(t, err)
+		expected, err := hdKey.ECPubKey()
+		require.NoError(t, err)
+
+		pubKey, err := ks.PubKey("hd", 0, 7)
+		require.NoError(t, err)
+		assert.True(t, expected.IsEqual(pubKey))
+
+		xPub, err := ks.XPub("hd")
+		require.NoError(t, err)
+		expectedXPub, err := GetExtendedPublicKey(mustBIP32Master(t))
+		require.NoError(t, err)
+		assert.Equal(t, expectedXPub, xPub)
+	})
+
+	t.Run("key is wiped after use", func(t *testing.T) {
+		t.Parallel()
+		ks, _ := mustTestKeystore(t)
+		var used *ec.PrivateKey
+		require.NoError(t, ks.UseKey("key", func(privateKey *ec.PrivateKey) error {
+			used = privateKey
+			assert.Equal(t, testPrivateKeyHex, privateKey.Hex())
+			return nil
+		}))
+		assert.Zero(t, used.D.Sign())
+
+		// The stored key is not affected
+		pubKey, err := ks.PubKey("key")
+		require.NoError(t, err)
+		assert.Equal(t, mustKeystoreTestKey(t).PubKey().Compressed(), pubKey.Compressed())
+	})
+
+	t.Run("fn error", func(t *testing.T) {
+		t.Parallel()
+		ks, _ := mustTestKeystore(t)
+		require.ErrorIs(t, ks.UseKey("hd", func(*ec.PrivateKey) error {
+			return ErrKeyNotFound
+		}), ErrKeyNotFound)
+	})
+
+	t.Run("sign message", func(t *testing.T) {
+		t.Parallel()
+		ks, _ := mustTestKeystore(t)
+		signature, err := ks.SignMessage("key", "test message", true)
+		require.NoError(t, err)
+		expected, err := SignMessage(testPrivateKeyHex, "test message", true)
+		require.NoError(t, err)
+		assert.Equal(t, expected, signature)
+		address, err := GetAddressFromPrivateKey(mustKeystoreTestKey(t), true, true)
+		require.NoError(t, err)
+		require.NoError(t, VerifyMessage(address, signature, "test message", true))
+	})
+
+	t.Run("create tx", func(t *testing.T) {
+		t.Parallel()
+		ks, _ := mustTestKeystore(t)
+		utxos := []*Utxo{{TxID: testTxID, Vout: 0, ScriptPubKey: testScriptPubKey, Satoshis: 1000}}
+		payTo := []*PayToAddress{{Address: testAddress, Satoshis: 500}}
+
+		tx, err := ks.CreateTx("key", utxos, payTo, nil)
+		require.NoError(t, err)
+		expected, err := CreateTx(utxos, payTo, nil, mustKeystoreTestKey(t))
+		require.NoError(t, err)
+		assert.Equal(t, expected.String(), tx.String())
+
+		tx, err = ks.CreateTxWithChange("key", utxos, payTo, nil, testAddress2, nil, nil)
+		require.NoError(t, err)
+		expected, err = CreateTxWithChange(utxos, payTo, nil, testAddress2, nil, nil, mustKeystoreTestKey(t))
+		require.NoError(t, err)
+		assert.Equal(t, expected.String(), tx.String())
+	})
+}
+
+// ExampleCreateKeystore example using CreateKeystore()
+func ExampleCreateKeystore() {
+	dir, err := os.MkdirTemp("", "keystore")
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	var ks *Keystore
+	if ks, err = CreateKeystore(filepath.Join(dir, "keystore.json"), []byte("password")); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	defer ks.Lock()
+
+	var hdKey *bip32.ExtendedKey
+	if hdKey, err = bip32.NewKeyFromString(testBIP32Master); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	if err = ks.AddHDKey("wallet", hdKey, map[string]string{"label": "savings"}); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	var signature string
+	if signature, err = ks.SignMessage("wallet", "hello", true, 0, 0); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("signed: %t", signature != "")
+	// Output:signed: true
+}
+
+// BenchmarkKeystore_SignMessage benchmarks the method SignMessage()
+func BenchmarkKeystore_SignMessage(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "keystore.json")
+	ks, _ := CreateKeystoreWithScrypt(path, []byte(testKeystorePassword), testKeystoreScrypt)
+	key, _ := GenerateSecretKey()
+	_ = ks.AddPrivateKey("key", key, nil)
+	for b.Loop() {
+		_, _ = ks.SignMessage("key", "test message", true)
+	}
+}
@@ -292,7 +292,7 @@ func TestHDKeyExtended(t *testing.T) {
 
 	t.Run("get addresses for path - testnet", func(t *testing.T) {
 		t.Parallel()
-		key := mustHDKey(t)
+		key := mustTestnetHDKey(t, mustHDKey(t))
 
 		addresses, err := GetAddressesForPath(key, 0, false)
 		require.NoError(t, err)
@@ -0,0 +1,65 @@
+package bitcoin
+
+import (
+	"errors"
+
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+)
+
+var (
+	// ErrNetworkMismatch is returned when a key or address belongs to a
+	// different network than the one requested
+	ErrNetworkMismatch = errors.New("network mismatch")
+
+	// ErrUnknownNetwork is returned when the network of a key cannot be determined
+	ErrUnknownNetwork = errors.New("unknown network")
+)
+
+// Network is a Bitcoin (BSV) network
+type Network uint8
+
+const (
+	// NetworkMainnet is the main network (xprv/xpub, addresses start with 1)
+	NetworkMainnet Network = iota
+
+	// NetworkTestnet is the test network (tprv/tpub, addresses start with m or n)
+	NetworkTestnet
+
+	// NetworkRegtest is the regression test network; it shares the testnet
+	// version bytes, so keys and addresses are identical to testnet
+	NetworkRegtest
+)
+
+// String returns the name of the network
+func (n Network) String() string {
+	switch n {
+	case NetworkMainnet:
+		return "mainnet"
+	case NetworkTestnet:
+		return "testnet"
+	case NetworkRegtest:
+		return "regtest"
+	}
+	return "unknown"
+}
+
+// IsMainnet returns true for the main network
+func (n Network) IsMainnet() bool {
+	return n == NetworkMainnet
+}
+
+// Params returns the chain parameters (version bytes) of the network
+func (n Network) Params() *chaincfg.Params {
+	if n.IsMainnet() {
+		return &chaincfg.MainNet
+	}
+	return &chaincfg.TestNet
+}
+
+// networkFromMainnet converts the mainnet flag used across the package.
+func networkFromMainnet(mainnet bool) Network {
+	if mainnet {
+		return NetworkMainnet
+	}
+	return NetworkTestnet
+}
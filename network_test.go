@@ -0,0 +1,34 @@
+package bitcoin
+
+import (
+	"testing"
+
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNetwork will test the methods of Network
+func TestNetwork(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		network  Network
+		name     string
+		mainnet  bool
+		expected *chaincfg.Params
+	}{
+		{NetworkMainnet, "mainnet", true, &chaincfg.MainNet},
+		{NetworkTestnet, "testnet", false, &chaincfg.TestNet},
+		{NetworkRegtest, "regtest", false, &chaincfg.TestNet},
+		{Network(99), "unknown", false, &chaincfg.TestNet},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.name, test.network.String())
+			assert.Equal(t, test.mainnet, test.network.IsMainnet())
+			assert.Equal(t, test.expected, test.network.Params())
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/bsv-blockchain/go-bt/v2"
+	"github.com/bsv-blockchain/go-bt/v2/bscript"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	script "github.com/bsv-blockchain/go-sdk/script"
+)
+
+// OrdinalSatoshis is the value of a 1Sat ordinal output
+const OrdinalSatoshis uint64 = 1
+
+var (
+	// ErrInvalidInscription is returned when an inscription is missing its content type or content
+	ErrInvalidInscription = errors.New("invalid inscription")
+
+	// ErrNoFeeUtxos is returned when only 1Sat ordinal utxos are left to pay the fee
+	ErrNoFeeUtxos = errors.New("no utxos to pay the fee (1Sat ordinals are not spent as fee)")
+)
+
+// InscriptionOutput is a 1Sat ordinal inscription output: the inscription,
+// locked to an address, with optional metadata after an OP_RETURN (usually a
+// MAP command like NewMAPSet("app", "myapp", "type", "ord"))
+//
+// Vout is set by GetInscriptions and ignored when building.
+type InscriptionOutput struct {
+	Vout        uint32
+	Address     string
+	Inscription *Inscription
+	Metadata    OpReturnData
+}
+
+// InscriptionScript will build the locking script of an inscription output:
+// OP_FALSE OP_IF "ord" OP_1 <content type> OP_0 <content> OP_ENDIF <P2PKH>
+// [OP_RETURN <metadata>...]
+func InscriptionScript(output *InscriptionOutput) ([]byte, error) {
+	if output.Inscription == nil || output.Inscription.ContentType == "" || len(output.Inscription.Content) == 0 {
+		return nil, fmt.Errorf("%w: content type and content are required", ErrInvalidInscription)
+	}
+	if output.Address == "" {
+		return nil, ErrMissingAddress
+	}
+
+	lock, err := bscript.NewP2PKHFromAddress(output.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	b := NewScriptBuilder().
+		AddOps(script.OpFALSE, script.OpIF).
+		AddString("ord").
+		AddOp(script.Op1).
+		AddString(output.Inscription.ContentType).
+		AddOp(script.OpFALSE).
+		AddPushData(output.Inscription.Content).
+		AddOp(script.OpENDIF).
+		AddScript(*lock)
+	if len(output.Metadata) > 0 {
+		b.AddOp(script.OpRETURN)
+		for _, data := range output.Metadata {
+			b.AddPushData(data)
+		}
+	}
+	return b.Script()
+}
+
+// GetInscriptions will get the inscriptions from every output of a transaction,
+// with the address that owns them (on the network) and their metadata pushes
+//
+// Expects tx to not be nil (otherwise will panic)
+func GetInscriptions(tx *bt.Tx, network Network) []*InscriptionOutput {
+	var inscriptions []*InscriptionOutput
+	for vout, output := range tx.Outputs {
+		if output.LockingScript == nil {
+			continue
+		}
+		classified := ClassifyScriptBytes(*output.LockingScript, network)
+		if classified.Type != ScriptOrdinal {
+			continue
+		}
+
+		inscription := &InscriptionOutput{
+			Vout:        uint32(vout), // #nosec G115 -- outputs are limited by the tx size
+			Inscription: classified.Inscription,
+			Metadata:    opReturnPushes(*output.LockingScript),
+		}
+		if len(classified.Addresses) > 0 {
+			inscription.Address = classified.Addresses[0]
+		}
+		inscriptions = append(inscriptions, inscription)
+	}
+	return inscriptions
+}
+
+// IsOrdinalUtxo will return true if a utxo holds a 1Sat ordinal: a 1 satoshi
+// output or an inscription script
+//
+// The 1 satoshi check is a heuristic (it cannot tell an ordinal from any other
+// 1 satoshi output), so it errs on the side of not spending ordinals as fees.
+func IsOrdinalUtxo(utxo *Utxo) bool {
+	if utxo.Satoshis == OrdinalSatoshis {
+		return true
+	}
+	raw, err := hex.DecodeString(utxo.ScriptPubKey)
+	return err == nil && ClassifyScriptBytes(raw, NetworkMainnet).Type == ScriptOrdinal
+}
+
+// SpendableUtxos will split utxos into the ones that can pay fees and the
+// 1Sat ordinals (see IsOrdinalUtxo), keeping their order
+func SpendableUtxos(utxos []*Utxo) (spendable, ordinals []*Utxo) {
+	for _, utxo := range utxos {
+		if IsOrdinalUtxo(utxo) {
+			ordinals = append(ordinals, utxo)
+		} else {
+			spendable = append(spendable, utxo)
+		}
+	}
+	return spendable, ordinals
+}
+
+// CreateInscriptionTx will create a signed transaction minting inscriptions
+// (1 satoshi each), paid for by the utxos that are not 1Sat ordinals, with
+// the change (after fees) sent to the change address
+//
+// Ordinal utxos are never used as fee inputs, so they are not burned as fees.
+// Rates default to the CalculateFeeForTx defaults when nil.
+func CreateInscriptionTx(utxos []*Utxo, inscriptions []*InscriptionOutput, changeAddress string,
+	standardRate, dataRate *bt.Fee, privateKey *ec.PrivateKey,
+) (*bt.Tx, error) {
+	return createOrdinalTx(nil, utxos, inscriptions, changeAddress, standardRate, dataRate, privateKey)
+}
+
+// createOrdinalTx creates a signed tx spending the ordinals first and then
+// the utxos that are not ordinals, with the inscriptions first and the change
+// last, so ordinals keep their position.
+func createOrdinalTx(ordinals, utxos []*Utxo, inscriptions []*InscriptionOutput, changeAddress string,
+	standardRate, dataRate *bt.Fee, privateKey *ec.PrivateKey,
+) (*bt.Tx, error) {
+	switch {
+	case len(inscriptions) == 0:
+		return nil, fmt.Errorf("%w: no inscriptions", ErrInvalidInscription)
+	case changeAddress == "":
+		return nil, ErrChangeAddressRequired
+	case privateKey == nil:
+		return nil, ErrPrivateKeyMissing
+	}
+
+	spendable, _ := SpendableUtxos(utxos)
+	if len(spendable) == 0 {
+		return nil, ErrNoFeeUtxos
+	}
+
+	tx := bt.NewTx()
+	var totalSatoshis uint64
+	for _, utxo := range append(append([]*Utxo{}, ordinals...), spendable...) {
+		if err := tx.From(utxo.TxID, utxo.Vout, utxo.ScriptPubKey, utxo.Satoshis); err != nil {
+			return nil, err
+		}
+		totalSatoshis += utxo.Satoshis
+	}
+
+	for _, inscription := range inscriptions {
+		lockingScript, err := InscriptionScript(inscription)
+		if err != nil {
+			return nil, err
+		}
+		tx.AddOutput(&bt.Output{Satoshis: OrdinalSatoshis, LockingScript: bscript.NewFromBytes(lockingScript)})
+	}
+	inscribed := uint64(len(inscriptions)) * OrdinalSatoshis
+
+	// Sign a draft with all the change to calculate the fee (+1 sat, see draftTx)
+	changeScript, err := bscript.NewP2PKHFromAddress(changeAddress)
+	if err != nil {
+		return nil, err
+	}
+	if totalSatoshis <= inscribed {
+		return nil, fmt.Errorf("%w: need %d + (fee), found %d", ErrInsufficientFunds, inscribed, totalSatoshis)
+	}
+	change := &bt.Output{Satoshis: totalSatoshis - inscribed, LockingScript: changeScript}
+	tx.AddOutput(change)
+	if err = SignTx(tx, privateKey); err != nil {
+		return nil, err
+	}
+
+	fee := CalculateFeeForTx(tx, standardRate, dataRate) + 1
+	if inscribed+fee > totalSatoshis {
+		return nil, fmt.Errorf("%w: need %d + %d (fee), found %d", ErrInsufficientFunds, inscribed, fee, totalSatoshis)
+	}
+
+	// Change of a single satoshi would be taken for a 1Sat ordinal (see
+	// IsOrdinalUtxo), so it goes to the fee; without change the fee is a
+	// little lower, so the tx still pays enough
+	if change.Satoshis = totalSatoshis - inscribed - fee; change.Satoshis <= OrdinalSatoshis {
+		tx.Outputs = tx.Outputs[:len(tx.Outputs)-1]
+	}
+	if err = SignTx(tx, privateKey); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
@@ -0,0 +1,231 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/bsv-blockchain/go-bt/v2"
+	"github.com/bsv-blockchain/go-bt/v2/bscript"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInscriptionScript will test the method InscriptionScript()
+func TestInscriptionScript(t *testing.T) {
+	t.Parallel()
+
+	hello := &Inscription{ContentType: "text/plain", Content: []byte("hello")}
+
+	t.Run("inscription", func(t *testing.T) {
+		t.Parallel()
+		raw, err := InscriptionScript(&InscriptionOutput{Address: "1FHnmcTycCypk8e3WBaqM462GHcZJdSeJD", Inscription: hello})
+		require.NoError(t, err)
+		assert.Equal(t, testInscription+testScriptPubKey, hex.EncodeToString(raw))
+
+		classified := ClassifyScriptBytes(raw, NetworkMainnet)
+		assert.Equal(t, ScriptOrdinal, classified.Type)
+		assert.Equal(t, []string{"1FHnmcTycCypk8e3WBaqM462GHcZJdSeJD"}, classified.Addresses)
+		assert.Equal(t, hello, classified.Inscription)
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		t.Parallel()
+		metadata, err := NewMAPSet("app", "myapp", "type", "ord")
+		require.NoError(t, err)
+		raw, err := InscriptionScript(&InscriptionOutput{Address: "1FHnmcTycCypk8e3WBaqM462GHcZJdSeJD", Inscription: hello, Metadata: metadata})
+		require.NoError(t, err)
+		assert.Equal(t, testInscription+testScriptPubKey+"6a", hex.EncodeToString(raw)[:len(testInscription+testScriptPubKey)+2])
+
+		// The owner is still found with the metadata after the lock
+		classified := ClassifyScriptBytes(raw, NetworkMainnet)
+		assert.Equal(t, ScriptOrdinal, classified.Type)
+		assert.Equal(t, []string{"1FHnmcTycCypk8e3WBaqM462GHcZJdSeJD"}, classified.Addresses)
+
+		protocols := ScriptBitcomProtocols(raw)
+		require.Len(t, protocols, 1)
+		m, err := ParseMAPData(protocols[0])
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"app": "myapp", "type": "ord"}, m.Data)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		_, err := InscriptionScript(&InscriptionOutput{Address: testAddress})
+		require.ErrorIs(t, err, ErrInvalidInscription)
+		_, err = InscriptionScript(&InscriptionOutput{Address: testAddress, Inscription: &Inscription{Content: []byte("hello")}})
+		require.ErrorIs(t, err, ErrInvalidInscription)
+		_, err = InscriptionScript(&InscriptionOutput{Address: testAddress, Inscription: &Inscription{ContentType: "text/plain"}})
+		require.ErrorIs(t, err, ErrInvalidInscription)
+		_, err = InscriptionScript(&InscriptionOutput{Inscription: hello})
+		require.ErrorIs(t, err, ErrMissingAddress)
+		_, err = InscriptionScript(&InscriptionOutput{Address: "invalid", Inscription: hello})
+		require.Error(t, err)
+	})
+}
+
+// TestSpendableUtxos will test the methods IsOrdinalUtxo() and SpendableUtxos()
+func TestSpendableUtxos(t *testing.T) {
+	t.Parallel()
+
+	funding := newTestUtxo(1000)
+	oneSat := newTestUtxo(1)
+	inscribed := newTestUtxo(1000)
+	inscribed.ScriptPubKey = testInscription + testScriptPubKey
+	invalid := newTestUtxo(1000)
+	invalid.ScriptPubKey = "zz"
+
+	assert.False(t, IsOrdinalUtxo(funding))
+	assert.True(t, IsOrdinalUtxo(oneSat))
+	assert.True(t, IsOrdinalUtxo(inscribed))
+	assert.False(t, IsOrdinalUtxo(invalid))
+
+	spendable, ordinals := SpendableUtxos([]*Utxo{oneSat, funding, inscribed, invalid})
+	assert.Equal(t, []*Utxo{funding, invalid}, spendable)
+	assert.Equal(t, []*Utxo{oneSat, inscribed}, ordinals)
+}
+
+// TestCreateInscriptionTx will test the method CreateInscriptionTx()
+func TestCreateInscriptionTx(t *testing.T) {
+	t.Parallel()
+
+	privateKey := mustTestPrivKey(t)
+	hello := &Inscription{ContentType: "text/plain", Content: []byte("hello")}
+	ordinal := newTestUtxo(1)
+	ordinal.Vout = 1
+
+	t.Run("mint with change", func(t *testing.T) {
+		t.Parallel()
+		metadata, err := NewMAPSet("app", "myapp", "type", "ord")
+		require.NoError(t, err)
+
+		tx, err := CreateInscriptionTx(
+			[]*Utxo{ordinal, newTestUtxo(1000)},
+			[]*InscriptionOutput{
+				{Address: testAddress, Inscription: hello, Metadata: metadata},
+				{Address: testAddress2, Inscription: &Inscription{ContentType: "application/json", Content: []byte(`{}`)}},
+			},
+			testChangeAddress, nil, nil, privateKey,
+		)
+		require.NoError(t, err)
+
+		// The 1 sat ordinal is not spent
+		require.Len(t, tx.Inputs, 1)
+		assert.Equal(t, uint32(0), tx.Inputs[0].PreviousTxOutIndex)
+		assert.NotEmpty(t, tx.Inputs[0].UnlockingScript)
+
+		require.Len(t, tx.Outputs, 3)
+		assert.Equal(t, OrdinalSatoshis, tx.Outputs[0].Satoshis)
+		assert.Equal(t, OrdinalSatoshis, tx.Outputs[1].Satoshis)
+		fee := 1000 - tx.TotalOutputSatoshis()
+		assert.GreaterOrEqual(t, fee, CalculateFeeForTx(tx, nil, nil))
+		assert.LessOrEqual(t, fee, CalculateFeeForTx(tx, nil, nil)+2)
+
+		change, err := bscript.NewP2PKHFromAddress(testChangeAddress)
+		require.NoError(t, err)
+		assert.Equal(t, change, tx.Outputs[2].LockingScript)
+
+		inscriptions := GetInscriptions(tx, NetworkMainnet)
+		require.Len(t, inscriptions, 2)
+		assert.Equal(t, &InscriptionOutput{Vout: 0, Address: testAddress, Inscription: hello, Metadata: metadata}, inscriptions[0])
+		assert.Equal(t, uint32(1), inscriptions[1].Vout)
+		assert.Equal(t, testAddress2, inscriptions[1].Address)
+		assert.Empty(t, inscriptions[1].Metadata)
+		assert.Len(t, GetMAPData(tx), 1)
+	})
+
+	t.Run("change of a satoshi goes to the fee", func(t *testing.T) {
+		t.Parallel()
+		// 127 satoshis leave 1 satoshi of change after the inscription and fee
+		inscriptions := []*InscriptionOutput{{
+			Address:     testAddress,
+			Inscription: &Inscription{ContentType: "text/plain", Content: []byte("h")},
+		}}
+		tx, err := CreateInscriptionTx([]*Utxo{newTestUtxo(127)}, inscriptions, testChangeAddress, nil, nil, privateKey)
+		require.NoError(t, err)
+		require.Len(t, tx.Outputs, 1)
+		assert.Equal(t, OrdinalSatoshis, tx.Outputs[0].Satoshis)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		inscriptions := []*InscriptionOutput{{Address: testAddress, Inscription: hello}}
+
+		_, err := CreateInscriptionTx([]*Utxo{newTestUtxo(1000)}, nil, testChangeAddress, nil, nil, privateKey)
+		require.ErrorIs(t, err, ErrInvalidInscription)
+		_, err = CreateInscriptionTx([]*Utxo{newTestUtxo(1000)}, inscriptions, "", nil, nil, privateKey)
+		require.ErrorIs(t, err, ErrChangeAddressRequired)
+		_, err = CreateInscriptionTx([]*Utxo{newTestUtxo(1000)}, inscriptions, testChangeAddress, nil, nil, nil)
+		require.ErrorIs(t, err, ErrPrivateKeyMissing)
+		_, err = CreateInscriptionTx([]*Utxo{ordinal}, inscriptions, testChangeAddress, nil, nil, privateKey)
+		require.ErrorIs(t, err, ErrNoFeeUtxos)
+		_, err = CreateInscriptionTx([]*Utxo{ordinal, newTestUtxo(50)}, inscriptions, testChangeAddress, nil, nil, privateKey)
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+		_, err = CreateInscriptionTx([]*Utxo{newTestUtxo(1000)}, []*InscriptionOutput{{Address: testAddress}}, testChangeAddress, nil, nil, privateKey)
+		require.ErrorIs(t, err, ErrInvalidInscription)
+	})
+}
+
+// TestGetInscriptions will test the method GetInscriptions()
+func TestGetInscriptions(t *testing.T) {
+	t.Parallel()
+
+	raw, err := hex.DecodeString(testScriptPubKey + testInscription)
+	require.NoError(t, err)
+	tx := bt.NewTx()
+	require.NoError(t, tx.PayToAddress(testAddress, 500))
+	tx.AddOutput(&bt.Output{Satoshis: 1, LockingScript: bscript.NewFromBytes(raw)})
+	tx.AddOutput(&bt.Output{})
+
+	owner, err := bscript.NewAddressFromPublicKeyHash(raw[3:23], false)
+	require.NoError(t, err)
+
+	inscriptions := GetInscriptions(tx, NetworkTestnet)
+	require.Len(t, inscriptions, 1)
+	assert.Equal(t, uint32(1), inscriptions[0].Vout)
+	assert.Equal(t, owner.AddressString, inscriptions[0].Address)
+	assert.Equal(t, "hello", string(inscriptions[0].Inscription.Content))
+	assert.Nil(t, inscriptions[0].Metadata)
+}
+
+// ExampleCreateInscriptionTx example using CreateInscriptionTx()
+func ExampleCreateInscriptionTx() {
+	privateKey, err := PrivateKeyFromString(testPrivateKeyHex)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	var tx *bt.Tx
+	if tx, err = CreateInscriptionTx(
+		[]*Utxo{{
+			TxID:         "b7b0650a7c3a1bd4716369783876348b59f5404784970192cec1996e86950576",
+			ScriptPubKey: "76a9149cbe9f5e72fa286ac8a38052d1d5337aa363ea7f88ac",
+			Satoshis:     1000,
+		}},
+		[]*InscriptionOutput{{
+			Address:     "1DfGxKmgL3ETwUdNnXLBueEvNpjcDGcKgK",
+			Inscription: &Inscription{ContentType: "text/plain", Content: []byte("hello")},
+		}},
+		"1DfGxKmgL3ETwUdNnXLBueEvNpjcDGcKgK", nil, nil, privateKey,
+	); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	for _, inscription := range GetInscriptions(tx, NetworkMainnet) {
+		fmt.Printf("output %d: %s %s", inscription.Vout, inscription.Inscription.ContentType, inscription.Inscription.Content)
+	}
+	// Output:output 0: text/plain hello
+}
+
+// BenchmarkInscriptionScript benchmarks the method InscriptionScript()
+func BenchmarkInscriptionScript(b *testing.B) {
+	output := &InscriptionOutput{
+		Address:     "1DfGxKmgL3ETwUdNnXLBueEvNpjcDGcKgK",
+		Inscription: &Inscription{ContentType: "text/plain", Content: []byte("hello")},
+	}
+	for b.Loop() {
+		_, _ = InscriptionScript(output)
+	}
+}
@@ -1,9 +1,11 @@
 package bitcoin
 
 import (
+	"bytes"
 	"encoding/hex"
 
 	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	hash "github.com/bsv-blockchain/go-sdk/primitives/hash"
 	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
 )
 
@@ -100,6 +102,23 @@ func PrivateAndPublicKeys(privateKey string) (*ec.PrivateKey, *ec.PublicKey, err
 	return privateKeyFromHex(privateKey)
 }
 
+// PrivateKeyFromHexBytes turns a private key (hex encoded bytes) into an ec.PrivateKey
+//
+// Unlike PrivateKeyFromString no string copy of the key is made; the caller
+// should zero hexKey, and wipe the result with ZeroPrivateKey after use.
+func PrivateKeyFromHexBytes(hexKey []byte) (*ec.PrivateKey, error) {
+	if len(hexKey) == 0 {
+		return nil, ErrPrivateKeyMissing
+	}
+	rawKey := make([]byte, hex.DecodedLen(len(hexKey)))
+	defer clear(rawKey)
+	if _, err := hex.Decode(rawKey, hexKey); err != nil {
+		return nil, err
+	}
+	privateKey, _ := ec.PrivateKeyFromBytes(rawKey)
+	return privateKey, nil
+}
+
 // PrivateKeyToWif will convert a private key to an uncompressed mainnet WIF (*WIF).
 //
 // Use PrivateKeyToWifWithCompression to choose compression.
@@ -138,6 +157,19 @@ func PrivateKeyToWifStringWithCompression(privateKey string, compress bool) (str
 	return privateWif.String(), nil
 }
 
+// PrivateKeyToWifBytes will convert a raw 32 byte private key to a mainnet WIF (bytes)
+//
+// Unlike PrivateKeyToWifStringWithCompression no string copy of the key is
+// made; the caller should zero both slices after use.
+func PrivateKeyToWifBytes(privateKey []byte, compress bool) ([]byte, error) {
+	if len(privateKey) == 0 {
+		return nil, ErrPrivateKeyMissing
+	} else if len(privateKey) != privKeyBytesLen {
+		return nil, ErrMalformedPrivateKey
+	}
+	return wifBytes(privateKey, chaincfg.MainNet.PrivateKeyID, compress), nil
+}
+
 // WifToPrivateKey will convert a WIF to a private key (*ec.PrivateKey)
 func WifToPrivateKey(wifKey string) (*ec.PrivateKey, error) {
 	// Missing wif?
@@ -167,6 +199,37 @@ func WifToPrivateKeyString(wifKey string) (string, error) {
 	return hex.EncodeToString(privateKey.Serialize()), nil
 }
 
+// WifToPrivateKeyBytes will convert a WIF (bytes) to a raw 32 byte private key
+//
+// Unlike WifToPrivateKeyString no string copy of the key is made; the caller
+// should zero both slices after use. See DecodeWIF for the errors.
+func WifToPrivateKeyBytes(wif []byte) ([]byte, error) {
+	if len(wif) == 0 {
+		return nil, ErrWifMissing
+	}
+	decoded, err := base58DecodeBytes(wif)
+	if err != nil {
+		return nil, ErrMalformedPrivateKey
+	}
+	defer clear(decoded)
+
+	// netID (1) || private key (32) || optional compress magic (1) || checksum (4)
+	switch len(decoded) {
+	case 1 + privKeyBytesLen + 4:
+	case 1 + privKeyBytesLen + 1 + 4:
+		if decoded[1+privKeyBytesLen] != compressMagic {
+			return nil, ErrMalformedPrivateKey
+		}
+	default:
+		return nil, ErrMalformedPrivateKey
+	}
+	checksum := len(decoded) - 4
+	if !bytes.Equal(hash.Sha256d(decoded[:checksum])[:4], decoded[checksum:]) {
+		return nil, ErrChecksumMismatch
+	}
+	return bytes.Clone(decoded[1 : 1+privKeyBytesLen]), nil
+}
+
 // WifFromString will convert a WIF (string) to a WIF (*WIF)
 func WifFromString(wifKey string) (*WIF, error) {
 	// Missing wif?
@@ -603,3 +603,73 @@ func BenchmarkWifFromString(b *testing.B) {
 		_, _ = WifFromString(wifString)
 	}
 }
+
+// TestPrivateKeyFromHexBytes will test the method PrivateKeyFromHexBytes()
+func TestPrivateKeyFromHexBytes(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := PrivateKeyFromHexBytes([]byte(testPrivateKeyHex))
+	require.NoError(t, err)
+	assert.Equal(t, testPrivateKeyHex, hex.EncodeToString(privateKey.Serialize()))
+
+	_, err = PrivateKeyFromHexBytes(nil)
+	require.ErrorIs(t, err, ErrPrivateKeyMissing)
+	_, err = PrivateKeyFromHexBytes([]byte("invalid"))
+	require.Error(t, err)
+}
+
+// TestPrivateKeyToWifBytes will test the methods PrivateKeyToWifBytes() and WifToPrivateKeyBytes()
+func TestPrivateKeyToWifBytes(t *testing.T) {
+	t.Parallel()
+
+	rawKey, err := hex.DecodeString(testPrivateKeyHex)
+	require.NoError(t, err)
+
+	for _, compress := range []bool{false, true} {
+		wif, wifErr := PrivateKeyToWifBytes(rawKey, compress)
+		require.NoError(t, wifErr)
+		expected, wifErr := PrivateKeyToWifStringWithCompression(testPrivateKeyHex, compress)
+		require.NoError(t, wifErr)
+		assert.Equal(t, expected, string(wif))
+
+		decoded, wifErr := WifToPrivateKeyBytes(wif)
+		require.NoError(t, wifErr)
+		assert.Equal(t, rawKey, decoded)
+	}
+
+	_, err = PrivateKeyToWifBytes(nil, true)
+	require.ErrorIs(t, err, ErrPrivateKeyMissing)
+	_, err = PrivateKeyToWifBytes(rawKey[:31], true)
+	require.ErrorIs(t, err, ErrMalformedPrivateKey)
+}
+
+// TestWifToPrivateKeyBytes will test the error cases of WifToPrivateKeyBytes()
+func TestWifToPrivateKeyBytes(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := WifToPrivateKeyBytes([]byte(testWIF))
+	require.NoError(t, err)
+	expected, err := WifToPrivateKeyString(testWIF)
+	require.NoError(t, err)
+	assert.Equal(t, expected, hex.EncodeToString(privateKey))
+
+	tests := []struct {
+		name     string
+		input    string
+		expected error
+	}{
+		{"empty", "", ErrWifMissing},
+		{"invalid base58", "0OIl", ErrMalformedPrivateKey},
+		{"wrong length", "1111", ErrMalformedPrivateKey},
+		{"bad checksum", testWIF[:len(testWIF)-1] + "1", ErrChecksumMismatch},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, wifErr := WifToPrivateKeyBytes([]byte(test.input))
+			require.ErrorIs(t, wifErr, test.expected)
+			_, wifErr = WifToPrivateKeyString(test.input)
+			require.Error(t, wifErr)
+		})
+	}
+}
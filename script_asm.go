@@ -0,0 +1,159 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	script "github.com/bsv-blockchain/go-sdk/script"
+)
+
+// ErrInvalidASM is returned when an ASM token is not an opcode, number or hex data
+var ErrInvalidASM = errors.New("invalid script asm")
+
+// asmError is appended to the ASM of a script that ends in a truncated push, as the node does
+const asmError = "[error]"
+
+// FormatASM will format a script as ASM the way the node does: opcodes by
+// name (OP_0 to OP_16 and OP_1NEGATE as 0 to 16 and -1), pushes of up to 4
+// bytes as script numbers and longer pushes as hex
+//
+// A script that ends in a truncated push ends with "[error]".
+func FormatASM(raw []byte) string {
+	return formatChunksASM(scriptChunks(raw))
+}
+
+// formatChunksASM formats parsed chunks as ASM (see FormatASM).
+func formatChunksASM(chunks []*script.ScriptChunk, parsed bool) string {
+	asm := make([]string, 0, len(chunks)+1)
+	for _, chunk := range chunks {
+		asm = append(asm, formatASMChunk(chunk))
+	}
+	if !parsed {
+		asm = append(asm, asmError)
+	}
+	return strings.Join(asm, " ")
+}
+
+// ScriptToASM will format a hex encoded script as ASM (see FormatASM)
+func ScriptToASM(scriptHex string) (string, error) {
+	raw, err := hex.DecodeString(scriptHex)
+	if err != nil {
+		return "", err
+	}
+	return FormatASM(raw), nil
+}
+
+// ParseASM will parse ASM into a script
+//
+// Tokens are separated by whitespace and may be:
+//   - opcode names (OP_DUP, OP_0, OP_FALSE, OP_TRUE, ...)
+//   - decimal numbers up to 10 digits in the 32-bit range, pushed with AddInt64
+//     (so FormatASM output parses back; 1 is OP_1)
+//   - hex data, pushed as-is with the smallest push opcode
+//   - 0x prefixed hex, inserted as raw script bytes
+//   - 'strings' without spaces, pushed as-is
+//
+// FormatASM output parses back to the same script unless it has pushes of up
+// to 4 bytes that are not minimal numbers, or hex pushes that look like numbers.
+func ParseASM(asm string) ([]byte, error) {
+	b := NewScriptBuilder()
+	for _, token := range strings.Fields(asm) {
+		if err := parseASMToken(b, token); err != nil {
+			return nil, err
+		}
+	}
+	return b.Script()
+}
+
+// ScriptFromASM will parse ASM into a hex encoded script (see ParseASM)
+func ScriptFromASM(asm string) (string, error) {
+	raw, err := ParseASM(asm)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// formatASMChunk formats an opcode or push the way the node does.
+func formatASMChunk(chunk *script.ScriptChunk) string {
+	switch {
+	case chunk.Op <= script.OpPUSHDATA4 && len(chunk.Data) <= 4:
+		return strconv.FormatInt(parseScriptNum(chunk.Data), 10)
+	case chunk.Op <= script.OpPUSHDATA4:
+		return hex.EncodeToString(chunk.Data)
+	case chunk.Op == script.Op1NEGATE:
+		return "-1"
+	case chunk.Op >= script.Op1 && chunk.Op <= script.Op16:
+		return strconv.Itoa(int(chunk.Op-script.Op1) + 1)
+	}
+	return script.OpCodeValues[chunk.Op]
+}
+
+// parseASMToken adds one ASM token to the builder.
+func parseASMToken(b *ScriptBuilder, token string) error {
+	if op, ok := asmOpcode(token); ok {
+		if op > script.OpFALSE && op <= script.OpPUSHDATA4 {
+			return fmt.Errorf("%w: %s must be followed by data, use hex data instead", ErrInvalidASM, token)
+		}
+		b.AddOp(op)
+		return nil
+	}
+
+	if n, ok := asmNumber(token); ok {
+		b.AddInt64(n)
+		return nil
+	}
+
+	switch {
+	case strings.HasPrefix(token, "0x"):
+		raw, err := hex.DecodeString(token[2:])
+		if err != nil || len(raw) == 0 {
+			return fmt.Errorf("%w: invalid raw hex %q", ErrInvalidASM, token)
+		}
+		b.AddScript(raw)
+	case len(token) >= 2 && strings.HasPrefix(token, "'") && strings.HasSuffix(token, "'"):
+		b.AddString(token[1 : len(token)-1])
+	default:
+		data, err := hex.DecodeString(token)
+		if err != nil {
+			return fmt.Errorf("%w: %q is not an opcode, number or hex data", ErrInvalidASM, token)
+		}
+		b.AddPushData(data)
+	}
+	return nil
+}
+
+// asmOpcode returns the opcode of an opcode name.
+func asmOpcode(token string) (byte, bool) {
+	if !strings.HasPrefix(token, "OP_") {
+		return 0, false
+	}
+	if op, ok := script.OpCodeStrings[token]; ok {
+		return op, true
+	}
+	// Names that only appear in FormatASM output (like OP_SUBSTR)
+	for op, name := range script.OpCodeValues {
+		if name == token {
+			return op, true
+		}
+	}
+	return 0, false
+}
+
+// asmNumber parses a decimal number in the range the node formats (pushes of
+// up to 4 bytes).
+func asmNumber(token string) (int64, bool) {
+	digits := strings.TrimPrefix(token, "-")
+	if len(digits) == 0 || len(digits) > 10 || strings.Trim(digits, "0123456789") != "" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(token, 10, 64)
+	if err != nil || n > math.MaxInt32 || n < -math.MaxInt32 {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,147 @@
+package bitcoin
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestScriptToASM will test the method ScriptToASM()
+func TestScriptToASM(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		script      string
+		expectedASM string
+	}{
+		{caseEmpty, "", ""},
+		{"p2pkh", testScriptPubKey, "OP_DUP OP_HASH160 9cbe9f5e72fa286ac8a38052d1d5337aa363ea7f OP_EQUALVERIFY OP_CHECKSIG"},
+		{"multisig", "5121" + testPubKeyG + "21" + testPubKey2G + "52ae", "1 " + testPubKeyG + " " + testPubKey2G + " 2 OP_CHECKMULTISIG"},
+		{"small pushes are numbers", "006a0568656c6c6f0103020001", "0 OP_RETURN 68656c6c6f 3 256"},
+		{"negative numbers", "4f018101820400000080", "-1 -1 -2 0"},
+		{"non-minimal zero", "0100", "0"},
+		{"pushdata1", "4c0568656c6c6f", "68656c6c6f"},
+		{"truncated push", "76a9140102", "OP_DUP OP_HASH160 [error]"},
+		{"unassigned opcode", "ba", "OP_UNKNOWN186"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			asm, err := ScriptToASM(test.script)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedASM, asm)
+		})
+	}
+
+	t.Run("invalid hex", func(t *testing.T) {
+		t.Parallel()
+		_, err := ScriptToASM("zz")
+		require.Error(t, err)
+	})
+}
+
+// TestScriptFromASM will test the method ScriptFromASM()
+func TestScriptFromASM(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		asm            string
+		expectedScript string
+		expectedError  error
+	}{
+		{caseEmpty, "", "", nil},
+		{"p2pkh", "OP_DUP OP_HASH160 9cbe9f5e72fa286ac8a38052d1d5337aa363ea7f OP_EQUALVERIFY OP_CHECKSIG", testScriptPubKey, nil},
+		{"opcode aliases", "OP_0 OP_FALSE OP_1 OP_TRUE OP_16", "0000515160", nil},
+		{"numbers", "0 -1 1 16 17 -2 1000000000 -2147483647", "004f5160011101820400ca9a3b04ffffffff", nil},
+		{"short hex is data", "ab 0a0b", "01ab020a0b", nil},
+		{"short digits are numbers", "0102", "0166", nil},
+		{"long digits are hex", "12345678901234", "0712345678901234", nil},
+		{"raw bytes", "0x0101 0x4c00", "01014c00", nil},
+		{"strings", "0 OP_RETURN 'hello'", "006a0568656c6c6f", nil},
+		{"extra whitespace", "  OP_DUP\n\tOP_DROP ", "7675", nil},
+		{"push opcode", "OP_PUSHDATA1", "", ErrInvalidASM},
+		{"unknown opcode", "OP_NOTANOPCODE", "", ErrInvalidASM},
+		{"not hex", "xyz", "", ErrInvalidASM},
+		{"odd hex", "abc", "", ErrInvalidASM},
+		{"empty raw bytes", "0x", "", ErrInvalidASM},
+		{"error marker", "OP_DUP [error]", "", ErrInvalidASM},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			scriptHex, err := ScriptFromASM(test.asm)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedScript, scriptHex)
+		})
+	}
+}
+
+// TestASMRoundTrip will test that FormatASM output parses back to the same script
+func TestASMRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	scripts := []string{
+		testScriptPubKey,
+		"21" + testPubKeyCompressed + "ac",
+		"5121" + testPubKeyG + "21" + testPubKey2G + "52ae",
+		"006a0568656c6c6f02e8034c4c" + strings.Repeat("ab", 76),
+		testRPuzzlePrefix + "a914" + testHash20 + "88ac",
+		testScriptPubKey + testInscription,
+		"ba4f60",
+	}
+
+	for _, scriptHex := range scripts {
+		asm, err := ScriptToASM(scriptHex)
+		require.NoError(t, err)
+		parsed, err := ScriptFromASM(asm)
+		require.NoError(t, err, asm)
+		assert.Equal(t, scriptHex, parsed, asm)
+	}
+}
+
+// ExampleScriptFromASM example using ScriptFromASM()
+func ExampleScriptFromASM() {
+	scriptHex, err := ScriptFromASM("OP_DUP OP_HASH160 9cbe9f5e72fa286ac8a38052d1d5337aa363ea7f OP_EQUALVERIFY OP_CHECKSIG")
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("script: %s", scriptHex)
+	// Output:script: 76a9149cbe9f5e72fa286ac8a38052d1d5337aa363ea7f88ac
+}
+
+// BenchmarkScriptFromASM benchmarks the method ScriptFromASM()
+func BenchmarkScriptFromASM(b *testing.B) {
+	for b.Loop() {
+		_, _ = ScriptFromASM("OP_DUP OP_HASH160 9cbe9f5e72fa286ac8a38052d1d5337aa363ea7f OP_EQUALVERIFY OP_CHECKSIG")
+	}
+}
+
+// ExampleScriptToASM example using ScriptToASM()
+func ExampleScriptToASM() {
+	asm, err := ScriptToASM("006a0568656c6c6f02e803")
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("asm: %s", asm)
+	// Output:asm: 0 OP_RETURN 68656c6c6f 1000
+}
+
+// BenchmarkScriptToASM benchmarks the method ScriptToASM()
+func BenchmarkScriptToASM(b *testing.B) {
+	for b.Loop() {
+		_, _ = ScriptToASM(testScriptPubKey)
+	}
+}
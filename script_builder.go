@@ -0,0 +1,196 @@
+package bitcoin
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+
+	script "github.com/bsv-blockchain/go-sdk/script"
+)
+
+var (
+	// ErrInvalidOpcode is returned when a push opcode is added without its data
+	ErrInvalidOpcode = errors.New("invalid opcode")
+
+	// ErrDataTooLarge is returned when data is too large for a single push
+	ErrDataTooLarge = errors.New("data too large to push")
+)
+
+// ScriptBuilder will build a script from opcodes, data pushes and numbers
+//
+// The first error is kept and returned by Script (or Hex), so calls can be
+// chained: NewScriptBuilder().AddOp(script.OpDUP).AddData(hash)....Script()
+type ScriptBuilder struct {
+	script []byte
+	err    error
+}
+
+// NewScriptBuilder will create an empty ScriptBuilder
+func NewScriptBuilder() *ScriptBuilder {
+	return &ScriptBuilder{}
+}
+
+// AddOp will add an opcode (data pushes must use AddData or AddPushData)
+func (b *ScriptBuilder) AddOp(op byte) *ScriptBuilder {
+	if b.err != nil {
+		return b
+	}
+	if op > script.OpFALSE && op <= script.OpPUSHDATA4 {
+		b.err = fmt.Errorf("%w: %s pushes data, use AddData", ErrInvalidOpcode, script.OpCodeValues[op])
+		return b
+	}
+	b.script = append(b.script, op)
+	return b
+}
+
+// AddOps will add opcodes (see AddOp)
+func (b *ScriptBuilder) AddOps(ops ...byte) *ScriptBuilder {
+	for _, op := range ops {
+		b.AddOp(op)
+	}
+	return b
+}
+
+// AddData will push data with the minimal encoding required by the node's
+// MINIMALDATA rule: empty data is OP_0, a single byte 1-16 is OP_1 to OP_16,
+// 0x81 is OP_1NEGATE, and anything else uses the smallest push opcode
+func (b *ScriptBuilder) AddData(data []byte) *ScriptBuilder {
+	switch {
+	case len(data) == 0:
+		return b.AddOp(script.OpFALSE)
+	case len(data) == 1 && data[0] >= 1 && data[0] <= 16:
+		return b.AddOp(script.Op1 + data[0] - 1)
+	case len(data) == 1 && data[0] == 0x81:
+		return b.AddOp(script.Op1NEGATE)
+	}
+	return b.AddPushData(data)
+}
+
+// AddPushData will push data as-is with the smallest push opcode (a single
+// byte 1-16 stays a push, unlike AddData), as expected by data protocols
+// after OP_RETURN
+func (b *ScriptBuilder) AddPushData(data []byte) *ScriptBuilder {
+	if b.err != nil {
+		return b
+	}
+	switch length := len(data); {
+	case length == 0:
+		b.script = append(b.script, script.OpFALSE)
+		return b
+	case length < int(script.OpPUSHDATA1):
+		b.script = append(b.script, byte(length)) // #nosec G115 -- length < 76
+	case length <= math.MaxUint8:
+		b.script = append(b.script, script.OpPUSHDATA1, byte(length)) // #nosec G115 -- length <= 255
+	case length <= math.MaxUint16:
+		b.script = append(b.script, script.OpPUSHDATA2)
+		b.script = binary.LittleEndian.AppendUint16(b.script, uint16(length)) // #nosec G115 -- length <= 65535
+	case uint64(length) <= math.MaxUint32:
+		b.script = append(b.script, script.OpPUSHDATA4)
+		b.script = binary.LittleEndian.AppendUint32(b.script, uint32(length)) // #nosec G115 -- length < 2^32
+	default:
+		b.err = fmt.Errorf("%w: %d bytes", ErrDataTooLarge, length)
+		return b
+	}
+	b.script = append(b.script, data...)
+	return b
+}
+
+// AddString will push a string as-is (see AddPushData)
+func (b *ScriptBuilder) AddString(s string) *ScriptBuilder {
+	return b.AddPushData([]byte(s))
+}
+
+// AddInt64 will push a number: -1 to 16 as OP_1NEGATE, OP_0 to OP_16, and
+// anything else as a minimally encoded script number
+func (b *ScriptBuilder) AddInt64(n int64) *ScriptBuilder {
+	switch {
+	case n == 0:
+		return b.AddOp(script.OpFALSE)
+	case n == -1:
+		return b.AddOp(script.Op1NEGATE)
+	case n >= 1 && n <= 16:
+		return b.AddOp(script.Op1 + byte(n) - 1) // #nosec G115 -- n is 1 to 16
+	}
+	return b.AddPushData(scriptNum(n))
+}
+
+// AddScript will append raw script bytes
+func (b *ScriptBuilder) AddScript(raw []byte) *ScriptBuilder {
+	if b.err == nil {
+		b.script = append(b.script, raw...)
+	}
+	return b
+}
+
+// Reset will empty the builder and clear its error
+func (b *ScriptBuilder) Reset() *ScriptBuilder {
+	b.script, b.err = b.script[:0], nil
+	return b
+}
+
+// Script will return a copy of the script, or the first error
+func (b *ScriptBuilder) Script() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return append([]byte{}, b.script...), nil
+}
+
+// Hex will return the hex encoded script, or the first error
+func (b *ScriptBuilder) Hex() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	return hex.EncodeToString(b.script), nil
+}
+
+// scriptNum encodes a number as a minimal little-endian sign-magnitude script number.
+func scriptNum(n int64) []byte {
+	if n == 0 {
+		return nil
+	}
+	negative := n < 0
+	magnitude := uint64(n) // #nosec G115 -- two's complement, negated below
+	if negative {
+		magnitude = -magnitude
+	}
+
+	var num []byte
+	for magnitude > 0 {
+		num = append(num, byte(magnitude)) // #nosec G115 -- low byte
+		magnitude >>= 8
+	}
+
+	// The top bit is the sign, so add a byte if the magnitude uses it
+	switch {
+	case num[len(num)-1]&0x80 != 0 && negative:
+		num = append(num, 0x80)
+	case num[len(num)-1]&0x80 != 0:
+		num = append(num, 0x00)
+	case negative:
+		num[len(num)-1] |= 0x80
+	}
+	return num
+}
+
+// parseScriptNum decodes a little-endian sign-magnitude script number of up
+// to 4 bytes (non-minimal encodings are accepted).
+func parseScriptNum(num []byte) int64 {
+	if len(num) == 0 {
+		return 0
+	}
+	var magnitude uint64
+	for i, v := range num {
+		if i == len(num)-1 {
+			v &= 0x7f
+		}
+		magnitude |= uint64(v) << (8 * i)
+	}
+	n := int64(magnitude) // #nosec G115 -- at most 4 bytes
+	if num[len(num)-1]&0x80 != 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,154 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	script "github.com/bsv-blockchain/go-sdk/script"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestScriptBuilder will test building scripts with ScriptBuilder
+func TestScriptBuilder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("p2pkh", func(t *testing.T) {
+		t.Parallel()
+		hash160, err := hex.DecodeString("9cbe9f5e72fa286ac8a38052d1d5337aa363ea7f")
+		require.NoError(t, err)
+		scriptHex, err := NewScriptBuilder().
+			AddOps(script.OpDUP, script.OpHASH160).
+			AddData(hash160).
+			AddOps(script.OpEQUALVERIFY, script.OpCHECKSIG).
+			Hex()
+		require.NoError(t, err)
+		assert.Equal(t, testScriptPubKey, scriptHex)
+	})
+
+	t.Run("push opcode without data", func(t *testing.T) {
+		t.Parallel()
+		b := NewScriptBuilder().AddOp(script.OpDUP).AddOp(script.OpPUSHDATA1).AddOp(script.OpDUP)
+		_, err := b.Script()
+		require.ErrorIs(t, err, ErrInvalidOpcode)
+		_, err = b.Hex()
+		require.ErrorIs(t, err, ErrInvalidOpcode)
+
+		raw, err := b.Reset().AddOp(script.OpDUP).Script()
+		require.NoError(t, err)
+		assert.Equal(t, []byte{script.OpDUP}, raw)
+	})
+
+	t.Run("script is a copy", func(t *testing.T) {
+		t.Parallel()
+		b := NewScriptBuilder().AddOp(script.OpDUP)
+		raw, err := b.Script()
+		require.NoError(t, err)
+		raw[0] = script.OpDROP
+		scriptHex, err := b.AddScript([]byte{script.OpEQUAL}).Hex()
+		require.NoError(t, err)
+		assert.Equal(t, "7687", scriptHex)
+	})
+}
+
+// TestScriptBuilder_AddData will test the push encodings of AddData and AddPushData
+func TestScriptBuilder_AddData(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		data             []byte
+		expectedData     string
+		expectedPushData string
+	}{
+		{caseEmpty, nil, "00", "00"},
+		{"zero byte", []byte{0x00}, "0100", "0100"},
+		{"small int", []byte{0x05}, "55", "0105"},
+		{"sixteen", []byte{0x10}, "60", "0110"},
+		{"seventeen", []byte{0x11}, "0111", "0111"},
+		{"negative one", []byte{0x81}, "4f", "0181"},
+		{"75 bytes", bytes.Repeat([]byte{0xab}, 75), "4b" + strings.Repeat("ab", 75), "4b" + strings.Repeat("ab", 75)},
+		{"76 bytes", bytes.Repeat([]byte{0xab}, 76), "4c4c" + strings.Repeat("ab", 76), "4c4c" + strings.Repeat("ab", 76)},
+		{"256 bytes", bytes.Repeat([]byte{0xab}, 256), "4d0001" + strings.Repeat("ab", 256), "4d0001" + strings.Repeat("ab", 256)},
+		{"65536 bytes", bytes.Repeat([]byte{0xab}, 65536), "4e00000100" + strings.Repeat("ab", 65536), "4e00000100" + strings.Repeat("ab", 65536)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			scriptHex, err := NewScriptBuilder().AddData(test.data).Hex()
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedData, scriptHex)
+
+			scriptHex, err = NewScriptBuilder().AddPushData(test.data).Hex()
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedPushData, scriptHex)
+		})
+	}
+}
+
+// TestScriptBuilder_AddInt64 will test pushing numbers
+func TestScriptBuilder_AddInt64(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{0, "00"},
+		{-1, "4f"},
+		{1, "51"},
+		{16, "60"},
+		{17, "0111"},
+		{-2, "0182"},
+		{127, "017f"},
+		{128, "028000"},
+		{-128, "028080"},
+		{255, "02ff00"},
+		{256, "020001"},
+		{-255, "02ff80"},
+		{1000000000, "0400ca9a3b"},
+		{2147483647, "04ffffff7f"},
+		{2147483648, "050000008000"},
+		{-2147483648, "050000008080"},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprint(test.input), func(t *testing.T) {
+			t.Parallel()
+			scriptHex, err := NewScriptBuilder().AddInt64(test.input).Hex()
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, scriptHex)
+		})
+	}
+}
+
+// ExampleScriptBuilder example using ScriptBuilder
+func ExampleScriptBuilder() {
+	scriptHex, err := NewScriptBuilder().
+		AddOps(script.OpFALSE, script.OpRETURN).
+		AddString("hello").
+		AddInt64(1000).
+		Hex()
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("script: %s", scriptHex)
+	// Output:script: 006a0568656c6c6f02e803
+}
+
+// BenchmarkScriptBuilder benchmarks building a P2PKH script with ScriptBuilder
+func BenchmarkScriptBuilder(b *testing.B) {
+	hash160 := bytes.Repeat([]byte{0xab}, 20)
+	for b.Loop() {
+		_, _ = NewScriptBuilder().
+			AddOps(script.OpDUP, script.OpHASH160).
+			AddData(hash160).
+			AddOps(script.OpEQUALVERIFY, script.OpCHECKSIG).
+			Script()
+	}
+}
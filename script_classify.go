@@ -0,0 +1,403 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"slices"
+
+	"github.com/bsv-blockchain/go-bt/v2/bscript"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	script "github.com/bsv-blockchain/go-sdk/script"
+)
+
+// ScriptType is the type of a locking script
+type ScriptType uint8
+
+const (
+	// ScriptUnknown is a script that matches no known template
+	ScriptUnknown ScriptType = iota
+
+	// ScriptP2PKH is OP_DUP OP_HASH160 <hash160> OP_EQUALVERIFY OP_CHECKSIG
+	ScriptP2PKH
+
+	// ScriptP2PK is <pubkey> OP_CHECKSIG
+	ScriptP2PK
+
+	// ScriptMultisig is bare multisig: OP_m <pubkey>... OP_n OP_CHECKMULTISIG
+	ScriptMultisig
+
+	// ScriptOpReturn is OP_RETURN <data>... (unspendable data)
+	ScriptOpReturn
+
+	// ScriptOpFalseOpReturn is OP_FALSE OP_RETURN <data>... (provably unspendable data)
+	ScriptOpFalseOpReturn
+
+	// ScriptOrdinal is a 1Sat ordinal inscription envelope
+	// (OP_FALSE OP_IF "ord" ... OP_ENDIF), usually with a P2PKH lock
+	ScriptOrdinal
+
+	// ScriptRPuzzle is an R-puzzle: the signature must use a known R value
+	// (OP_OVER OP_3 OP_SPLIT ... [<hash op>] <R or hash> OP_EQUALVERIFY OP_CHECKSIG)
+	ScriptRPuzzle
+
+	// ScriptHashPuzzle is <hash op> <hash> OP_EQUAL; since Genesis this
+	// includes the legacy P2SH pattern (OP_HASH160 <hash> OP_EQUAL)
+	ScriptHashPuzzle
+)
+
+// String returns the name of the script type
+func (t ScriptType) String() string {
+	switch t {
+	case ScriptUnknown:
+		return "unknown"
+	case ScriptP2PKH:
+		return "p2pkh"
+	case ScriptP2PK:
+		return "p2pk"
+	case ScriptMultisig:
+		return "multisig"
+	case ScriptOpReturn:
+		return "op_return"
+	case ScriptOpFalseOpReturn:
+		return "op_false_op_return"
+	case ScriptOrdinal:
+		return "ordinal"
+	case ScriptRPuzzle:
+		return "r_puzzle"
+	case ScriptHashPuzzle:
+		return "hash_puzzle"
+	}
+	return "unknown"
+}
+
+// Inscription is the content of a 1Sat ordinal inscription
+type Inscription struct {
+	ContentType string
+	Content     []byte
+}
+
+// ClassifiedScript is a decoded locking script
+//
+// Addresses and PubKeys are set for the keys that can spend the script,
+// Data for OP_RETURN data pushes, Hash and HashOp for puzzles (an R-puzzle
+// without a hash op holds the R value itself), and Inscription for ordinals.
+type ClassifiedScript struct {
+	Type         ScriptType
+	Addresses    []string
+	PubKeys      []string
+	Data         [][]byte
+	RequiredSigs int
+	Hash         []byte
+	HashOp       string
+	Inscription  *Inscription
+	ASM          string
+}
+
+// ClassifyScript will classify a hex encoded locking script (see ClassifyScriptBytes)
+func ClassifyScript(lockingScript string, network Network) (*ClassifiedScript, error) {
+	if lockingScript == "" {
+		return nil, ErrMissingScript
+	}
+	raw, err := hex.DecodeString(lockingScript)
+	if err != nil {
+		return nil, err
+	}
+	return ClassifyScriptBytes(raw, network), nil
+}
+
+// ClassifyScriptBytes will classify a locking script as P2PKH, P2PK, bare
+// multisig, OP_RETURN data, 1Sat ordinal, R-puzzle, hash puzzle or unknown,
+// with its addresses (for the network), public keys, data pushes, required
+// signatures and ASM
+//
+// The ASM is formatted the way the node does (see FormatASM). A script that
+// cannot be parsed is unknown, with "[error]" at the end of its ASM. Data, Hash and the Inscription content share memory with
+// lockingScript.
+func ClassifyScriptBytes(lockingScript []byte, network Network) *ClassifiedScript {
+	chunks, parsed := scriptChunks(lockingScript)
+	classified := &ClassifiedScript{Type: ScriptUnknown, ASM: formatChunksASM(chunks, parsed)}
+	mainnet := network.IsMainnet()
+
+	switch {
+	case len(chunks) > 0 && chunks[0].Op == script.OpRETURN:
+		classified.Type = ScriptOpReturn
+		classified.Data = chunksData(chunks[1:])
+	case len(chunks) > 1 && chunks[0].Op == script.OpFALSE && chunks[1].Op == script.OpRETURN:
+		classified.Type = ScriptOpFalseOpReturn
+		classified.Data = chunksData(chunks[2:])
+	case !parsed:
+		// Only data scripts may be truncated
+	case // The first template that matches sets the type
+		classifyOrdinal(classified, chunks, mainnet),
+		classifyP2PKH(classified, chunks, mainnet),
+		classifyP2PK(classified, chunks, mainnet),
+		classifyMultisig(classified, chunks, mainnet),
+		classifyRPuzzle(classified, chunks),
+		classifyHashPuzzle(classified, chunks):
+	}
+	return classified
+}
+
+// classifyP2PKH matches OP_DUP OP_HASH160 <hash160> OP_EQUALVERIFY OP_CHECKSIG.
+func classifyP2PKH(classified *ClassifiedScript, chunks []*script.ScriptChunk, mainnet bool) bool {
+	if !isP2PKHChunks(chunks) {
+		return false
+	}
+	address, err := addressFromHash160(chunks[2].Data, mainnet)
+	if err != nil {
+		return false
+	}
+	classified.Type = ScriptP2PKH
+	classified.Addresses = []string{address}
+	classified.RequiredSigs = 1
+	return true
+}
+
+// classifyP2PK matches <pubkey> OP_CHECKSIG.
+func classifyP2PK(classified *ClassifiedScript, chunks []*script.ScriptChunk, mainnet bool) bool {
+	if len(chunks) != 2 || chunks[1].Op != script.OpCHECKSIG {
+		return false
+	}
+	address, ok := pubKeyAddress(chunks[0], mainnet)
+	if !ok {
+		return false
+	}
+	classified.Type = ScriptP2PK
+	classified.Addresses = []string{address}
+	classified.PubKeys = []string{hex.EncodeToString(chunks[0].Data)}
+	classified.RequiredSigs = 1
+	return true
+}
+
+// classifyMultisig matches OP_m <pubkey>... OP_n OP_CHECKMULTISIG.
+func classifyMultisig(classified *ClassifiedScript, chunks []*script.ScriptChunk, mainnet bool) bool {
+	if len(chunks) < 4 || chunks[len(chunks)-1].Op != script.OpCHECKMULTISIG {
+		return false
+	}
+	required, ok := smallInt(chunks[0])
+	total, totalOK := smallInt(chunks[len(chunks)-2])
+	keys := chunks[1 : len(chunks)-2]
+	if !ok || !totalOK || required < 1 || required > total || total != len(keys) {
+		return false
+	}
+
+	addresses := make([]string, 0, len(keys))
+	pubKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		address, valid := pubKeyAddress(key, mainnet)
+		if !valid {
+			return false
+		}
+		addresses = append(addresses, address)
+		pubKeys = append(pubKeys, hex.EncodeToString(key.Data))
+	}
+	classified.Type = ScriptMultisig
+	classified.Addresses = addresses
+	classified.PubKeys = pubKeys
+	classified.RequiredSigs = required
+	return true
+}
+
+// rPuzzlePrefix extracts R from the DER signature on the stack.
+//
+//nolint:gochecknoglobals // script template
+var rPuzzlePrefix = []byte{
+	script.OpOVER, script.Op3, script.OpSPLIT, script.OpNIP, script.Op1,
+	script.OpSPLIT, script.OpSWAP, script.OpSPLIT, script.OpDROP,
+}
+
+// classifyRPuzzle matches <rPuzzlePrefix> [<hash op>] <R or hash> OP_EQUALVERIFY OP_CHECKSIG.
+func classifyRPuzzle(classified *ClassifiedScript, chunks []*script.ScriptChunk) bool {
+	n := len(rPuzzlePrefix)
+	if len(chunks) < n+3 || len(chunks) > n+4 {
+		return false
+	}
+	for i, op := range rPuzzlePrefix {
+		if chunks[i].Op != op {
+			return false
+		}
+	}
+	last := len(chunks) - 1
+	if chunks[last].Op != script.OpCHECKSIG || chunks[last-1].Op != script.OpEQUALVERIFY || !isDataPush(chunks[last-2]) {
+		return false
+	}
+
+	var hashOp string
+	if len(chunks) == n+4 {
+		if !isHashOp(chunks[n].Op) {
+			return false
+		}
+		hashOp = script.OpCodeValues[chunks[n].Op]
+	}
+	classified.Type = ScriptRPuzzle
+	classified.Hash = chunks[last-2].Data
+	classified.HashOp = hashOp
+	classified.RequiredSigs = 1
+	return true
+}
+
+// classifyHashPuzzle matches <hash op> <hash> OP_EQUAL.
+func classifyHashPuzzle(classified *ClassifiedScript, chunks []*script.ScriptChunk) bool {
+	if len(chunks) != 3 || !isHashOp(chunks[0].Op) || !isDataPush(chunks[1]) || chunks[2].Op != script.OpEQUAL {
+		return false
+	}
+	classified.Type = ScriptHashPuzzle
+	classified.Hash = chunks[1].Data
+	classified.HashOp = script.OpCodeValues[chunks[0].Op]
+	return true
+}
+
+// classifyOrdinal matches an OP_FALSE OP_IF "ord" ... OP_ENDIF envelope, with
+// an optional P2PKH lock before or after it, and optional OP_RETURN metadata
+// at the end.
+func classifyOrdinal(classified *ClassifiedScript, chunks []*script.ScriptChunk, mainnet bool) bool {
+	start, end, inscription := findInscription(chunks)
+	if inscription == nil {
+		return false
+	}
+
+	// The rest of the script is the lock (P2PKH before or after the envelope),
+	// up to the metadata
+	lock := append(append([]*script.ScriptChunk{}, chunks[:start]...), chunks[end+1:]...)
+	if i := slices.IndexFunc(lock, func(chunk *script.ScriptChunk) bool { return chunk.Op == script.OpRETURN }); i >= 0 {
+		lock = lock[:i]
+	}
+	if isP2PKHChunks(lock) {
+		address, err := addressFromHash160(lock[2].Data, mainnet)
+		if err != nil {
+			return false
+		}
+		classified.Addresses = []string{address}
+		classified.RequiredSigs = 1
+	}
+	classified.Type = ScriptOrdinal
+	classified.Inscription = inscription
+	return true
+}
+
+// findInscription finds an ordinal envelope and returns the index of its OP_FALSE
+// and OP_ENDIF chunks and the inscription (nil if there is none).
+//
+// The envelope is OP_FALSE OP_IF "ord" [<tag> <value>]... OP_0 <content>...
+// OP_ENDIF, where tag 1 is the content type.
+func findInscription(chunks []*script.ScriptChunk) (int, int, *Inscription) {
+	for start := 0; start+3 < len(chunks); start++ {
+		if chunks[start].Op != script.OpFALSE || chunks[start+1].Op != script.OpIF ||
+			!bytes.Equal(chunks[start+2].Data, []byte("ord")) {
+			continue
+		}
+
+		inscription := &Inscription{}
+		for i := start + 3; i < len(chunks); i++ {
+			if chunks[i].Op == script.OpENDIF {
+				return start, i, inscription
+			}
+
+			// Tag 0 (the body) is followed by the content, up to OP_ENDIF
+			if chunks[i].Op == script.OpFALSE {
+				for i++; i < len(chunks) && chunks[i].Op != script.OpENDIF; i++ {
+					inscription.Content = append(inscription.Content, chunks[i].Data...)
+				}
+				if i == len(chunks) {
+					break
+				}
+				return start, i, inscription
+			}
+
+			// Other tags have a value; tag 1 is the content type
+			if i+1 == len(chunks) {
+				break
+			}
+			if tag, ok := smallInt(chunks[i]); (ok && tag == 1) || bytes.Equal(chunks[i].Data, []byte{1}) {
+				inscription.ContentType = string(chunks[i+1].Data)
+			}
+			i++
+		}
+	}
+	return 0, 0, nil
+}
+
+// scriptChunks parses a script into opcodes and data pushes; parsed is false
+// if the script ends in a truncated push.
+func scriptChunks(raw []byte) (chunks []*script.ScriptChunk, parsed bool) {
+	s := script.NewFromBytes(raw)
+	for pos := 0; pos < len(raw); {
+		chunk, err := s.ReadOp(&pos)
+		if err != nil {
+			return chunks, false
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, true
+}
+
+// chunksData returns the data pushes of chunks (skipping opcodes).
+func chunksData(chunks []*script.ScriptChunk) [][]byte {
+	var data [][]byte
+	for _, chunk := range chunks {
+		if isDataPush(chunk) {
+			data = append(data, chunk.Data)
+		}
+	}
+	return data
+}
+
+// isP2PKHChunks returns true for OP_DUP OP_HASH160 <20 bytes> OP_EQUALVERIFY OP_CHECKSIG.
+func isP2PKHChunks(chunks []*script.ScriptChunk) bool {
+	return len(chunks) == 5 &&
+		chunks[0].Op == script.OpDUP &&
+		chunks[1].Op == script.OpHASH160 &&
+		chunks[2].Op == script.OpDATA20 &&
+		chunks[3].Op == script.OpEQUALVERIFY &&
+		chunks[4].Op == script.OpCHECKSIG
+}
+
+// isDataPush returns true if the chunk pushes data (not an opcode or an empty push).
+func isDataPush(chunk *script.ScriptChunk) bool {
+	return chunk.Op > script.OpFALSE && chunk.Op <= script.OpPUSHDATA4 && len(chunk.Data) > 0
+}
+
+// isHashOp returns true for the hashing opcodes.
+func isHashOp(op byte) bool {
+	switch op {
+	case script.OpRIPEMD160, script.OpSHA1, script.OpSHA256, script.OpHASH160, script.OpHASH256:
+		return true
+	}
+	return false
+}
+
+// smallInt returns the value of OP_0 to OP_16.
+func smallInt(chunk *script.ScriptChunk) (int, bool) {
+	switch {
+	case chunk.Op == script.OpFALSE:
+		return 0, true
+	case chunk.Op >= script.Op1 && chunk.Op <= script.Op16:
+		return int(chunk.Op-script.Op1) + 1, true
+	}
+	return 0, false
+}
+
+// pubKeyAddress returns the address of a pushed public key, if it is valid.
+func pubKeyAddress(chunk *script.ScriptChunk, mainnet bool) (string, bool) {
+	if !isDataPush(chunk) {
+		return "", false
+	}
+	pubKey, err := ec.ParsePubKey(chunk.Data)
+	if err != nil {
+		return "", false
+	}
+	address, err := GetAddressFromPubKey(pubKey, len(chunk.Data) == ec.PubKeyBytesLenCompressed, mainnet)
+	if err != nil {
+		return "", false
+	}
+	return address.AddressString, true
+}
+
+// addressFromHash160 returns the P2PKH address of a hash160.
+func addressFromHash160(hash160 []byte, mainnet bool) (string, error) {
+	address, err := bscript.NewAddressFromPublicKeyHash(hash160, mainnet)
+	if err != nil {
+		return "", err
+	}
+	return address.AddressString, nil
+}
@@ -0,0 +1,221 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// testHash20 and testHash32 are placeholder hashes for the puzzle scripts
+	testHash20 = "0102030405060708090a0b0c0d0e0f1011121314"
+	testHash32 = "0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20"
+
+	// testRPuzzlePrefix is OP_OVER OP_3 OP_SPLIT OP_NIP OP_TRUE OP_SPLIT OP_SWAP OP_SPLIT OP_DROP
+	testRPuzzlePrefix = "78537f77517f7c7f75"
+
+	// testInscription is OP_FALSE OP_IF "ord" OP_1 "text/plain" OP_0 "hello" OP_ENDIF
+	testInscription = "0063036f7264510a746578742f706c61696e000568656c6c6f68"
+)
+
+// TestClassifyScript will test the method ClassifyScript()
+func TestClassifyScript(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                 string
+		script               string
+		expectedType         ScriptType
+		expectedAddresses    []string
+		expectedPubKeys      []string
+		expectedData         []string
+		expectedRequiredSigs int
+		expectedHash         string
+		expectedHashOp       string
+		expectedASM          string
+	}{
+		{
+			"p2pkh", testScriptPubKey, ScriptP2PKH,
+			[]string{"1FHnmcTycCypk8e3WBaqM462GHcZJdSeJD"}, nil, nil, 1, "", "",
+			"OP_DUP OP_HASH160 9cbe9f5e72fa286ac8a38052d1d5337aa363ea7f OP_EQUALVERIFY OP_CHECKSIG",
+		},
+		{
+			"p2pk", "21" + testPubKeyCompressed + "ac", ScriptP2PK,
+			[]string{"1DfGxKmgL3ETwUdNnXLBueEvNpjcDGcKgK"}, []string{testPubKeyCompressed}, nil, 1, "", "",
+			testPubKeyCompressed + " OP_CHECKSIG",
+		},
+		{
+			"multisig 1 of 2", "5121" + testPubKeyG + "21" + testPubKey2G + "52ae", ScriptMultisig,
+			[]string{"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", "1cMh228HTCiwS8ZsaakH8A8wze1JR5ZsP"},
+			[]string{testPubKeyG, testPubKey2G}, nil, 1, "", "",
+			"1 " + testPubKeyG + " " + testPubKey2G + " 2 OP_CHECKMULTISIG",
+		},
+		{
+			"op_return", "6a0568656c6c6f0103", ScriptOpReturn,
+			nil, nil, []string{"68656c6c6f", "03"}, 0, "", "",
+			"OP_RETURN 68656c6c6f 3",
+		},
+		{
+			"op_false op_return", "006a0568656c6c6f", ScriptOpFalseOpReturn,
+			nil, nil, []string{"68656c6c6f"}, 0, "", "",
+			"0 OP_RETURN 68656c6c6f",
+		},
+		{
+			"truncated op_return", "006a0568656c", ScriptOpFalseOpReturn,
+			nil, nil, nil, 0, "", "",
+			"0 OP_RETURN [error]",
+		},
+		{
+			"r-puzzle with hash", testRPuzzlePrefix + "a914" + testHash20 + "88ac", ScriptRPuzzle,
+			nil, nil, nil, 1, testHash20, "OP_HASH160",
+			"OP_OVER 3 OP_SPLIT OP_NIP 1 OP_SPLIT OP_SWAP OP_SPLIT OP_DROP OP_HASH160 " + testHash20 + " OP_EQUALVERIFY OP_CHECKSIG",
+		},
+		{
+			"r-puzzle with R", testRPuzzlePrefix + "20" + testHash32 + "88ac", ScriptRPuzzle,
+			nil, nil, nil, 1, testHash32, "",
+			"OP_OVER 3 OP_SPLIT OP_NIP 1 OP_SPLIT OP_SWAP OP_SPLIT OP_DROP " + testHash32 + " OP_EQUALVERIFY OP_CHECKSIG",
+		},
+		{
+			"sha256 hash puzzle", "a820" + testHash32 + "87", ScriptHashPuzzle,
+			nil, nil, nil, 0, testHash32, "OP_SHA256",
+			"OP_SHA256 " + testHash32 + " OP_EQUAL",
+		},
+		{
+			"legacy p2sh pattern", "a914" + testHash20 + "87", ScriptHashPuzzle,
+			nil, nil, nil, 0, testHash20, "OP_HASH160",
+			"OP_HASH160 " + testHash20 + " OP_EQUAL",
+		},
+		{
+			"unknown", "5152935387", ScriptUnknown,
+			nil, nil, nil, 0, "", "",
+			"1 2 OP_ADD 3 OP_EQUAL",
+		},
+		{
+			"multisig with invalid key", "5121" + strings.Repeat("00", 33) + "51ae", ScriptUnknown,
+			nil, nil, nil, 0, "", "",
+			"1 " + strings.Repeat("00", 33) + " 1 OP_CHECKMULTISIG",
+		},
+		{
+			"multisig with wrong count", "5121" + testPubKeyG + "52ae", ScriptUnknown,
+			nil, nil, nil, 0, "", "",
+			"1 " + testPubKeyG + " 2 OP_CHECKMULTISIG",
+		},
+		{
+			"truncated push", "76a9140102", ScriptUnknown,
+			nil, nil, nil, 0, "", "",
+			"OP_DUP OP_HASH160 [error]",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			classified, err := ClassifyScript(test.script, NetworkMainnet)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedType, classified.Type)
+			assert.Equal(t, test.expectedAddresses, classified.Addresses)
+			assert.Equal(t, test.expectedPubKeys, classified.PubKeys)
+			assert.Equal(t, test.expectedRequiredSigs, classified.RequiredSigs)
+			assert.Equal(t, test.expectedHash, hex.EncodeToString(classified.Hash))
+			assert.Equal(t, test.expectedHashOp, classified.HashOp)
+			assert.Equal(t, test.expectedASM, classified.ASM)
+
+			var data []string
+			for _, push := range classified.Data {
+				data = append(data, hex.EncodeToString(push))
+			}
+			assert.Equal(t, test.expectedData, data)
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		_, err := ClassifyScript("", NetworkMainnet)
+		require.ErrorIs(t, err, ErrMissingScript)
+		_, err = ClassifyScript("zz", NetworkMainnet)
+		require.Error(t, err)
+	})
+
+	t.Run("testnet addresses", func(t *testing.T) {
+		t.Parallel()
+		classified, err := ClassifyScript("21"+testPubKeyCompressed+"ac", NetworkTestnet)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"mtBEFNrf94fiib6zW6JZjZTFEpLK7RqN3i"}, classified.Addresses)
+	})
+}
+
+// TestClassifyScript_Ordinal will test classifying 1Sat ordinal inscriptions
+func TestClassifyScript_Ordinal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		script            string
+		expectedAddresses []string
+		expectedType      string
+		expectedContent   string
+	}{
+		{"p2pkh then inscription", testScriptPubKey + testInscription, []string{"1FHnmcTycCypk8e3WBaqM462GHcZJdSeJD"}, "text/plain", "hello"},
+		{"inscription then p2pkh", testInscription + testScriptPubKey, []string{"1FHnmcTycCypk8e3WBaqM462GHcZJdSeJD"}, "text/plain", "hello"},
+		{"inscription only", testInscription, nil, "text/plain", "hello"},
+		{
+			"split content and extra field",
+			"0063036f7264" + "0105" + "0178" + "51" + "10" + hex.EncodeToString([]byte("application/json")) +
+				"00" + "027b7d" + "020100" + "68",
+			nil, "application/json", "{}\x01\x00",
+		},
+		{"no content type", "0063036f7264" + "00" + "03686921" + "68", nil, "", "hi!"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			classified, err := ClassifyScript(test.script, NetworkMainnet)
+			require.NoError(t, err)
+			require.Equal(t, ScriptOrdinal, classified.Type, classified.ASM)
+			require.NotNil(t, classified.Inscription)
+			assert.Equal(t, test.expectedAddresses, classified.Addresses)
+			assert.Equal(t, test.expectedType, classified.Inscription.ContentType)
+			assert.Equal(t, test.expectedContent, string(classified.Inscription.Content))
+		})
+	}
+
+	t.Run("unterminated envelope", func(t *testing.T) {
+		t.Parallel()
+		classified, err := ClassifyScript(strings.TrimSuffix(testInscription, "68"), NetworkMainnet)
+		require.NoError(t, err)
+		assert.Equal(t, ScriptUnknown, classified.Type)
+	})
+}
+
+// TestScriptType_String will test the method String()
+func TestScriptType_String(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "p2pkh", ScriptP2PKH.String())
+	assert.Equal(t, "op_false_op_return", ScriptOpFalseOpReturn.String())
+	assert.Equal(t, "hash_puzzle", ScriptHashPuzzle.String())
+	assert.Equal(t, "unknown", ScriptType(200).String())
+}
+
+// ExampleClassifyScript example using ClassifyScript()
+func ExampleClassifyScript() {
+	classified, err := ClassifyScript("76a9149cbe9f5e72fa286ac8a38052d1d5337aa363ea7f88ac", NetworkMainnet)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("%s to %s: %s", classified.Type, classified.Addresses[0], classified.ASM)
+	// Output:p2pkh to 1FHnmcTycCypk8e3WBaqM462GHcZJdSeJD: OP_DUP OP_HASH160 9cbe9f5e72fa286ac8a38052d1d5337aa363ea7f OP_EQUALVERIFY OP_CHECKSIG
+}
+
+// BenchmarkClassifyScript benchmarks the method ClassifyScript()
+func BenchmarkClassifyScript(b *testing.B) {
+	for b.Loop() {
+		_, _ = ClassifyScript(testScriptPubKey+testInscription, NetworkMainnet)
+	}
+}
@@ -0,0 +1,320 @@
+package bitcoin
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	hash "github.com/bsv-blockchain/go-sdk/primitives/hash"
+)
+
+var (
+	// ErrSecretKeyDestroyed is returned when a destroyed SecretKey is used
+	ErrSecretKeyDestroyed = errors.New("secret key has been destroyed")
+
+	// ErrInvalidBase58 is returned when base58 input contains an invalid character
+	ErrInvalidBase58 = errors.New("invalid base58 character")
+)
+
+// curveOrder is the secp256k1 curve order (big-endian), the bound of a private key
+var curveOrder = ec.S256().N.FillBytes(make([]byte, privKeyBytesLen))
+
+// redacted replaces a SecretKey in formatted output, logs and JSON
+const redacted = "[REDACTED]"
+
+// SecretKey holds a private key in a buffer that is zeroed by Destroy
+//
+// A SecretKey is redacted when formatted with fmt, logged with slog or
+// marshaled to JSON or text; use Bytes, WIF or PrivateKey to get the key. It is
+// safe for concurrent use.
+type SecretKey struct {
+	state *secretKeyState
+}
+
+// secretKeyState is shared by copies of a SecretKey, so Destroy wipes them all.
+type secretKeyState struct {
+	mu  sync.RWMutex
+	key []byte
+}
+
+// NewSecretKey will create a SecretKey from a raw 32 byte private key (a
+// non-zero scalar below the curve order)
+//
+// The bytes are copied, so the caller should zero privateKey after the call.
+func NewSecretKey(privateKey []byte) (*SecretKey, error) {
+	if len(privateKey) != privKeyBytesLen || isZero(privateKey) || !isBelowCurveOrder(privateKey) {
+		return nil, ErrMalformedPrivateKey
+	}
+	return &SecretKey{state: &secretKeyState{key: bytes.Clone(privateKey)}}, nil
+}
+
+// NewSecretKeyFromPrivateKey will create a SecretKey from an ec.PrivateKey
+//
+// The caller should wipe privateKey with ZeroPrivateKey after the call.
+func NewSecretKeyFromPrivateKey(privateKey *ec.PrivateKey) (*SecretKey, error) {
+	if privateKey == nil {
+		return nil, ErrPrivateKeyMissing
+	}
+	serialized := privateKey.Serialize()
+	defer clear(serialized)
+	return NewSecretKey(serialized)
+}
+
+// GenerateSecretKey will create a new random SecretKey
+func GenerateSecretKey() (*SecretKey, error) {
+	privateKey, err := CreatePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	defer ZeroPrivateKey(privateKey)
+	return NewSecretKeyFromPrivateKey(privateKey)
+}
+
+// Bytes will return a copy of the raw 32 byte private key
+//
+// The caller should zero the copy after use.
+func (k SecretKey) Bytes() ([]byte, error) {
+	var key []byte
+	err := k.use(func(secret []byte) error {
+		key = bytes.Clone(secret)
+		return nil
+	})
+	return key, err
+}
+
+// WIF will return the mainnet WIF of the private key (see PrivateKeyToWifBytes)
+//
+// The caller should zero the WIF after use.
+func (k SecretKey) WIF(compress bool) ([]byte, error) {
+	var wif []byte
+	err := k.use(func(secret []byte) (err error) {
+		wif, err = PrivateKeyToWifBytes(secret, compress)
+		return err
+	})
+	return wif, err
+}
+
+// PrivateKey will return the key as an ec.PrivateKey
+//
+// The caller should wipe it with ZeroPrivateKey after use; Use does this automatically.
+func (k SecretKey) PrivateKey() (*ec.PrivateKey, error) {
+	var privateKey *ec.PrivateKey
+	err := k.use(func(secret []byte) error {
+		privateKey, _ = ec.PrivateKeyFromBytes(secret)
+		return nil
+	})
+	return privateKey, err
+}
+
+// PubKey will return the public key of the private key
+func (k SecretKey) PubKey() (*ec.PublicKey, error) {
+	var pubKey *ec.PublicKey
+	err := k.Use(func(privateKey *ec.PrivateKey) error {
+		pubKey = privateKey.PubKey()
+		return nil
+	})
+	return pubKey, err
+}
+
+// Use will call fn with the key as an ec.PrivateKey, wiping it when fn returns
+//
+// fn must not keep a reference to the private key.
+func (k SecretKey) Use(fn func(privateKey *ec.PrivateKey) error) error {
+	privateKey, err := k.PrivateKey()
+	if err != nil {
+		return err
+	}
+	defer ZeroPrivateKey(privateKey)
+	return fn(privateKey)
+}
+
+// Destroy will zero the key; every later use returns ErrSecretKeyDestroyed
+func (k SecretKey) Destroy() {
+	if k.state == nil {
+		return
+	}
+	k.state.mu.Lock()
+	defer k.state.mu.Unlock()
+	clear(k.state.key)
+	k.state.key = nil
+}
+
+// IsDestroyed returns true once Destroy has been called
+func (k SecretKey) IsDestroyed() bool {
+	return k.use(func([]byte) error { return nil }) != nil
+}
+
+// Equal will compare two keys in constant time (destroyed keys are never equal)
+func (k SecretKey) Equal(other *SecretKey) bool {
+	if other == nil {
+		return false
+	} else if k.state == other.state {
+		// The same buffer (avoids taking the read lock twice)
+		return !k.IsDestroyed()
+	}
+
+	// Copy one key rather than holding both read locks, which deadlocks when
+	// a.Equal(b) and b.Equal(a) race with a pending Destroy
+	secret, err := k.Bytes()
+	if err != nil {
+		return false
+	}
+	defer clear(secret)
+	equal := false
+	_ = other.use(func(otherSecret []byte) error {
+		equal = subtle.ConstantTimeCompare(secret, otherSecret) == 1
+		return nil
+	})
+	return equal
+}
+
+// String returns "[REDACTED]"
+func (k SecretKey) String() string {
+	return redacted
+}
+
+// Format writes "[REDACTED]" for every fmt verb (%v, %+v, %#v, %s, %x, ...)
+func (k SecretKey) Format(f fmt.State, _ rune) {
+	_, _ = f.Write([]byte(redacted))
+}
+
+// LogValue returns "[REDACTED]" for slog
+func (k SecretKey) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// MarshalJSON returns "[REDACTED]" as a JSON string
+func (k SecretKey) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
+
+// MarshalText returns "[REDACTED]"
+func (k SecretKey) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+// use calls fn with the key buffer under the read lock.
+func (k SecretKey) use(fn func(secret []byte) error) error {
+	if k.state == nil {
+		return ErrSecretKeyDestroyed
+	}
+	k.state.mu.RLock()
+	defer k.state.mu.RUnlock()
+	if k.state.key == nil {
+		return ErrSecretKeyDestroyed
+	}
+	return fn(k.state.key)
+}
+
+// ZeroPrivateKey will wipe the scalar of an ec.PrivateKey (a nil key is ignored)
+func ZeroPrivateKey(privateKey *ec.PrivateKey) {
+	if privateKey == nil || privateKey.D == nil {
+		return
+	}
+	clear(privateKey.D.Bits())
+	privateKey.D.SetInt64(0)
+}
+
+// isZero returns true if every byte is zero, in constant time.
+func isZero(b []byte) bool {
+	var acc byte
+	for _, v := range b {
+		acc |= v
+	}
+	return acc == 0
+}
+
+// isBelowCurveOrder returns true if a 32 byte big-endian scalar is below the
+// curve order, in constant time.
+func isBelowCurveOrder(b []byte) bool {
+	// The borrow of b - curveOrder is set when b is smaller
+	borrow := 0
+	for i := len(b) - 1; i >= 0; i-- {
+		borrow = ((int(b[i]) - int(curveOrder[i]) - borrow) >> 8) & 1
+	}
+	return borrow == 1
+}
+
+// wifBytes encodes a raw private key as a WIF without creating strings.
+func wifBytes(privateKey []byte, netID byte, compress bool) []byte {
+	payload := make([]byte, 0, 1+privKeyBytesLen+1+4)
+	payload = append(payload, netID)
+	payload = append(payload, privateKey...)
+	if compress {
+		payload = append(payload, compressMagic)
+	}
+	payload = append(payload, hash.Sha256d(payload)[:4]...)
+	defer clear(payload)
+	return base58EncodeBytes(payload)
+}
+
+// base58EncodeBytes encodes input as base58 into a new byte slice, zeroing
+// its scratch buffer (the base58 package only works with strings).
+func base58EncodeBytes(input []byte) []byte {
+	zeros := 0
+	for zeros < len(input) && input[zeros] == 0 {
+		zeros++
+	}
+
+	// log(256) / log(58), rounded up
+	size := (len(input)-zeros)*138/100 + 1
+	digits := make([]byte, size)
+	defer clear(digits)
+	length := 0
+	for _, v := range input[zeros:] {
+		carry := int(v)
+		i := 0
+		for j := size - 1; (carry != 0 || i < length) && j >= 0; j-- {
+			carry += 256 * int(digits[j])
+			digits[j] = byte(carry % 58) // #nosec G115 -- carry % 58 < 58
+			carry /= 58
+			i++
+		}
+		length = i
+	}
+
+	encoded := make([]byte, 0, zeros+length)
+	for range zeros {
+		encoded = append(encoded, tmpl[0])
+	}
+	for _, digit := range digits[size-length:] {
+		encoded = append(encoded, tmpl[digit])
+	}
+	return encoded
+}
+
+// base58DecodeBytes decodes base58 input into a new byte slice, zeroing its
+// scratch buffer.
+func base58DecodeBytes(input []byte) ([]byte, error) {
+	zeros := 0
+	for zeros < len(input) && input[zeros] == tmpl[0] {
+		zeros++
+	}
+
+	// log(58) / log(256), rounded up
+	size := (len(input)-zeros)*733/1000 + 1
+	decoded := make([]byte, size)
+	defer clear(decoded)
+	length := 0
+	for _, ch := range input[zeros:] {
+		carry := bytes.IndexByte(tmpl, ch)
+		if carry < 0 {
+			return nil, ErrInvalidBase58
+		}
+		i := 0
+		for j := size - 1; (carry != 0 || i < length) && j >= 0; j-- {
+			carry += 58 * int(decoded[j])
+			decoded[j] = byte(carry % 256) // #nosec G115 -- carry % 256 < 256
+			carry /= 256
+			i++
+		}
+		length = i
+	}
+
+	result := make([]byte, zeros, zeros+length)
+	return append(result, decoded[size-length:]...), nil
+}
@@ -0,0 +1,269 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mustSecretKey creates a SecretKey from testPrivateKeyHex, failing the test on error
+func mustSecretKey(t *testing.T) *SecretKey {
+	t.Helper()
+	rawKey, err := hex.DecodeString(testPrivateKeyHex)
+	require.NoError(t, err)
+	key, err := NewSecretKey(rawKey)
+	require.NoError(t, err)
+	return key
+}
+
+// TestNewSecretKey will test the constructors of SecretKey
+func TestNewSecretKey(t *testing.T) {
+	t.Parallel()
+
+	t.Run("raw bytes are copied", func(t *testing.T) {
+		t.Parallel()
+		rawKey, err := hex.DecodeString(testPrivateKeyHex)
+		require.NoError(t, err)
+		key, err := NewSecretKey(rawKey)
+		require.NoError(t, err)
+		clear(rawKey)
+
+		keyBytes, err := key.Bytes()
+		require.NoError(t, err)
+		assert.Equal(t, testPrivateKeyHex, hex.EncodeToString(keyBytes))
+	})
+
+	t.Run("from private key", func(t *testing.T) {
+		t.Parallel()
+		privateKey, err := PrivateKeyFromString(testPrivateKeyHex)
+		require.NoError(t, err)
+		key, err := NewSecretKeyFromPrivateKey(privateKey)
+		require.NoError(t, err)
+		assert.True(t, key.Equal(mustSecretKey(t)))
+
+		_, err = NewSecretKeyFromPrivateKey(nil)
+		require.ErrorIs(t, err, ErrPrivateKeyMissing)
+	})
+
+	t.Run("generate", func(t *testing.T) {
+		t.Parallel()
+		key, err := GenerateSecretKey()
+		require.NoError(t, err)
+		other, err := GenerateSecretKey()
+		require.NoError(t, err)
+		assert.False(t, key.Equal(other))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewSecretKey(nil)
+		require.ErrorIs(t, err, ErrMalformedPrivateKey)
+		_, err = NewSecretKey(make([]byte, 31))
+		require.ErrorIs(t, err, ErrMalformedPrivateKey)
+		_, err = NewSecretKey(make([]byte, 32))
+		require.ErrorIs(t, err, ErrMalformedPrivateKey)
+
+		// Scalars at or above the curve order
+		order := ec.S256().N.Bytes()
+		_, err = NewSecretKey(order)
+		require.ErrorIs(t, err, ErrMalformedPrivateKey)
+		_, err = NewSecretKey(bytes.Repeat([]byte{0xff}, 32))
+		require.ErrorIs(t, err, ErrMalformedPrivateKey)
+
+		order[31]--
+		key, err := NewSecretKey(order)
+		require.NoError(t, err)
+		assert.False(t, key.IsDestroyed())
+	})
+}
+
+// TestSecretKeyConversions will test the methods WIF(), PrivateKey(), PubKey() and Use()
+func TestSecretKeyConversions(t *testing.T) {
+	t.Parallel()
+
+	key := mustSecretKey(t)
+
+	for _, compress := range []bool{false, true} {
+		wif, err := key.WIF(compress)
+		require.NoError(t, err)
+		expected, err := PrivateKeyToWifStringWithCompression(testPrivateKeyHex, compress)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(wif))
+	}
+
+	privateKey, err := key.PrivateKey()
+	require.NoError(t, err)
+	assert.Equal(t, testPrivateKeyHex, hex.EncodeToString(privateKey.Serialize()))
+
+	pubKey, err := key.PubKey()
+	require.NoError(t, err)
+	assert.Equal(t, testPubKeyCompressed, hex.EncodeToString(pubKey.Compressed()))
+
+	// Use wipes the private key after the callback
+	var used *ec.PrivateKey
+	require.NoError(t, key.Use(func(privateKey *ec.PrivateKey) error {
+		used = privateKey
+		assert.Equal(t, testPrivateKeyHex, hex.EncodeToString(privateKey.Serialize()))
+		return nil
+	}))
+	assert.Equal(t, 0, used.D.Sign())
+
+	errCallback := fmt.Errorf("%w: callback", ErrMissingScript)
+	require.ErrorIs(t, key.Use(func(*ec.PrivateKey) error { return errCallback }), errCallback)
+}
+
+// TestSecretKeyDestroy will test the method Destroy()
+func TestSecretKeyDestroy(t *testing.T) {
+	t.Parallel()
+
+	key := mustSecretKey(t)
+	other := mustSecretKey(t)
+	copied := *key
+	assert.True(t, key.Equal(other))
+	assert.True(t, key.Equal(key))
+	assert.False(t, key.IsDestroyed())
+
+	key.Destroy()
+	key.Destroy() // Idempotent
+	assert.True(t, key.IsDestroyed())
+	assert.True(t, copied.IsDestroyed(), "copies share the buffer")
+	assert.False(t, key.Equal(other))
+	assert.False(t, key.Equal(key))
+	assert.False(t, other.Equal(key))
+
+	_, err := key.Bytes()
+	require.ErrorIs(t, err, ErrSecretKeyDestroyed)
+	_, err = key.WIF(true)
+	require.ErrorIs(t, err, ErrSecretKeyDestroyed)
+	_, err = key.PrivateKey()
+	require.ErrorIs(t, err, ErrSecretKeyDestroyed)
+	_, err = key.PubKey()
+	require.ErrorIs(t, err, ErrSecretKeyDestroyed)
+
+	// The zero value behaves as destroyed
+	var zero SecretKey
+	assert.True(t, zero.IsDestroyed())
+	zero.Destroy()
+	assert.False(t, other.Equal(nil))
+	assert.False(t, other.Equal(&zero))
+}
+
+// TestSecretKeyEqualConcurrency will test comparing keys both ways while they are destroyed
+func TestSecretKeyEqualConcurrency(t *testing.T) {
+	t.Parallel()
+
+	for range 100 {
+		a, b := mustSecretKey(t), mustSecretKey(t)
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Go(func() { a.Equal(b) })
+			wg.Go(func() { b.Equal(a) })
+		}
+		wg.Go(a.Destroy)
+		wg.Go(b.Destroy)
+		wg.Wait()
+		assert.False(t, a.Equal(b))
+	}
+}
+
+// TestSecretKeyRedaction will test that a SecretKey never prints its key
+func TestSecretKeyRedaction(t *testing.T) {
+	t.Parallel()
+
+	key := mustSecretKey(t)
+	wrapper := struct {
+		Name string
+		Key  *SecretKey
+	}{"treasury", key}
+
+	t.Run("fmt", func(t *testing.T) {
+		t.Parallel()
+		for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%x", "%X", "%q", "%d"} {
+			for _, value := range []any{key, *key, wrapper} {
+				out := fmt.Sprintf(verb, value)
+				assert.Contains(t, out, redacted, verb)
+				assert.NotContains(t, out, testPrivateKeyHex[:8], verb)
+			}
+		}
+		assert.Equal(t, redacted, key.String())
+	})
+
+	t.Run("slog", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+		logger.Info("loaded", "key", key)
+		assert.Contains(t, buf.String(), `"key":"[REDACTED]"`)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+		out, err := json.Marshal(wrapper)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Name":"treasury","Key":"[REDACTED]"}`, string(out))
+
+		text, err := key.MarshalText()
+		require.NoError(t, err)
+		assert.Equal(t, redacted, string(text))
+	})
+}
+
+// TestZeroPrivateKey will test the method ZeroPrivateKey()
+func TestZeroPrivateKey(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := PrivateKeyFromString(testPrivateKeyHex)
+	require.NoError(t, err)
+	words := privateKey.D.Bits()
+	ZeroPrivateKey(privateKey)
+	assert.Equal(t, 0, privateKey.D.Sign())
+	for _, word := range words {
+		assert.Zero(t, word)
+	}
+
+	// Nil keys are ignored
+	ZeroPrivateKey(nil)
+	ZeroPrivateKey(&ec.PrivateKey{})
+}
+
+// ExampleSecretKey example using SecretKey
+func ExampleSecretKey() {
+	wif, err := PrivateKeyToWifBytes(bytes.Repeat([]byte{0x01}, 32), true)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	rawKey, err := WifToPrivateKeyBytes(wif)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	clear(wif)
+
+	var key *SecretKey
+	if key, err = NewSecretKey(rawKey); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	clear(rawKey)
+	defer key.Destroy()
+
+	fmt.Printf("key: %v", key)
+	// Output:key: [REDACTED]
+}
+
+// BenchmarkSecretKeyUse benchmarks the method SecretKey.Use()
+func BenchmarkSecretKeyUse(b *testing.B) {
+	key, _ := GenerateSecretKey()
+	for b.Loop() {
+		_ = key.Use(func(*ec.PrivateKey) error { return nil })
+	}
+}
@@ -0,0 +1,424 @@
+package bitcoin
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	base58 "github.com/bsv-blockchain/go-sdk/compat/base58"
+	"github.com/bsv-blockchain/go-sdk/compat/bip39/wordlists"
+	hash "github.com/bsv-blockchain/go-sdk/primitives/hash"
+)
+
+var (
+	// ErrInvalidThreshold is returned when a threshold is zero or larger than the share count
+	ErrInvalidThreshold = errors.New("threshold must satisfy 1 <= threshold <= count")
+
+	// ErrInvalidSecretLength is returned when a secret is empty or too long to split
+	ErrInvalidSecretLength = errors.New("secret must be 1 to 251 bytes")
+
+	// ErrInvalidShare is returned when a share cannot be decoded
+	ErrInvalidShare = errors.New("invalid share")
+
+	// ErrShareMismatch is returned when shares from different splits are combined
+	ErrShareMismatch = errors.New("shares are not from the same split")
+
+	// ErrInsufficientShares is returned when there are not enough shares to
+	// recover the secret
+	ErrInsufficientShares = errors.New("insufficient shares")
+)
+
+const (
+	// shareVersion is the version byte of the share encoding
+	shareVersion = 0x01
+
+	// shareHeaderLength is the length of the share header:
+	// version (1) || id (2) || group index (1) || group threshold (1) ||
+	// group count (1) || member index (1) || member threshold (1) || value length (1)
+	shareHeaderLength = 9
+
+	// shareChecksumLength is the length of the share and secret checksums
+	shareChecksumLength = 4
+
+	// maxShareGroups is the maximum number of groups in a split
+	maxShareGroups = 16
+
+	// maxSecretLength is the longest secret that can be split (the value length
+	// is one byte and includes the secret checksum)
+	maxSecretLength = 255 - shareChecksumLength
+)
+
+// ShareGroup is the member threshold and count of one group of a split
+type ShareGroup struct {
+	Threshold uint8
+	Count     uint8
+}
+
+// Share is one share of a secret split with Shamir's secret sharing over GF(256)
+//
+// The secret is first split into GroupCount group secrets (any GroupThreshold of
+// them recover it), and every group secret is split into member shares (any
+// Threshold of them recover the group secret). A single group split has a
+// GroupThreshold and GroupCount of 1. The secret is split together with a
+// 4 byte SHA-256 checksum, so a wrong combination of shares is detected.
+type Share struct {
+	ID             uint16 // Random identifier of the split
+	GroupIndex     uint8  // 0-based index of the group
+	GroupThreshold uint8  // Groups required to recover the secret
+	GroupCount     uint8  // Total number of groups
+	Index          uint8  // 1-based index of the member (the x coordinate)
+	Threshold      uint8  // Members of the group required to recover the group secret
+	Value          []byte // The share of the secret (and secret checksum)
+}
+
+// SplitSecret will split a secret (such as an HD seed) into count shares, any
+// threshold of which recover it with CombineShares
+func SplitSecret(secret []byte, threshold, count uint8) ([]*Share, error) {
+	groups, err := SplitSecretGroups(secret, 1, []ShareGroup{{Threshold: threshold, Count: count}})
+	if err != nil {
+		return nil, err
+	}
+	return groups[0], nil
+}
+
+// SplitSecretGroups will split a secret into groups of shares: the secret is
+// recovered from groupThreshold groups, each with its own member threshold
+//
+// For example, a 2-of-3 split across 3 groups of 3-of-5, 2-of-3 and 1-of-1
+// shares lets any two of the groups recover the secret.
+func SplitSecretGroups(secret []byte, groupThreshold uint8, groups []ShareGroup) ([][]*Share, error) {
+	if len(secret) == 0 || len(secret) > maxSecretLength {
+		return nil, ErrInvalidSecretLength
+	}
+	if len(groups) > maxShareGroups || groupThreshold == 0 || int(groupThreshold) > len(groups) {
+		return nil, fmt.Errorf("%w: group threshold %d of %d groups (at most %d groups)",
+			ErrInvalidThreshold, groupThreshold, len(groups), maxShareGroups)
+	}
+	for _, group := range groups {
+		if group.Threshold == 0 || group.Threshold > group.Count {
+			return nil, fmt.Errorf("%w: member threshold %d of %d", ErrInvalidThreshold, group.Threshold, group.Count)
+		}
+	}
+
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+
+	// The secret checksum is split with the secret, so it is hidden by the shares
+	payload := append(bytes.Clone(secret), hash.Sha256(secret)[:shareChecksumLength]...)
+	groupValues, err := splitBytes(payload, groupThreshold, uint8(len(groups))) // #nosec G115 -- at most 16 groups
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([][]*Share, len(groups))
+	for i, group := range groups {
+		var memberValues [][]byte
+		if memberValues, err = splitBytes(groupValues[i], group.Threshold, group.Count); err != nil {
+			return nil, err
+		}
+		for j, value := range memberValues {
+			shares[i] = append(shares[i], &Share{
+				ID:             binary.BigEndian.Uint16(id[:]),
+				GroupIndex:     uint8(i), // #nosec G115 -- at most 16 groups
+				GroupThreshold: groupThreshold,
+				GroupCount:     uint8(len(groups)), // #nosec G115 -- at most 16 groups
+				Index:          uint8(j + 1),       // #nosec G115 -- at most 255 members
+				Threshold:      group.Threshold,
+				Value:          value,
+			})
+		}
+	}
+	return shares, nil
+}
+
+// CombineShares will recover a secret from enough shares of SplitSecret or
+// SplitSecretGroups, verifying the secret checksum
+//
+// Shares may be given in any order; duplicates are ignored.
+func CombineShares(shares []*Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrInsufficientShares
+	} else if len(shares[0].Value) <= shareChecksumLength {
+		return nil, fmt.Errorf("%w: value too short", ErrInvalidShare)
+	}
+
+	// Collect the members of every group
+	first := shares[0]
+	members := make(map[uint8]map[uint8][]byte)
+	thresholds := make(map[uint8]uint8)
+	for _, share := range shares {
+		if share.ID != first.ID || share.GroupThreshold != first.GroupThreshold ||
+			share.GroupCount != first.GroupCount || len(share.Value) != len(first.Value) {
+			return nil, ErrShareMismatch
+		}
+		group, ok := members[share.GroupIndex]
+		if !ok {
+			group = make(map[uint8][]byte)
+			members[share.GroupIndex] = group
+			thresholds[share.GroupIndex] = share.Threshold
+		} else if thresholds[share.GroupIndex] != share.Threshold {
+			return nil, fmt.Errorf("%w: different thresholds in group %d", ErrShareMismatch, share.GroupIndex)
+		}
+		if value, exists := group[share.Index]; exists && !bytes.Equal(value, share.Value) {
+			return nil, fmt.Errorf("%w: two different shares with index %d", ErrShareMismatch, share.Index)
+		}
+		group[share.Index] = share.Value
+	}
+
+	// Recover the group secrets with enough members (group i is at x = i+1)
+	groupValues := make(map[uint8][]byte)
+	for groupIndex, group := range members {
+		if len(group) >= int(thresholds[groupIndex]) {
+			groupValues[groupIndex+1] = combineBytes(group, thresholds[groupIndex])
+		}
+	}
+	if len(groupValues) < int(first.GroupThreshold) {
+		return nil, fmt.Errorf("%w: recovered %d of %d groups", ErrInsufficientShares, len(groupValues), first.GroupThreshold)
+	}
+
+	payload := combineBytes(groupValues, first.GroupThreshold)
+	secret, checksum := payload[:len(payload)-shareChecksumLength], payload[len(payload)-shareChecksumLength:]
+	if !bytes.Equal(hash.Sha256(secret)[:shareChecksumLength], checksum) {
+		return nil, fmt.Errorf("%w: the shares do not recover a valid secret", ErrChecksumMismatch)
+	}
+	return secret, nil
+}
+
+// SplitPrivateKey will split a hex private key into count shares, any
+// threshold of which recover it with CombinePrivateKey
+func SplitPrivateKey(privateKey string, threshold, count uint8) ([]*Share, error) {
+	rawKey, err := PrivateKeyFromString(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return SplitSecret(rawKey.Serialize(), threshold, count)
+}
+
+// CombinePrivateKey will recover a hex private key from shares of SplitPrivateKey
+func CombinePrivateKey(shares []*Share) (string, error) {
+	secret, err := CombineShares(shares)
+	if err != nil {
+		return "", err
+	} else if len(secret) != privKeyBytesLen {
+		return "", ErrMalformedPrivateKey
+	}
+	privateKey := hex.EncodeToString(secret)
+	if _, err = PrivateKeyFromString(privateKey); err != nil {
+		return "", err
+	}
+	return privateKey, nil
+}
+
+// SplitWIF will split a WIF (see PrivateKeyToWifString) into count shares, any
+// threshold of which recover it with CombineWIF
+//
+// The network and compression of the WIF are kept.
+func SplitWIF(wif string, threshold, count uint8) ([]*Share, error) {
+	if _, err := DecodeWIF(wif); err != nil {
+		return nil, err
+	}
+
+	// Split the WIF payload without its base58 checksum
+	decoded, err := base58.Decode(wif)
+	if err != nil {
+		return nil, err
+	}
+	return SplitSecret(decoded[:len(decoded)-4], threshold, count)
+}
+
+// CombineWIF will recover a WIF from shares of SplitWIF
+func CombineWIF(shares []*Share) (string, error) {
+	secret, err := CombineShares(shares)
+	if err != nil {
+		return "", err
+	}
+	wif := base58.Encode(append(secret, hash.Sha256d(secret)[:4]...))
+	if _, err = DecodeWIF(wif); err != nil {
+		return "", err
+	}
+	return wif, nil
+}
+
+// Bytes will encode the share: header || value || checksum (first 4 bytes of
+// the SHA-256 of the header and value)
+func (s *Share) Bytes() []byte {
+	encoded := make([]byte, 0, shareHeaderLength+len(s.Value)+shareChecksumLength)
+	encoded = append(encoded, shareVersion)
+	encoded = binary.BigEndian.AppendUint16(encoded, s.ID)
+	encoded = append(encoded, s.GroupIndex, s.GroupThreshold, s.GroupCount, s.Index, s.Threshold,
+		byte(len(s.Value))) // #nosec G115 -- values are at most 255 bytes
+	encoded = append(encoded, s.Value...)
+	return append(encoded, hash.Sha256(encoded)[:shareChecksumLength]...)
+}
+
+// ShareFromBytes will decode a share encoded with Share.Bytes, verifying its checksum
+func ShareFromBytes(encoded []byte) (*Share, error) {
+	if len(encoded) < shareHeaderLength+1+shareChecksumLength {
+		return nil, fmt.Errorf("%w: too short", ErrInvalidShare)
+	} else if encoded[0] != shareVersion {
+		return nil, fmt.Errorf("%w: unknown version %d", ErrInvalidShare, encoded[0])
+	}
+
+	end := shareHeaderLength + int(encoded[8])
+	if len(encoded) != end+shareChecksumLength {
+		return nil, fmt.Errorf("%w: length does not match the header", ErrInvalidShare)
+	}
+	if !bytes.Equal(hash.Sha256(encoded[:end])[:shareChecksumLength], encoded[end:]) {
+		return nil, fmt.Errorf("%w: share checksum", ErrChecksumMismatch)
+	}
+
+	share := &Share{
+		ID:             binary.BigEndian.Uint16(encoded[1:3]),
+		GroupIndex:     encoded[3],
+		GroupThreshold: encoded[4],
+		GroupCount:     encoded[5],
+		Index:          encoded[6],
+		Threshold:      encoded[7],
+		Value:          bytes.Clone(encoded[shareHeaderLength:end]),
+	}
+	if share.Index == 0 || share.Threshold == 0 || share.GroupThreshold == 0 ||
+		share.GroupThreshold > share.GroupCount || share.GroupIndex >= share.GroupCount {
+		return nil, fmt.Errorf("%w: invalid header", ErrInvalidShare)
+	}
+	return share, nil
+}
+
+// Mnemonic will encode the share as words of the BIP-39 English wordlist
+// (11 bits per word, the last word padded with zero bits)
+func (s *Share) Mnemonic() string {
+	encoded := s.Bytes()
+	bits := len(encoded) * 8
+	wordCount := (bits + 10) / 11
+	value := new(big.Int).SetBytes(encoded)
+	value.Lsh(value, uint(wordCount*11-bits)) // #nosec G115 -- padding < 11
+
+	words := make([]string, wordCount)
+	mask := big.NewInt(2047)
+	for i := wordCount - 1; i >= 0; i-- {
+		words[i] = wordlists.English[new(big.Int).And(value, mask).Int64()]
+		value.Rsh(value, 11)
+	}
+	return strings.Join(words, " ")
+}
+
+// ShareFromMnemonic will decode a share encoded with Share.Mnemonic
+func ShareFromMnemonic(mnemonic string) (*Share, error) {
+	words := strings.Fields(mnemonic)
+	bits := len(words) * 11
+	if bits < (shareHeaderLength+1+shareChecksumLength)*8 {
+		return nil, fmt.Errorf("%w: too short", ErrInvalidShare)
+	}
+
+	value := new(big.Int)
+	for _, word := range words {
+		index, ok := englishWordIndex(word)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown word %q", ErrInvalidShare, word)
+		}
+		value.Lsh(value, 11)
+		value.Or(value, big.NewInt(int64(index)))
+	}
+
+	// The share length is in the header, the remaining bits are padding
+	header := new(big.Int).Rsh(value, uint(bits-shareHeaderLength*8)) // #nosec G115 -- bits > header
+	length := shareHeaderLength + int(header.FillBytes(make([]byte, shareHeaderLength))[8]) + shareChecksumLength
+	if (length*8+10)/11 != len(words) {
+		return nil, fmt.Errorf("%w: length does not match the header", ErrInvalidShare)
+	}
+	value.Rsh(value, uint(bits-length*8)) // #nosec G115 -- padding < 11
+	return ShareFromBytes(value.FillBytes(make([]byte, length)))
+}
+
+// englishWordIndex returns the index of a word in the BIP-39 English wordlist.
+func englishWordIndex(word string) (int, bool) {
+	for i, w := range wordlists.English {
+		if w == word {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// splitBytes splits every byte of the secret with a random polynomial of
+// degree threshold-1 over GF(256), evaluated at x = 1..count.
+func splitBytes(secret []byte, threshold, count uint8) ([][]byte, error) {
+	coefficients := make([]byte, int(threshold)-1)
+	values := make([][]byte, count)
+	for i := range values {
+		values[i] = make([]byte, len(secret))
+	}
+
+	for b, secretByte := range secret {
+		if _, err := rand.Read(coefficients); err != nil {
+			return nil, err
+		}
+		for i := range values {
+			// Horner's method: ((c_k x + c_k-1) x + ...) x + secret
+			x := byte(i + 1) // #nosec G115 -- at most 255 shares
+			var y byte
+			for c := len(coefficients) - 1; c >= 0; c-- {
+				y = gfMul(y, x) ^ coefficients[c]
+			}
+			values[i][b] = gfMul(y, x) ^ secretByte
+		}
+	}
+	return values, nil
+}
+
+// combineBytes interpolates threshold of the points (x -> value) at x = 0.
+func combineBytes(points map[uint8][]byte, threshold uint8) []byte {
+	xs := make([]byte, 0, threshold)
+	for x := range points {
+		if len(xs) == int(threshold) {
+			break
+		}
+		xs = append(xs, x)
+	}
+
+	var secret []byte
+	for _, xi := range xs {
+		// Lagrange basis polynomial of xi at 0: prod(xj / (xj - xi)), where - is xor
+		basis := byte(1)
+		for _, xj := range xs {
+			if xj != xi {
+				basis = gfMul(basis, gfMul(xj, gfInverse(xj^xi)))
+			}
+		}
+		if secret == nil {
+			secret = make([]byte, len(points[xi]))
+		}
+		for b, y := range points[xi] {
+			secret[b] ^= gfMul(y, basis)
+		}
+	}
+	return secret
+}
+
+// gfMul multiplies in GF(256) with the AES polynomial x^8 + x^4 + x^3 + x + 1,
+// without data dependent branches.
+func gfMul(a, b byte) byte {
+	var product byte
+	for range 8 {
+		product ^= -(b & 1) & a
+		a = (a << 1) ^ (-(a >> 7) & 0x1b)
+		b >>= 1
+	}
+	return product
+}
+
+// gfInverse returns the multiplicative inverse in GF(256) (a^254).
+func gfInverse(a byte) byte {
+	result := byte(1)
+	for range 7 {
+		a = gfMul(a, a)
+		result = gfMul(result, a)
+	}
+	return result
+}
@@ -0,0 +1,319 @@
+package bitcoin
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGF256 will test the field arithmetic used by the shares
+func TestGF256(t *testing.T) {
+	t.Parallel()
+
+	// Known products with the AES polynomial
+	assert.Equal(t, byte(0xc1), gfMul(0x57, 0x83))
+	assert.Equal(t, byte(0xfe), gfMul(0x57, 0x13))
+	assert.Equal(t, byte(0), gfMul(0, 0x13))
+
+	for a := 1; a < 256; a++ {
+		assert.Equal(t, byte(1), gfMul(byte(a), gfInverse(byte(a))), "inverse of %d", a)
+	}
+}
+
+// TestSplitSecret will test the methods SplitSecret() and CombineShares()
+func TestSplitSecret(t *testing.T) {
+	t.Parallel()
+
+	secret := []byte("the quick brown fox jumps over the lazy dog")
+
+	tests := []struct {
+		name      string
+		threshold uint8
+		count     uint8
+	}{
+		{"1 of 1", 1, 1},
+		{"1 of 3", 1, 3},
+		{"2 of 3", 2, 3},
+		{"3 of 5", 3, 5},
+		{"5 of 5", 5, 5},
+		{"10 of 255", 10, 255},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			shares, err := SplitSecret(secret, test.threshold, test.count)
+			require.NoError(t, err)
+			require.Len(t, shares, int(test.count))
+
+			// Any threshold shares recover the secret, in any order
+			recovered, err := CombineShares(shares[:test.threshold])
+			require.NoError(t, err)
+			assert.Equal(t, secret, recovered)
+
+			recovered, err = CombineShares(shares[len(shares)-int(test.threshold):])
+			require.NoError(t, err)
+			assert.Equal(t, secret, recovered)
+
+			// All shares (more than the threshold) also work
+			recovered, err = CombineShares(shares)
+			require.NoError(t, err)
+			assert.Equal(t, secret, recovered)
+
+			// Fewer shares do not
+			if test.threshold > 1 {
+				_, err = CombineShares(shares[:test.threshold-1])
+				require.ErrorIs(t, err, ErrInsufficientShares)
+			}
+		})
+	}
+}
+
+// TestSplitSecretGroups will test the method SplitSecretGroups()
+func TestSplitSecretGroups(t *testing.T) {
+	t.Parallel()
+
+	seed := bytes.Repeat([]byte{0x42}, 64)
+	groups, err := SplitSecretGroups(seed, 2, []ShareGroup{{3, 5}, {2, 3}, {1, 1}})
+	require.NoError(t, err)
+	require.Len(t, groups, 3)
+	assert.Len(t, groups[0], 5)
+	assert.Len(t, groups[1], 3)
+	assert.Len(t, groups[2], 1)
+
+	tests := []struct {
+		name     string
+		shares   []*Share
+		expected error
+	}{
+		{"groups 0 and 1", []*Share{groups[0][4], groups[1][0], groups[0][1], groups[1][2], groups[0][2]}, nil},
+		{"groups 1 and 2", []*Share{groups[1][1], groups[2][0], groups[1][2]}, nil},
+		{"all groups, one incomplete", []*Share{groups[0][0], groups[1][1], groups[2][0], groups[1][2]}, nil},
+		{"only one complete group", []*Share{groups[0][0], groups[0][1], groups[0][2], groups[1][0]}, ErrInsufficientShares},
+		{"one group", groups[0], ErrInsufficientShares},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			recovered, combineErr := CombineShares(test.shares)
+			if test.expected != nil {
+				require.ErrorIs(t, combineErr, test.expected)
+				return
+			}
+			require.NoError(t, combineErr)
+			assert.Equal(t, seed, recovered)
+		})
+	}
+}
+
+// TestSplitSecretErrors will test the error cases of splitting and combining
+func TestSplitSecretErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid split", func(t *testing.T) {
+		t.Parallel()
+		_, err := SplitSecret(nil, 2, 3)
+		require.ErrorIs(t, err, ErrInvalidSecretLength)
+		_, err = SplitSecret(make([]byte, 252), 2, 3)
+		require.ErrorIs(t, err, ErrInvalidSecretLength)
+		_, err = SplitSecret([]byte("secret"), 0, 3)
+		require.ErrorIs(t, err, ErrInvalidThreshold)
+		_, err = SplitSecret([]byte("secret"), 4, 3)
+		require.ErrorIs(t, err, ErrInvalidThreshold)
+		_, err = SplitSecretGroups([]byte("secret"), 2, []ShareGroup{{1, 1}})
+		require.ErrorIs(t, err, ErrInvalidThreshold)
+		_, err = SplitSecretGroups([]byte("secret"), 1, make([]ShareGroup, 17))
+		require.ErrorIs(t, err, ErrInvalidThreshold)
+	})
+
+	t.Run("invalid combine", func(t *testing.T) {
+		t.Parallel()
+		shares, err := SplitSecret([]byte("secret"), 2, 3)
+		require.NoError(t, err)
+		other, err := SplitSecret([]byte("secret"), 2, 3)
+		require.NoError(t, err)
+		other[1].ID = shares[0].ID + 1
+
+		_, err = CombineShares(nil)
+		require.ErrorIs(t, err, ErrInsufficientShares)
+		_, err = CombineShares([]*Share{shares[0], other[1]})
+		require.ErrorIs(t, err, ErrShareMismatch)
+		_, err = CombineShares([]*Share{{Value: []byte{1}}})
+		require.ErrorIs(t, err, ErrInvalidShare)
+
+		// A duplicate share is ignored, a conflicting one is rejected
+		_, err = CombineShares([]*Share{shares[0], shares[0]})
+		require.ErrorIs(t, err, ErrInsufficientShares)
+		conflict := *shares[0]
+		conflict.Value = bytes.Repeat([]byte{1}, len(conflict.Value))
+		_, err = CombineShares([]*Share{shares[0], &conflict})
+		require.ErrorIs(t, err, ErrShareMismatch)
+		conflict = *shares[1]
+		conflict.Threshold = 3
+		_, err = CombineShares([]*Share{shares[0], &conflict})
+		require.ErrorIs(t, err, ErrShareMismatch)
+
+		// A corrupted share is detected by the secret checksum
+		corrupted := *shares[1]
+		corrupted.Value = bytes.Clone(corrupted.Value)
+		corrupted.Value[0] ^= 1
+		_, err = CombineShares([]*Share{shares[0], &corrupted})
+		require.ErrorIs(t, err, ErrChecksumMismatch)
+	})
+}
+
+// TestSplitPrivateKey will test the methods SplitPrivateKey() and CombinePrivateKey()
+func TestSplitPrivateKey(t *testing.T) {
+	t.Parallel()
+
+	shares, err := SplitPrivateKey(testPrivateKeyHex, 2, 3)
+	require.NoError(t, err)
+	privateKey, err := CombinePrivateKey([]*Share{shares[2], shares[0]})
+	require.NoError(t, err)
+	assert.Equal(t, testPrivateKeyHex, privateKey)
+
+	_, err = SplitPrivateKey("invalid", 2, 3)
+	require.Error(t, err)
+	_, err = CombinePrivateKey(shares[:1])
+	require.ErrorIs(t, err, ErrInsufficientShares)
+
+	// A secret that is not a private key
+	notKey, err := SplitSecret([]byte("not a key"), 1, 1)
+	require.NoError(t, err)
+	_, err = CombinePrivateKey(notKey)
+	require.ErrorIs(t, err, ErrMalformedPrivateKey)
+}
+
+// TestSplitWIF will test the methods SplitWIF() and CombineWIF()
+func TestSplitWIF(t *testing.T) {
+	t.Parallel()
+
+	compressed, err := PrivateKeyToWifString(testPrivateKeyHex)
+	require.NoError(t, err)
+	uncompressed, err := PrivateKeyToWifStringWithCompression(testPrivateKeyHex, false)
+	require.NoError(t, err)
+
+	for _, wif := range []string{compressed, uncompressed, testWIF} {
+		shares, splitErr := SplitWIF(wif, 3, 5)
+		require.NoError(t, splitErr)
+		recovered, combineErr := CombineWIF(shares[1:4])
+		require.NoError(t, combineErr)
+		assert.Equal(t, wif, recovered)
+	}
+
+	_, err = SplitWIF("invalid", 2, 3)
+	require.Error(t, err)
+	notWIF, err := SplitSecret([]byte("not a wif"), 1, 1)
+	require.NoError(t, err)
+	_, err = CombineWIF(notWIF)
+	require.Error(t, err)
+	_, err = CombineWIF(nil)
+	require.ErrorIs(t, err, ErrInsufficientShares)
+}
+
+// TestShareEncoding will test the methods Share.Bytes(), ShareFromBytes(),
+// Share.Mnemonic() and ShareFromMnemonic()
+func TestShareEncoding(t *testing.T) {
+	t.Parallel()
+
+	// Secrets of every length modulo 11 bits exercise the mnemonic padding
+	for length := 1; length <= 33; length++ {
+		shares, err := SplitSecret(bytes.Repeat([]byte{byte(length)}, length), 2, 2)
+		require.NoError(t, err)
+		for _, share := range shares {
+			decoded, decodeErr := ShareFromBytes(share.Bytes())
+			require.NoError(t, decodeErr)
+			assert.Equal(t, share, decoded)
+
+			decoded, decodeErr = ShareFromMnemonic(share.Mnemonic())
+			require.NoError(t, decodeErr)
+			assert.Equal(t, share, decoded)
+		}
+	}
+
+	share := &Share{ID: 7, GroupThreshold: 1, GroupCount: 1, Index: 1, Threshold: 1, Value: []byte("value")}
+	encoded := share.Bytes()
+
+	t.Run("invalid bytes", func(t *testing.T) {
+		t.Parallel()
+		_, err := ShareFromBytes(encoded[:10])
+		require.ErrorIs(t, err, ErrInvalidShare)
+
+		wrongVersion := bytes.Clone(encoded)
+		wrongVersion[0] = 2
+		_, err = ShareFromBytes(wrongVersion)
+		require.ErrorIs(t, err, ErrInvalidShare)
+
+		_, err = ShareFromBytes(append(bytes.Clone(encoded), 0))
+		require.ErrorIs(t, err, ErrInvalidShare)
+
+		typo := bytes.Clone(encoded)
+		typo[10] ^= 1
+		_, err = ShareFromBytes(typo)
+		require.ErrorIs(t, err, ErrChecksumMismatch)
+
+		invalidHeader := *share
+		invalidHeader.Index = 0
+		_, err = ShareFromBytes(invalidHeader.Bytes())
+		require.ErrorIs(t, err, ErrInvalidShare)
+	})
+
+	t.Run("invalid mnemonic", func(t *testing.T) {
+		t.Parallel()
+		words := strings.Fields(share.Mnemonic())
+		words = words[:len(words):len(words)]
+
+		_, err := ShareFromMnemonic(strings.Join(words[:5], " "))
+		require.ErrorIs(t, err, ErrInvalidShare)
+		_, err = ShareFromMnemonic(strings.Join(append([]string{"notaword"}, words[1:]...), " "))
+		require.ErrorIs(t, err, ErrInvalidShare)
+		_, err = ShareFromMnemonic(strings.Join(append(words, "abandon"), " "))
+		require.ErrorIs(t, err, ErrInvalidShare)
+
+		// A swapped word is detected by the share checksum
+		swapped := append([]string{}, words...)
+		swapped[len(swapped)/2] = "zoo"
+		_, err = ShareFromMnemonic(strings.Join(swapped, " "))
+		require.Error(t, err)
+	})
+}
+
+// ExampleSplitSecret example using SplitSecret() and CombineShares()
+func ExampleSplitSecret() {
+	seed := []byte("an HD seed of 16 to 64 bytes....")
+	shares, err := SplitSecret(seed, 2, 3)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	// Any two of the three shares recover the seed
+	var recovered []byte
+	if recovered, err = CombineShares([]*Share{shares[2], shares[0]}); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("recovered: %s", recovered)
+	// Output:recovered: an HD seed of 16 to 64 bytes....
+}
+
+// BenchmarkSplitSecret benchmarks the method SplitSecret() with a 64 byte seed
+func BenchmarkSplitSecret(b *testing.B) {
+	seed := bytes.Repeat([]byte{0x42}, 64)
+	for b.Loop() {
+		_, _ = SplitSecret(seed, 3, 5)
+	}
+}
+
+// BenchmarkCombineShares benchmarks the method CombineShares() with a 64 byte seed
+func BenchmarkCombineShares(b *testing.B) {
+	shares, _ := SplitSecret(bytes.Repeat([]byte{0x42}, 64), 3, 5)
+	for b.Loop() {
+		_, _ = CombineShares(shares[:3])
+	}
+}
@@ -0,0 +1,332 @@
+package bitcoin
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+)
+
+// This file adds streaming encryption for payloads that are too large to hold
+// in memory (files stored on chain via B://, backups, ...). A random ephemeral
+// key is agreed with the recipient public key exactly like eciesEncrypt, and
+// the payload is then split into fixed size chunks that are each sealed with
+// AES-256-GCM. The structure it encodes everything into is:
+//
+//	struct {
+//		Magic [4]byte               // "BST1"
+//		PublicKey [33]byte          // compressed ephemeral key
+//		ChunkSize [4]byte           // plaintext bytes per chunk (big endian)
+//		Chunks []struct {
+//			Data []byte             // cipher text (ChunkSize bytes, last may be shorter)
+//			Tag [16]byte            // GCM tag
+//		}
+//	}
+//
+// The GCM nonce of every chunk is its index plus a final-chunk flag, and the
+// header is the additional data of every chunk, so reordered, dropped,
+// duplicated or truncated chunks (and a tampered header) fail to authenticate.
+// The reader never releases plaintext from a chunk that did not authenticate.
+
+var (
+	// ErrStreamTampered is returned when a chunk (or the header) fails to
+	// authenticate, caused by an invalid private key, corrupt or reordered data
+	ErrStreamTampered = errors.New("encrypted stream failed authentication")
+
+	// ErrStreamTruncated is returned when the encrypted stream ends before its
+	// final chunk
+	ErrStreamTruncated = errors.New("encrypted stream is truncated")
+
+	// ErrStreamClosed is returned when writing to a closed stream
+	ErrStreamClosed = errors.New("encrypted stream is closed")
+
+	// ErrInvalidChunkSize is returned when the chunk size is out of range
+	ErrInvalidChunkSize = errors.New("invalid stream chunk size")
+
+	errInvalidStreamMagic = errors.New("invalid stream magic bytes")
+)
+
+const (
+	// DefaultStreamChunkSize is the default number of plaintext bytes per chunk
+	DefaultStreamChunkSize = 64 * 1024 // 64 KiB
+
+	// MaxStreamChunkSize is the largest accepted chunk size (bounds memory use)
+	MaxStreamChunkSize = 16 * 1024 * 1024 // 16 MiB
+
+	// streamHeaderLength is magic(4) + compressed key(33) + chunk size(4)
+	streamHeaderLength = 4 + 33 + 4
+
+	// streamTagLength is the length of the GCM tag appended to every chunk
+	streamTagLength = 16
+)
+
+// streamMagic is the streaming format magic prefix.
+//
+//nolint:gochecknoglobals // fixed stream wire-format marker; byte arrays cannot be const
+var streamMagic = [4]byte{'B', 'S', 'T', '1'}
+
+// newStreamCipher creates the AES-256-GCM cipher for a stream from the ECDH
+// shared secret, using the same key derivation as eciesEncrypt.
+func newStreamCipher(privKey *ec.PrivateKey, pubKey *ec.PublicKey) (cipher.AEAD, error) {
+	keyE, _ := deriveKeys(generateSharedSecret(privKey, pubKey))
+	block, err := aes.NewCipher(keyE)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// streamNonce returns the GCM nonce for a chunk: 3 zero bytes, the chunk index
+// (big endian) and the final-chunk flag.
+func streamNonce(index uint64, final bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], index)
+	if final {
+		nonce[11] = 0x01
+	}
+	return nonce
+}
+
+// EncryptWriter encrypts everything written to it for a recipient public key
+// and writes the encrypted stream to the underlying writer
+//
+// Close must be called to write the final chunk; it does not close the
+// underlying writer
+type EncryptWriter struct {
+	w         io.Writer
+	aead      cipher.AEAD
+	header    []byte
+	buf       []byte
+	chunkSize int
+	index     uint64
+	closed    bool
+}
+
+// NewEncryptWriter will create an EncryptWriter for the public key using the
+// DefaultStreamChunkSize, and write the stream header to w
+func NewEncryptWriter(w io.Writer, pubKey *ec.PublicKey) (*EncryptWriter, error) {
+	return NewEncryptWriterWithChunkSize(w, pubKey, DefaultStreamChunkSize)
+}
+
+// NewEncryptWriterWithChunkSize will create an EncryptWriter for the public key
+// using the given chunk size, and write the stream header to w
+func NewEncryptWriterWithChunkSize(w io.Writer, pubKey *ec.PublicKey, chunkSize int) (*EncryptWriter, error) {
+	if pubKey == nil {
+		return nil, ErrPublicKeyNil
+	}
+	if chunkSize <= 0 || chunkSize > MaxStreamChunkSize {
+		return nil, ErrInvalidChunkSize
+	}
+
+	// Agree a key using a random ephemeral key
+	ephemeral, err := ec.NewPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	var aead cipher.AEAD
+	if aead, err = newStreamCipher(ephemeral, pubKey); err != nil {
+		return nil, err
+	}
+
+	// Write the header
+	header := make([]byte, 0, streamHeaderLength)
+	header = append(header, streamMagic[:]...)
+	header = append(header, ephemeral.PubKey().Compressed()...)
+	header = binary.BigEndian.AppendUint32(header, uint32(chunkSize)) // #nosec G115 -- bounded by MaxStreamChunkSize
+	if _, err = w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &EncryptWriter{
+		w:         w,
+		aead:      aead,
+		header:    header,
+		buf:       make([]byte, 0, chunkSize),
+		chunkSize: chunkSize,
+	}, nil
+}
+
+// Write encrypts p, writing every completed chunk to the underlying writer
+func (e *EncryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, ErrStreamClosed
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// A full buffer is only sealed once more data arrives, so that the
+		// final chunk is always written by Close
+		if len(e.buf) == e.chunkSize {
+			if err := e.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := min(e.chunkSize-len(e.buf), len(p))
+		e.buf = append(e.buf, p[:n]...)
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close writes the final chunk; it does not close the underlying writer
+func (e *EncryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.seal(true)
+}
+
+// seal encrypts and writes the buffered chunk.
+func (e *EncryptWriter) seal(final bool) error {
+	sealed := e.aead.Seal(nil, streamNonce(e.index, final), e.buf, e.header)
+	e.index++
+	e.buf = e.buf[:0]
+	_, err := e.w.Write(sealed)
+	return err
+}
+
+// DecryptReader decrypts an encrypted stream produced by EncryptWriter
+//
+// Every chunk is authenticated before any of its plaintext is returned, so a
+// tampered, reordered or truncated stream fails with ErrStreamTampered or
+// ErrStreamTruncated as soon as the bad chunk is reached
+type DecryptReader struct {
+	r      *bufio.Reader
+	aead   cipher.AEAD
+	header []byte
+	chunk  []byte
+	out    []byte
+	plain  []byte
+	index  uint64
+	err    error
+}
+
+// NewDecryptReader will read the stream header from r and create a
+// DecryptReader using the recipient private key
+func NewDecryptReader(r io.Reader, privateKey *ec.PrivateKey) (*DecryptReader, error) {
+	if privateKey == nil {
+		return nil, ErrPrivateKeyMissing
+	}
+
+	// Read and check the header
+	header := make([]byte, streamHeaderLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, errInputTooShort
+		}
+		return nil, err
+	}
+	if !bytes.Equal(header[:4], streamMagic[:]) {
+		return nil, errInvalidStreamMagic
+	}
+	pubKey, err := ec.ParsePubKey(header[4:37])
+	if err != nil {
+		return nil, err
+	}
+	chunkSize := int(binary.BigEndian.Uint32(header[37:]))
+	if chunkSize <= 0 || chunkSize > MaxStreamChunkSize {
+		return nil, ErrInvalidChunkSize
+	}
+
+	var aead cipher.AEAD
+	if aead, err = newStreamCipher(privateKey, pubKey); err != nil {
+		return nil, err
+	}
+
+	return &DecryptReader{
+		r:      bufio.NewReader(r),
+		aead:   aead,
+		header: header,
+		chunk:  make([]byte, chunkSize+streamTagLength),
+		out:    make([]byte, 0, chunkSize),
+	}, nil
+}
+
+// Read reads decrypted data from the stream
+func (d *DecryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.err = d.open()
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+// open reads, authenticates and decrypts the next chunk into d.plain, returning
+// io.EOF after the final chunk.
+func (d *DecryptReader) open() error {
+	n, err := io.ReadFull(d.r, d.chunk)
+	var final bool
+	switch {
+	case err == nil:
+		// A full chunk is the final one only if nothing follows it
+		if _, err = d.r.Peek(1); errors.Is(err, io.EOF) {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	case errors.Is(err, io.ErrUnexpectedEOF) && n >= streamTagLength:
+		final = true
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrStreamTruncated
+	default:
+		return err
+	}
+
+	plain, err := d.aead.Open(d.out[:0], streamNonce(d.index, final), d.chunk[:n], d.header)
+	if err != nil {
+		// A valid non-final chunk at the end of the input means chunks are missing
+		if final {
+			if _, err = d.aead.Open(nil, streamNonce(d.index, false), d.chunk[:n], d.header); err == nil {
+				return ErrStreamTruncated
+			}
+		}
+		return ErrStreamTampered
+	}
+	d.index++
+	d.plain = plain
+	if final {
+		// Any remaining plaintext is returned before io.EOF
+		return io.EOF
+	}
+	return nil
+}
+
+// EncryptStream will encrypt everything read from src for the public key and
+// write the encrypted stream to dst, returning the number of plaintext bytes
+func EncryptStream(dst io.Writer, src io.Reader, pubKey *ec.PublicKey) (int64, error) {
+	encrypter, err := NewEncryptWriter(dst, pubKey)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	if n, err = io.Copy(encrypter, src); err != nil {
+		return n, err
+	}
+	return n, encrypter.Close()
+}
+
+// DecryptStream will decrypt the encrypted stream read from src using the
+// private key and write the plaintext to dst, returning the number of
+// plaintext bytes
+//
+// Plaintext written before an error is returned came from authenticated
+// chunks, but the stream as a whole must be discarded on error
+func DecryptStream(dst io.Writer, src io.Reader, privateKey *ec.PrivateKey) (int64, error) {
+	decrypter, err := NewDecryptReader(src, privateKey)
+	if err != nil {
+		return 0, err
+	}
+	return io.Copy(dst, decrypter)
+}
@@ -0,0 +1,305 @@
+package bitcoin
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStreamChunkSize is a small chunk size so tests cover many chunks
+const testStreamChunkSize = 16
+
+// encryptTestStream encrypts data to the test key using testStreamChunkSize,
+// failing the test on error
+func encryptTestStream(t *testing.T, data []byte) []byte {
+	t.Helper()
+	privateKey := mustTestPrivKey(t)
+
+	var out bytes.Buffer
+	encrypter, err := NewEncryptWriterWithChunkSize(&out, privateKey.PubKey(), testStreamChunkSize)
+	require.NoError(t, err)
+
+	_, err = encrypter.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, encrypter.Close())
+	return out.Bytes()
+}
+
+// TestEncryptStream will test the methods EncryptStream() and DecryptStream()
+func TestEncryptStream(t *testing.T) {
+	t.Parallel()
+
+	privateKey := mustTestPrivKey(t)
+
+	for _, size := range []int{0, 1, 1000, DefaultStreamChunkSize - 1, DefaultStreamChunkSize, 3*DefaultStreamChunkSize + 7} {
+		t.Run(fmt.Sprintf("%d bytes", size), func(t *testing.T) {
+			t.Parallel()
+			data := make([]byte, size)
+			_, err := rand.Read(data)
+			require.NoError(t, err)
+
+			var encrypted bytes.Buffer
+			n, err := EncryptStream(&encrypted, bytes.NewReader(data), privateKey.PubKey())
+			require.NoError(t, err)
+			assert.Equal(t, int64(size), n)
+
+			var decrypted bytes.Buffer
+			n, err = DecryptStream(&decrypted, &encrypted, privateKey)
+			require.NoError(t, err)
+			assert.Equal(t, int64(size), n)
+			assert.True(t, bytes.Equal(data, decrypted.Bytes()))
+		})
+	}
+}
+
+// TestEncryptWriter will test the EncryptWriter and DecryptReader chunking
+func TestEncryptWriter(t *testing.T) {
+	t.Parallel()
+
+	privateKey := mustTestPrivKey(t)
+
+	t.Run("chunk boundaries", func(t *testing.T) {
+		t.Parallel()
+		for size := 0; size <= 3*testStreamChunkSize+1; size++ {
+			data := bytes.Repeat([]byte{0x42}, size)
+			encrypted := encryptTestStream(t, data)
+
+			// Every chunk carries a tag, and there is always a final chunk
+			chunks := size/testStreamChunkSize + 1
+			if size > 0 && size%testStreamChunkSize == 0 {
+				chunks--
+			}
+			assert.Len(t, encrypted, streamHeaderLength+size+chunks*streamTagLength)
+
+			reader, err := NewDecryptReader(bytes.NewReader(encrypted), privateKey)
+			require.NoError(t, err)
+			decrypted, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.True(t, bytes.Equal(data, decrypted))
+		}
+	})
+
+	t.Run("many small writes", func(t *testing.T) {
+		t.Parallel()
+		var out bytes.Buffer
+		encrypter, err := NewEncryptWriterWithChunkSize(&out, privateKey.PubKey(), testStreamChunkSize)
+		require.NoError(t, err)
+
+		var expected bytes.Buffer
+		for i := range 100 {
+			chunk := []byte(fmt.Sprintf("line %d\n", i))
+			expected.Write(chunk)
+			n, writeErr := encrypter.Write(chunk)
+			require.NoError(t, writeErr)
+			assert.Equal(t, len(chunk), n)
+		}
+		require.NoError(t, encrypter.Close())
+		require.NoError(t, encrypter.Close())
+
+		_, err = encrypter.Write([]byte("too late"))
+		require.ErrorIs(t, err, ErrStreamClosed)
+
+		var decrypted bytes.Buffer
+		_, err = DecryptStream(&decrypted, &out, privateKey)
+		require.NoError(t, err)
+		assert.Equal(t, expected.String(), decrypted.String())
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewEncryptWriter(io.Discard, nil)
+		require.ErrorIs(t, err, ErrPublicKeyNil)
+		_, err = NewEncryptWriterWithChunkSize(io.Discard, privateKey.PubKey(), 0)
+		require.ErrorIs(t, err, ErrInvalidChunkSize)
+		_, err = NewEncryptWriterWithChunkSize(io.Discard, privateKey.PubKey(), MaxStreamChunkSize+1)
+		require.ErrorIs(t, err, ErrInvalidChunkSize)
+		_, err = NewDecryptReader(bytes.NewReader(nil), nil)
+		require.ErrorIs(t, err, ErrPrivateKeyMissing)
+		_, err = DecryptStream(io.Discard, bytes.NewReader(nil), nil)
+		require.ErrorIs(t, err, ErrPrivateKeyMissing)
+		_, err = EncryptStream(io.Discard, bytes.NewReader(nil), nil)
+		require.ErrorIs(t, err, ErrPublicKeyNil)
+	})
+}
+
+// TestDecryptReaderTampering will test that DecryptReader detects tampering,
+// truncation and reordering
+func TestDecryptReaderTampering(t *testing.T) {
+	t.Parallel()
+
+	privateKey := mustTestPrivKey(t)
+	data := bytes.Repeat([]byte("0123456789abcdef"), 4) // 4 full chunks
+	encrypted := encryptTestStream(t, data)
+	sealedChunk := testStreamChunkSize + streamTagLength
+
+	// chunk returns the n-th sealed chunk
+	chunk := func(n int) []byte {
+		start := streamHeaderLength + n*sealedChunk
+		return encrypted[start : start+sealedChunk]
+	}
+
+	tests := []struct {
+		name     string
+		input    func() []byte
+		expected error
+	}{
+		{"flipped cipher text bit", func() []byte {
+			tampered := bytes.Clone(encrypted)
+			tampered[streamHeaderLength+sealedChunk+3] ^= 0x01
+			return tampered
+		}, ErrStreamTampered},
+		{"flipped tag bit", func() []byte {
+			tampered := bytes.Clone(encrypted)
+			tampered[len(tampered)-1] ^= 0x01
+			return tampered
+		}, ErrStreamTampered},
+		{"tampered chunk size", func() []byte {
+			tampered := bytes.Clone(encrypted)
+			tampered[streamHeaderLength-1] = testStreamChunkSize + 1
+			return tampered
+		}, ErrStreamTampered},
+		{"truncated at chunk boundary", func() []byte {
+			return encrypted[:streamHeaderLength+2*sealedChunk]
+		}, ErrStreamTruncated},
+		{"truncated mid chunk", func() []byte {
+			return encrypted[:streamHeaderLength+2*sealedChunk+streamTagLength+4]
+		}, ErrStreamTampered},
+		{"truncated before a full tag", func() []byte {
+			return encrypted[:streamHeaderLength+2*sealedChunk+streamTagLength-1]
+		}, ErrStreamTruncated},
+		{"header only", func() []byte {
+			return encrypted[:streamHeaderLength]
+		}, ErrStreamTruncated},
+		{"reordered chunks", func() []byte {
+			reordered := bytes.Clone(encrypted[:streamHeaderLength])
+			for _, n := range []int{1, 0, 2, 3} {
+				reordered = append(reordered, chunk(n)...)
+			}
+			return reordered
+		}, ErrStreamTampered},
+		{"duplicated chunk", func() []byte {
+			duplicated := bytes.Clone(encrypted[:streamHeaderLength+sealedChunk])
+			return append(duplicated, encrypted[streamHeaderLength:]...)
+		}, ErrStreamTampered},
+		{"dropped middle chunk", func() []byte {
+			dropped := bytes.Clone(encrypted[:streamHeaderLength+sealedChunk])
+			return append(dropped, encrypted[streamHeaderLength+2*sealedChunk:]...)
+		}, ErrStreamTampered},
+		{"appended data", func() []byte {
+			return append(bytes.Clone(encrypted), 0x00)
+		}, ErrStreamTampered},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var decrypted bytes.Buffer
+			_, err := DecryptStream(&decrypted, bytes.NewReader(test.input()), privateKey)
+			require.ErrorIs(t, err, test.expected)
+
+			// Only authenticated chunks were released, all of them in order
+			assert.True(t, bytes.HasPrefix(data, decrypted.Bytes()))
+		})
+	}
+
+	t.Run("wrong key", func(t *testing.T) {
+		t.Parallel()
+		otherKey, err := CreatePrivateKey()
+		require.NoError(t, err)
+		var decrypted bytes.Buffer
+		_, err = DecryptStream(&decrypted, bytes.NewReader(encrypted), otherKey)
+		require.ErrorIs(t, err, ErrStreamTampered)
+		assert.Equal(t, 0, decrypted.Len())
+	})
+
+	t.Run("fails fast", func(t *testing.T) {
+		t.Parallel()
+		tampered := bytes.Clone(encrypted)
+		tampered[streamHeaderLength+2*sealedChunk] ^= 0x01
+
+		reader, err := NewDecryptReader(bytes.NewReader(tampered), privateKey)
+		require.NoError(t, err)
+		buf := make([]byte, testStreamChunkSize)
+		for range 2 {
+			_, err = io.ReadFull(reader, buf)
+			require.NoError(t, err)
+		}
+		_, err = reader.Read(buf)
+		require.ErrorIs(t, err, ErrStreamTampered)
+		_, err = reader.Read(buf)
+		require.ErrorIs(t, err, ErrStreamTampered)
+	})
+
+	t.Run("invalid header", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewDecryptReader(bytes.NewReader(encrypted[:streamHeaderLength-1]), privateKey)
+		require.ErrorIs(t, err, errInputTooShort)
+
+		badMagic := bytes.Clone(encrypted)
+		badMagic[0] = 'X'
+		_, err = NewDecryptReader(bytes.NewReader(badMagic), privateKey)
+		require.ErrorIs(t, err, errInvalidStreamMagic)
+
+		badKey := bytes.Clone(encrypted)
+		badKey[4] = 0x05
+		_, err = NewDecryptReader(bytes.NewReader(badKey), privateKey)
+		require.Error(t, err)
+
+		badChunkSize := bytes.Clone(encrypted)
+		copy(badChunkSize[37:41], []byte{0xff, 0xff, 0xff, 0xff})
+		_, err = NewDecryptReader(bytes.NewReader(badChunkSize), privateKey)
+		require.ErrorIs(t, err, ErrInvalidChunkSize)
+	})
+}
+
+// ExampleEncryptStream example using EncryptStream()
+func ExampleEncryptStream() {
+	privateKey, err := PrivateKeyFromString(testPrivateKeyHex)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	// Encrypt a reader (a file, a network stream, ...) into a writer
+	var encrypted bytes.Buffer
+	if _, err = EncryptStream(&encrypted, bytes.NewReader([]byte("a very large file")), privateKey.PubKey()); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	// Decrypt it again
+	var decrypted bytes.Buffer
+	if _, err = DecryptStream(&decrypted, &encrypted, privateKey); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("decrypted: %s", decrypted.String())
+	// Output:decrypted: a very large file
+}
+
+// BenchmarkEncryptStream benchmarks the method EncryptStream() with 1 MiB
+func BenchmarkEncryptStream(b *testing.B) {
+	key, _ := CreatePrivateKey()
+	data := make([]byte, 1024*1024)
+	b.SetBytes(int64(len(data)))
+	for b.Loop() {
+		_, _ = EncryptStream(io.Discard, bytes.NewReader(data), key.PubKey())
+	}
+}
+
+// BenchmarkDecryptStream benchmarks the method DecryptStream() with 1 MiB
+func BenchmarkDecryptStream(b *testing.B) {
+	key, _ := CreatePrivateKey()
+	data := make([]byte, 1024*1024)
+	var encrypted bytes.Buffer
+	_, _ = EncryptStream(&encrypted, bytes.NewReader(data), key.PubKey())
+	b.SetBytes(int64(len(data)))
+	for b.Loop() {
+		_, _ = DecryptStream(io.Discard, bytes.NewReader(encrypted.Bytes()), key)
+	}
+}
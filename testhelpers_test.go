@@ -5,6 +5,7 @@ import (
 
 	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
 	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
 	"github.com/stretchr/testify/require"
 )
 
@@ -46,3 +47,13 @@ func mustHDKey(t *testing.T) *bip32.ExtendedKey {
 	require.NoError(t, err)
 	return key
 }
+
+// mustTestnetHDKey returns a copy of the HD key with testnet (tprv/tpub) version
+// bytes, failing the test on error.
+func mustTestnetHDKey(t *testing.T, hdKey *bip32.ExtendedKey) *bip32.ExtendedKey {
+	t.Helper()
+	key, err := bip32.NewKeyFromString(hdKey.String())
+	require.NoError(t, err)
+	key.SetNet(&chaincfg.TestNet)
+	return key
+}
@@ -273,9 +273,7 @@ func CreateTx(utxos []*Utxo, addresses []*PayToAddress,
 
 	// Sign the transaction
 	if privateKey != nil {
-		myAccount := &account{PrivateKey: privateKey}
-		// todo: support context (ctx)
-		if err = tx.FillAllInputs(context.Background(), myAccount); err != nil {
+		if err = SignTx(tx, privateKey); err != nil {
 			return nil, err
 		}
 	}
@@ -284,6 +282,18 @@ func CreateTx(utxos []*Utxo, addresses []*PayToAddress,
 	return tx, nil
 }
 
+// SignTx will sign all inputs of a transaction (P2PKH) with the private key
+//
+// Use this after changing outputs of a tx created without a key, for example
+// after adding Sigma signatures (see SignSigma)
+func SignTx(tx *bt.Tx, privateKey *ec.PrivateKey) error {
+	if privateKey == nil {
+		return ErrPrivateKeyMissing
+	}
+	// todo: support context (ctx)
+	return tx.FillAllInputs(context.Background(), &account{PrivateKey: privateKey})
+}
+
 // CreateTxUsingWif will create a basic transaction and return the raw transaction (*transaction.Transaction)
 //
 // This will NOT create a "change" address (it's assumed you have already specified an address)
@@ -34,23 +34,30 @@ func PubKeyFromSignature(sig, data string) (pubKey *ec.PublicKey, wasCompressed
 // Error will occur if verify fails or verification is not successful (no bool)
 // Spec: https://docs.moneybutton.com/docs/bsv-message.html
 func VerifyMessage(address, sig, data string, mainnet bool) error {
+	_, _, err := pubKeyForAddress(address, sig, data, mainnet)
+	return err
+}
+
+// pubKeyForAddress reconstructs the public key from a Bitcoin Signed Message
+// signature and checks that it relates to the address provided
+func pubKeyForAddress(address, sig, data string, mainnet bool) (*ec.PublicKey, bool, error) {
 	// Reconstruct the pubkey
 	publicKey, wasCompressed, err := PubKeyFromSignature(sig, data)
 	if err != nil {
-		return err
+		return nil, false, err
 	}
 
 	// Get the address
 	var bscriptAddress *bscript.Address
 	if bscriptAddress, err = GetAddressFromPubKey(publicKey, wasCompressed, mainnet); err != nil {
-		return err
+		return nil, false, err
 	}
 
-	// Return nil if addresses match.
+	// Return the pubkey if addresses match.
 	if bscriptAddress.AddressString == address {
-		return nil
+		return publicKey, wasCompressed, nil
 	}
-	return fmt.Errorf(
+	return nil, false, fmt.Errorf(
 		"%w: expected %s (compressed: %t), found %s",
 		ErrAddressNotFound,
 		address,
@@ -155,6 +155,14 @@ func (w *WIF) String() string {
 	return base58.Encode(a)
 }
 
+// Bytes creates the Wallet Import Format encoding of a WIF structure as bytes,
+// without the string copy made by String. The caller should zero the result after use.
+func (w *WIF) Bytes() []byte {
+	privateKey := paddedAppend(privKeyBytesLen, make([]byte, 0, privKeyBytesLen), w.PrivKey.D.Bytes())
+	defer clear(privateKey)
+	return wifBytes(privateKey, w.netID, w.CompressPubKey)
+}
+
 // SerializePubKey serializes the associated public key of the imported or
 // exported private key in either a compressed or uncompressed format depending
 // on the value of w.CompressPubKey.
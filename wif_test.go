@@ -1,6 +1,7 @@
 package bitcoin
 
 import (
+	"bytes"
 	"encoding/hex"
 	"strings"
 	"testing"
@@ -317,3 +318,45 @@ func TestIsForNetNil(t *testing.T) {
 	assert.False(t, w.IsForNet(nil))
 	assert.True(t, w.IsForNet(&chaincfg.MainNet))
 }
+
+// TestWIFBytes confirms WIF.Bytes matches WIF.String for both compressions and networks
+func TestWIFBytes(t *testing.T) {
+	t.Parallel()
+
+	priv, err := PrivateKeyFromString(testPrivateKeyHex)
+	require.NoError(t, err)
+
+	for _, net := range []*chaincfg.Params{&chaincfg.MainNet, &chaincfg.TestNet} {
+		for _, compress := range []bool{false, true} {
+			w, wifErr := NewWIF(priv, net, compress)
+			require.NoError(t, wifErr)
+			assert.Equal(t, w.String(), string(w.Bytes()))
+		}
+	}
+}
+
+// TestBase58Bytes confirms the byte-only base58 helpers match the base58 package,
+// including leading zero bytes
+func TestBase58Bytes(t *testing.T) {
+	t.Parallel()
+
+	inputs := [][]byte{
+		{},
+		{0},
+		{0, 0, 1},
+		{0xff},
+		[]byte("hello world"),
+		append([]byte{0, 0}, bytes.Repeat([]byte{0xab}, 32)...),
+	}
+	for _, input := range inputs {
+		encoded := base58EncodeBytes(input)
+		assert.Equal(t, base58.Encode(input), string(encoded))
+
+		decoded, err := base58DecodeBytes(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(input), hex.EncodeToString(decoded))
+	}
+
+	_, err := base58DecodeBytes([]byte("0OIl"))
+	require.ErrorIs(t, err, ErrInvalidBase58)
+}
@@ -0,0 +1,311 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+)
+
+var (
+	// ErrExtendedKeyIsPrivate is returned when a watch-only wallet is given a private extended key
+	ErrExtendedKeyIsPrivate = errors.New("extended key must be a public key (xPub)")
+
+	// ErrInvalidChain is returned when a chain is not the external or internal chain
+	ErrInvalidChain = errors.New("chain must be the external (0) or internal (1) chain")
+
+	// ErrIndexNotWatched is returned when an index is past the gap limit of watched addresses
+	ErrIndexNotWatched = errors.New("index is past the gap limit of watched addresses")
+
+	// ErrInvalidGapLimit is returned when a gap limit is above MaxGapLimit
+	ErrInvalidGapLimit = errors.New("gap limit is above the maximum")
+
+	// ErrWalletIndexTooHigh is returned when a chain index is above MaxWalletIndex
+	ErrWalletIndexTooHigh = errors.New("wallet index is above the maximum")
+)
+
+// DefaultGapLimit is the number of unused addresses watched past the next
+// index on each chain
+// Reference: https://github.com/bitcoin/bips/blob/master/bip-0044.mediawiki#address-gap-limit
+const DefaultGapLimit = 20
+
+// MaxGapLimit is the largest gap limit of an XPubWallet
+const MaxGapLimit = 1000
+
+// MaxWalletIndex is the largest next index of an XPubWallet chain (every
+// address below it is derived and cached)
+const MaxWalletIndex = 100_000
+
+// WalletAddress is an address derived by an XPubWallet
+type WalletAddress struct {
+	Address string `json:"address"`
+	Script  string `json:"script"`
+	Chain   uint32 `json:"chain"`
+	Num     uint32 `json:"num"`
+}
+
+// XPubWallet is a watch-only wallet for an extended public key (xPub)
+//
+// It hands out receive (external chain) and change (internal chain) addresses
+// in order, caches every derived address and script, and recognizes any
+// address or script up to the gap limit of addresses past the next index of each
+// chain. It is safe for concurrent use; returned addresses are copies.
+type XPubWallet struct {
+	mu        sync.RWMutex
+	xPub      string
+	mainnet   bool
+	gapLimit  uint32
+	nextNum   [2]uint32
+	chainKeys [2]*bip32.ExtendedKey
+	derived   [2][]*WalletAddress
+	addresses map[string]*WalletAddress
+	scripts   map[string]*WalletAddress
+}
+
+// xPubWalletState is the JSON representation of an XPubWallet
+type xPubWalletState struct {
+	XPub        string `json:"xpub"`
+	Mainnet     bool   `json:"mainnet"`
+	GapLimit    uint32 `json:"gap_limit"`
+	NextReceive uint32 `json:"next_receive"`
+	NextChange  uint32 `json:"next_change"`
+}
+
+// NewXPubWallet will create a watch-only wallet from an extended public key
+// (see GetHDKeyFromExtendedPublicKey) using the DefaultGapLimit
+func NewXPubWallet(xPub string, mainnet bool) (*XPubWallet, error) {
+	return NewXPubWalletWithGapLimit(xPub, mainnet, DefaultGapLimit)
+}
+
+// NewXPubWalletWithGapLimit will create a watch-only wallet from an extended
+// public key, watching gapLimit unused addresses past the next index of each chain
+//
+// The gap limit must be at most MaxGapLimit.
+func NewXPubWalletWithGapLimit(xPub string, mainnet bool, gapLimit uint32) (*XPubWallet, error) {
+	return newXPubWallet(xPubWalletState{XPub: xPub, Mainnet: mainnet, GapLimit: gapLimit})
+}
+
+// newXPubWallet creates a wallet from its state and derives the watched addresses.
+func newXPubWallet(state xPubWalletState) (*XPubWallet, error) {
+	if state.GapLimit > MaxGapLimit {
+		return nil, fmt.Errorf("%w: %d, max is %d", ErrInvalidGapLimit, state.GapLimit, MaxGapLimit)
+	} else if max(state.NextReceive, state.NextChange) > MaxWalletIndex {
+		return nil, fmt.Errorf("%w: %d, max is %d", ErrWalletIndexTooHigh, max(state.NextReceive, state.NextChange), MaxWalletIndex)
+	}
+
+	hdKey, err := GetHDKeyFromExtendedPublicKey(state.XPub)
+	if err != nil {
+		return nil, err
+	} else if hdKey.IsPrivate() {
+		return nil, ErrExtendedKeyIsPrivate
+	} else if err = checkHDKeyNetwork(hdKey, state.Mainnet); err != nil {
+		return nil, err
+	}
+
+	w := &XPubWallet{
+		xPub:      state.XPub,
+		mainnet:   state.Mainnet,
+		gapLimit:  state.GapLimit,
+		nextNum:   [2]uint32{state.NextReceive, state.NextChange},
+		addresses: make(map[string]*WalletAddress),
+		scripts:   make(map[string]*WalletAddress),
+	}
+	for _, chain := range []uint32{DefaultExternalChain, DefaultInternalChain} {
+		if w.chainKeys[chain], err = GetHDKeyChild(hdKey, chain); err != nil {
+			return nil, err
+		}
+		if err = w.watch(chain); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// XPub returns the extended public key of the wallet
+func (w *XPubWallet) XPub() string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.xPub
+}
+
+// NextReceiveAddress will return the next unused receive address and advance
+// the receive index (ErrWalletIndexTooHigh once it reaches MaxWalletIndex)
+func (w *XPubWallet) NextReceiveAddress() (*WalletAddress, error) {
+	return w.next(DefaultExternalChain)
+}
+
+// NextChangeAddress will return the next unused change address and advance the
+// change index (ErrWalletIndexTooHigh once it reaches MaxWalletIndex)
+func (w *XPubWallet) NextChangeAddress() (*WalletAddress, error) {
+	return w.next(DefaultInternalChain)
+}
+
+// Indexes returns the next receive and change indexes
+func (w *XPubWallet) Indexes() (receive, change uint32) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.nextNum[DefaultExternalChain], w.nextNum[DefaultInternalChain]
+}
+
+// GetAddress will return the address at the chain/num path without changing
+// the indexes (addresses past the watched gap are derived but not cached)
+func (w *XPubWallet) GetAddress(chain, num uint32) (*WalletAddress, error) {
+	if chain > DefaultInternalChain {
+		return nil, ErrInvalidChain
+	} else if num >= bip32.HardenedKeyStart {
+		return nil, bip32.ErrDeriveHardFromPublic
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if num < uint32(len(w.derived[chain])) { // #nosec G115 -- bounded by bip32.HardenedKeyStart
+		return copyWalletAddress(w.derived[chain][num]), nil
+	}
+	key, err := deriveChildKey(w.chainKeys[chain], chain, num, w.mainnet)
+	if err != nil {
+		return nil, err
+	}
+	return &WalletAddress{Address: key.Address, Script: key.Script, Chain: chain, Num: num}, nil
+}
+
+// MarkUsed will mark the watched address at the chain/num path as used (seen
+// on chain), moving the chain index past it and watching the gap limit of
+// addresses beyond
+//
+// Indexes past the watched gap return ErrIndexNotWatched, and indexes of
+// MaxWalletIndex or more return ErrWalletIndexTooHigh
+func (w *XPubWallet) MarkUsed(chain, num uint32) error {
+	if chain > DefaultInternalChain {
+		return ErrInvalidChain
+	} else if num >= bip32.HardenedKeyStart {
+		return bip32.ErrDeriveHardFromPublic
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if uint64(num) >= uint64(w.nextNum[chain])+uint64(w.gapLimit) {
+		return ErrIndexNotWatched
+	} else if num >= MaxWalletIndex {
+		return ErrWalletIndexTooHigh
+	}
+	if num >= w.nextNum[chain] {
+		w.nextNum[chain] = num + 1
+	}
+	return w.watch(chain)
+}
+
+// IsMine returns the watched wallet address for the address string, if any
+func (w *XPubWallet) IsMine(address string) (*WalletAddress, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	walletAddress, ok := w.addresses[address]
+	return copyWalletAddress(walletAddress), ok
+}
+
+// IsMineScript returns the watched wallet address for the output script (hex
+// encoded), if any
+func (w *XPubWallet) IsMineScript(script string) (*WalletAddress, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	walletAddress, ok := w.scripts[script]
+	return copyWalletAddress(walletAddress), ok
+}
+
+// MarshalJSON will serialize the wallet state (xPub, network, gap limit and
+// indexes); the address cache is rebuilt when the state is loaded
+func (w *XPubWallet) MarshalJSON() ([]byte, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return json.Marshal(xPubWalletState{
+		XPub:        w.xPub,
+		Mainnet:     w.mainnet,
+		GapLimit:    w.gapLimit,
+		NextReceive: w.nextNum[DefaultExternalChain],
+		NextChange:  w.nextNum[DefaultInternalChain],
+	})
+}
+
+// UnmarshalJSON will load the wallet state produced by MarshalJSON
+//
+// States with a gap limit above MaxGapLimit or an index above MaxWalletIndex
+// are rejected, rather than deriving their addresses.
+func (w *XPubWallet) UnmarshalJSON(data []byte) error {
+	var state xPubWalletState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	loaded, err := newXPubWallet(state)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.xPub, w.mainnet, w.gapLimit = loaded.xPub, loaded.mainnet, loaded.gapLimit
+	w.nextNum, w.chainKeys, w.derived = loaded.nextNum, loaded.chainKeys, loaded.derived
+	w.addresses, w.scripts = loaded.addresses, loaded.scripts
+	return nil
+}
+
+// next returns the address at the next index of the chain and advances it.
+func (w *XPubWallet) next(chain uint32) (*WalletAddress, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	num := w.nextNum[chain]
+	if num >= MaxWalletIndex {
+		return nil, ErrWalletIndexTooHigh
+	}
+	if err := w.derive(chain, num); err != nil {
+		return nil, err
+	}
+	w.nextNum[chain]++
+	if err := w.watch(chain); err != nil {
+		return nil, err
+	}
+	return copyWalletAddress(w.derived[chain][num]), nil
+}
+
+// watch derives the chain up to gapLimit addresses past its next index.
+func (w *XPubWallet) watch(chain uint32) error {
+	end := uint64(w.nextNum[chain]) + uint64(w.gapLimit)
+	if end == 0 {
+		return nil
+	}
+	return w.derive(chain, uint32(min(end-1, uint64(bip32.HardenedKeyStart-1)))) // #nosec G115 -- bounded above
+}
+
+// derive caches every address of the chain up to and including num.
+func (w *XPubWallet) derive(chain, num uint32) error {
+	from := uint32(len(w.derived[chain])) // #nosec G115 -- bounded by bip32.HardenedKeyStart
+	if from > num {
+		return nil
+	}
+	keys, err := deriveChainRange(w.chainKeys[chain], chain, from, num+1, w.mainnet, 0)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		walletAddress := &WalletAddress{
+			Address: key.Address,
+			Script:  key.Script,
+			Chain:   chain,
+			Num:     key.Num,
+		}
+		w.derived[chain] = append(w.derived[chain], walletAddress)
+		w.addresses[walletAddress.Address] = walletAddress
+		w.scripts[walletAddress.Script] = walletAddress
+	}
+	return nil
+}
+
+// copyWalletAddress returns a copy of a cached address (or nil).
+func copyWalletAddress(walletAddress *WalletAddress) *WalletAddress {
+	if walletAddress == nil {
+		return nil
+	}
+	copied := *walletAddress
+	return &copied
+}
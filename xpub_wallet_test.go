@@ -0,0 +1,330 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	bip32 "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mustXPubWallet creates a watch-only wallet for a new HD key, failing the test
+// on error
+func mustXPubWallet(t *testing.T, gapLimit uint32) (*XPubWallet, *bip32.ExtendedKey) {
+	t.Helper()
+	hdKey := mustHDKey(t)
+	xPub, err := GetExtendedPublicKey(hdKey)
+	require.NoError(t, err)
+	wallet, err := NewXPubWalletWithGapLimit(xPub, true, gapLimit)
+	require.NoError(t, err)
+	return wallet, hdKey
+}
+
+// TestNewXPubWallet will test the method NewXPubWallet()
+func TestNewXPubWallet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid xpub", func(t *testing.T) {
+		t.Parallel()
+		hdKey := mustHDKey(t)
+		xPub, err := GetExtendedPublicKey(hdKey)
+		require.NoError(t, err)
+
+		wallet, err := NewXPubWallet(xPub, true)
+		require.NoError(t, err)
+		assert.Equal(t, xPub, wallet.XPub())
+		receive, change := wallet.Indexes()
+		assert.Equal(t, uint32(0), receive)
+		assert.Equal(t, uint32(0), change)
+	})
+
+	t.Run("private key", func(t *testing.T) {
+		t.Parallel()
+		wallet, err := NewXPubWallet(mustHDKey(t).String(), true)
+		require.ErrorIs(t, err, ErrExtendedKeyIsPrivate)
+		assert.Nil(t, wallet)
+	})
+
+	t.Run("invalid xpub", func(t *testing.T) {
+		t.Parallel()
+		wallet, err := NewXPubWallet("xpub-invalid", true)
+		require.Error(t, err)
+		assert.Nil(t, wallet)
+	})
+
+	t.Run("gap limit too high", func(t *testing.T) {
+		t.Parallel()
+		xPub, err := GetExtendedPublicKey(mustHDKey(t))
+		require.NoError(t, err)
+		wallet, err := NewXPubWalletWithGapLimit(xPub, true, MaxGapLimit+1)
+		require.ErrorIs(t, err, ErrInvalidGapLimit)
+		assert.Nil(t, wallet)
+	})
+
+	t.Run("tpub", func(t *testing.T) {
+		t.Parallel()
+		tPub, err := GetExtendedPublicKey(mustTestnetHDKey(t, mustHDKey(t)))
+		require.NoError(t, err)
+
+		wallet, err := NewXPubWallet(tPub, true)
+		require.ErrorIs(t, err, ErrNetworkMismatch)
+		assert.Nil(t, wallet)
+
+		wallet, err = NewXPubWallet(tPub, false)
+		require.NoError(t, err)
+		address, err := wallet.NextReceiveAddress()
+		require.NoError(t, err)
+		assert.Contains(t, "mn", address.Address[:1])
+	})
+}
+
+// TestXPubWalletAddresses will test the receive and change addresses of XPubWallet
+func TestXPubWalletAddresses(t *testing.T) {
+	t.Parallel()
+
+	wallet, hdKey := mustXPubWallet(t, DefaultGapLimit)
+
+	for num := range uint32(3) {
+		receive, err := wallet.NextReceiveAddress()
+		require.NoError(t, err)
+		change, err := wallet.NextChangeAddress()
+		require.NoError(t, err)
+
+		// Matches the stateless helpers
+		expected, err := GetAddressesForPath(hdKey, num, true)
+		require.NoError(t, err)
+		assert.Equal(t, expected[0], receive.Address)
+		assert.Equal(t, expected[1], change.Address)
+
+		script, err := ScriptFromAddress(receive.Address)
+		require.NoError(t, err)
+		assert.Equal(t, script, receive.Script)
+		assert.Equal(t, WalletAddress{receive.Address, receive.Script, DefaultExternalChain, num}, *receive)
+		assert.Equal(t, uint32(DefaultInternalChain), change.Chain)
+		assert.Equal(t, num, change.Num)
+	}
+
+	receive, change := wallet.Indexes()
+	assert.Equal(t, uint32(3), receive)
+	assert.Equal(t, uint32(3), change)
+
+	// GetAddress does not move the indexes and returns a copy of the cached address
+	first, err := wallet.GetAddress(DefaultExternalChain, 0)
+	require.NoError(t, err)
+	again, err := wallet.GetAddress(DefaultExternalChain, 0)
+	require.NoError(t, err)
+	assert.Equal(t, first, again)
+	assert.NotSame(t, first, again)
+	first.Address = testAddress
+	_, ok := wallet.IsMine(testAddress)
+	assert.False(t, ok)
+	receive, _ = wallet.Indexes()
+	assert.Equal(t, uint32(3), receive)
+
+	// Addresses past the watched gap are derived but not cached
+	far, err := wallet.GetAddress(DefaultExternalChain, bip32.HardenedKeyStart-1)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(bip32.HardenedKeyStart-1), far.Num)
+	_, ok = wallet.IsMine(far.Address)
+	assert.False(t, ok)
+
+	_, err = wallet.GetAddress(2, 0)
+	require.ErrorIs(t, err, ErrInvalidChain)
+	_, err = wallet.GetAddress(DefaultExternalChain, bip32.HardenedKeyStart)
+	require.ErrorIs(t, err, bip32.ErrDeriveHardFromPublic)
+}
+
+// TestXPubWalletIsMine will test the methods IsMine() and IsMineScript()
+func TestXPubWalletIsMine(t *testing.T) {
+	t.Parallel()
+
+	const gapLimit = 5
+	wallet, hdKey := mustXPubWallet(t, gapLimit)
+
+	// Addresses within the gap limit are watched before they are handed out
+	addresses, err := GetAddressesForPath(hdKey, gapLimit-1, true)
+	require.NoError(t, err)
+	walletAddress, ok := wallet.IsMine(addresses[1])
+	require.True(t, ok)
+	assert.Equal(t, uint32(DefaultInternalChain), walletAddress.Chain)
+	assert.Equal(t, uint32(gapLimit-1), walletAddress.Num)
+
+	script, err := ScriptFromAddress(addresses[0])
+	require.NoError(t, err)
+	walletAddress, ok = wallet.IsMineScript(script)
+	require.True(t, ok)
+	assert.Equal(t, uint32(DefaultExternalChain), walletAddress.Chain)
+	assert.Equal(t, uint32(gapLimit-1), walletAddress.Num)
+
+	// Addresses past the gap limit are not
+	addresses, err = GetAddressesForPath(hdKey, gapLimit, true)
+	require.NoError(t, err)
+	_, ok = wallet.IsMine(addresses[0])
+	assert.False(t, ok)
+
+	// Until an address is handed out or seen on chain
+	_, err = wallet.NextReceiveAddress()
+	require.NoError(t, err)
+	_, ok = wallet.IsMine(addresses[0])
+	assert.True(t, ok)
+	_, ok = wallet.IsMine(addresses[1])
+	assert.False(t, ok)
+
+	require.NoError(t, wallet.MarkUsed(DefaultInternalChain, 2))
+	_, change := wallet.Indexes()
+	assert.Equal(t, uint32(3), change)
+	_, ok = wallet.IsMine(addresses[1])
+	assert.True(t, ok)
+
+	// Marking an older address does not move the index back
+	require.NoError(t, wallet.MarkUsed(DefaultInternalChain, 0))
+	_, change = wallet.Indexes()
+	assert.Equal(t, uint32(3), change)
+
+	// Only watched addresses can be marked
+	require.ErrorIs(t, wallet.MarkUsed(DefaultInternalChain, 3+gapLimit), ErrIndexNotWatched)
+	require.ErrorIs(t, wallet.MarkUsed(DefaultInternalChain, bip32.HardenedKeyStart-1), ErrIndexNotWatched)
+	_, change = wallet.Indexes()
+	assert.Equal(t, uint32(3), change)
+
+	require.ErrorIs(t, wallet.MarkUsed(5, 0), ErrInvalidChain)
+	require.ErrorIs(t, wallet.MarkUsed(DefaultInternalChain, bip32.HardenedKeyStart), bip32.ErrDeriveHardFromPublic)
+
+	_, ok = wallet.IsMine(testAddress)
+	assert.False(t, ok)
+	_, ok = wallet.IsMineScript(testScriptPubKey)
+	assert.False(t, ok)
+}
+
+// TestXPubWalletJSON will test the methods MarshalJSON() and UnmarshalJSON()
+func TestXPubWalletJSON(t *testing.T) {
+	t.Parallel()
+
+	wallet, _ := mustXPubWallet(t, 3)
+	for range 4 {
+		_, err := wallet.NextReceiveAddress()
+		require.NoError(t, err)
+	}
+	_, err := wallet.NextChangeAddress()
+	require.NoError(t, err)
+
+	data, err := json.Marshal(wallet)
+	require.NoError(t, err)
+	assert.JSONEq(t, fmt.Sprintf(
+		`{"xpub":%q,"mainnet":true,"gap_limit":3,"next_receive":4,"next_change":1}`, wallet.XPub(),
+	), string(data))
+
+	// The loaded wallet continues where the original left off
+	loaded := new(XPubWallet)
+	require.NoError(t, json.Unmarshal(data, loaded))
+	receive, change := loaded.Indexes()
+	assert.Equal(t, uint32(4), receive)
+	assert.Equal(t, uint32(1), change)
+
+	expected, err := wallet.NextReceiveAddress()
+	require.NoError(t, err)
+	next, err := loaded.NextReceiveAddress()
+	require.NoError(t, err)
+	assert.Equal(t, *expected, *next)
+
+	first, err := wallet.GetAddress(DefaultExternalChain, 0)
+	require.NoError(t, err)
+	_, ok := loaded.IsMine(first.Address)
+	assert.True(t, ok)
+
+	t.Run("invalid state", func(t *testing.T) {
+		t.Parallel()
+		require.Error(t, json.Unmarshal([]byte(`{"xpub":1}`), new(XPubWallet)))
+		require.Error(t, json.Unmarshal([]byte(`{"xpub":"invalid"}`), new(XPubWallet)))
+
+		// Hostile states are rejected before deriving any addresses
+		for state, expectedErr := range map[string]error{
+			`{"xpub":%q,"mainnet":true,"gap_limit":2147483647}`:                  ErrInvalidGapLimit,
+			`{"xpub":%q,"mainnet":true,"gap_limit":3,"next_receive":2147483647}`: ErrWalletIndexTooHigh,
+			`{"xpub":%q,"mainnet":true,"gap_limit":3,"next_change":100001}`:      ErrWalletIndexTooHigh,
+		} {
+			err := json.Unmarshal(fmt.Appendf(nil, state, wallet.XPub()), new(XPubWallet))
+			require.ErrorIs(t, err, expectedErr)
+		}
+	})
+}
+
+// TestXPubWalletConcurrency will test handing out addresses from many goroutines
+func TestXPubWalletConcurrency(t *testing.T) {
+	t.Parallel()
+
+	wallet, _ := mustXPubWallet(t, DefaultGapLimit)
+
+	var wg sync.WaitGroup
+	seen := sync.Map{}
+	for range 50 {
+		wg.Go(func() {
+			address, err := wallet.NextReceiveAddress()
+			assert.NoError(t, err)
+			_, loaded := seen.LoadOrStore(address.Address, true)
+			assert.False(t, loaded, "address handed out twice")
+			_, ok := wallet.IsMine(address.Address)
+			assert.True(t, ok)
+		})
+	}
+	wg.Wait()
+
+	receive, _ := wallet.Indexes()
+	assert.Equal(t, uint32(50), receive)
+}
+
+// ExampleNewXPubWallet example using NewXPubWallet()
+func ExampleNewXPubWallet() {
+	hdKey, err := GenerateHDKeyFromString(
+		"xprv9s21ZrQH143K3PZSwbEeXEYq74EbnfMngzAiMCZcfjzyRpUvt2vQJnaHRTZjeuEmLXeN6BzYRoFsEckfobxE9XaRzeLGfQoxzPzTRyRb6oE",
+	)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	// The backend only ever sees the xPub
+	var xPub string
+	if xPub, err = GetExtendedPublicKey(hdKey); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	var wallet *XPubWallet
+	if wallet, err = NewXPubWallet(xPub, true); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	var address *WalletAddress
+	if address, err = wallet.NextReceiveAddress(); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	walletAddress, _ := wallet.IsMine(address.Address)
+	fmt.Printf("address: %s path: %d/%d", address.Address, walletAddress.Chain, walletAddress.Num)
+	// Output:address: 1AFc9feffQmxT61iEftzkaYvWTgLCyU6j path: 0/0
+}
+
+// BenchmarkXPubWalletNextReceiveAddress benchmarks the method NextReceiveAddress()
+func BenchmarkXPubWalletNextReceiveAddress(b *testing.B) {
+	hdKey, _ := GenerateHDKey(RecommendedSeedLength)
+	xPub, _ := GetExtendedPublicKey(hdKey)
+	wallet, _ := NewXPubWallet(xPub, true)
+	for b.Loop() {
+		_, _ = wallet.NextReceiveAddress()
+	}
+}
+
+// BenchmarkXPubWalletIsMine benchmarks the method IsMine()
+func BenchmarkXPubWalletIsMine(b *testing.B) {
+	hdKey, _ := GenerateHDKey(RecommendedSeedLength)
+	xPub, _ := GetExtendedPublicKey(hdKey)
+	wallet, _ := NewXPubWallet(xPub, true)
+	address, _ := wallet.GetAddress(DefaultExternalChain, 10)
+	for b.Loop() {
+		_, _ = wallet.IsMine(address.Address)
+	}
+}